@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/inflop/splitty.api/internal/domain/repository"
 	"github.com/inflop/splitty.api/internal/domain/service"
 	"github.com/inflop/splitty.api/internal/infrastructure/api/handler"
 	"github.com/inflop/splitty.api/internal/infrastructure/api/router"
+	"github.com/inflop/splitty.api/internal/infrastructure/mail"
+	"github.com/inflop/splitty.api/internal/infrastructure/notification"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
 	repo "github.com/inflop/splitty.api/internal/infrastructure/repository"
+	"github.com/inflop/splitty.api/internal/infrastructure/storage"
+	"github.com/inflop/splitty.api/internal/infrastructure/webhook"
 	"github.com/rs/cors"
 )
 
@@ -20,15 +28,52 @@ func main() {
 
 	// Inicjalizacja repozytoriów
 	eventRepository := repo.NewInMemoryEventRepository()
+	groupRepository := repo.NewInMemoryGroupRepository()
+	templateRepository := repo.NewInMemoryTemplateRepository()
+	webhookRepository := repo.NewInMemoryWebhookRepository()
+
+	// Inicjalizacja magazynu załączników
+	blobStorage, err := newBlobStorage()
+	if err != nil {
+		logger.Fatalf("Failed to initialize attachment storage: %v", err)
+	}
 
 	// Inicjalizacja usług
 	expenseService := service.NewExpenseService()
+	attachmentService := service.NewAttachmentService(blobStorage, envInt64("ATTACHMENTS_MAX_SIZE", service.DefaultMaxAttachmentSize))
+
+	// Inicjalizacja brokera powiadomień o zmianach wydarzeń
+	broker := pubsub.NewInMemoryBroker()
+
+	// Uruchomienie doręczania webhooków
+	dispatcher := webhook.NewDispatcher(webhookRepository, webhook.Options{
+		MaxAttempts: int(envInt64("WEBHOOK_MAX_ATTEMPTS", webhook.DefaultMaxAttempts)),
+		BaseDelay:   envDuration("WEBHOOK_RETRY_DELAY", webhook.DefaultBaseDelay),
+		MaxDelay:    envDuration("WEBHOOK_MAX_RETRY_DELAY", webhook.DefaultMaxDelay),
+		Timeout:     envDuration("WEBHOOK_TIMEOUT", webhook.DefaultTimeout),
+	}, logger)
+	go dispatcher.Run(context.Background(), broker)
+
+	// Uruchomienie powiadomień e-mail
+	mailer, err := newMailer(logger)
+	if err != nil {
+		logger.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	notifyLocale, err := service.ParseLocale(os.Getenv("NOTIFY_LOCALE"))
+	if err != nil {
+		logger.Fatalf("Invalid NOTIFY_LOCALE: %v", err)
+	}
+	notifier := notification.NewNotifier(eventRepository, expenseService, mailer, notifyLocale, logger)
+	go notifier.Run(context.Background(), broker)
 
 	// Inicjalizacja handlerów
-	eventHandler := handler.NewEventHandler(eventRepository, expenseService)
+	eventHandler := handler.NewEventHandler(eventRepository, templateRepository, expenseService, attachmentService, broker)
+	attachmentHandler := handler.NewAttachmentHandler(eventRepository, attachmentService, expenseService, broker)
+	groupHandler := handler.NewGroupHandler(groupRepository, eventRepository, expenseService, broker)
+	webhookHandler := handler.NewWebhookHandler(webhookRepository, eventRepository, expenseService)
 
 	// Konfiguracja routera
-	r := router.SetupRoutes(eventHandler)
+	r := router.SetupRoutes(eventHandler, attachmentHandler, groupHandler, webhookHandler)
 
 	// Konfiguracja CORS
 	c := cors.New(cors.Options{
@@ -39,6 +84,37 @@ func main() {
 		MaxAge:           86400, // 24h w sekundach
 	})
 
+	// Uruchomienie schedulera wydatków cyklicznych
+	scheduler := &recurringScheduler{
+		eventRepository: eventRepository,
+		expenseService:  expenseService,
+		broker:          broker,
+		interval:        envDuration("RECURRING_INTERVAL", time.Hour),
+		logger:          logger,
+	}
+	go scheduler.Run(context.Background())
+
+	// Uruchomienie zadania czyszczenia kosza
+	purger := &trashPurger{
+		eventRepository:   eventRepository,
+		expenseService:    expenseService,
+		attachmentService: attachmentService,
+		retention:         envDuration("TRASH_RETENTION", service.DefaultTrashRetention),
+		interval:          envDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		logger:            logger,
+	}
+	go purger.Run(context.Background())
+
+	// Uruchomienie przypomnień o niespłaconych rozliczeniach
+	reminders := &reminderScheduler{
+		eventRepository: eventRepository,
+		expenseService:  expenseService,
+		notifier:        notifier,
+		interval:        envDuration("REMINDER_INTERVAL", time.Hour),
+		logger:          logger,
+	}
+	go reminders.Run(context.Background())
+
 	// Konfiguracja serwera
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -59,3 +135,58 @@ func main() {
 		logger.Fatalf("Server failed to start: %v", err)
 	}
 }
+
+// newBlobStorage tworzy magazyn załączników na podstawie zmiennych środowiskowych
+func newBlobStorage() (repository.BlobStorage, error) {
+	switch os.Getenv("ATTACHMENTS_STORAGE") {
+	case "s3":
+		return storage.NewS3BlobStorage(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		}, nil)
+	default:
+		dir := os.Getenv("ATTACHMENTS_DIR")
+		if dir == "" {
+			dir = "data/attachments" // Domyślny katalog
+		}
+		return storage.NewLocalBlobStorage(dir)
+	}
+}
+
+// newMailer tworzy nadawcę wiadomości e-mail na podstawie zmiennych środowiskowych.
+// Bez SMTP_HOST wiadomości trafiają wyłącznie do logu.
+func newMailer(logger *log.Logger) (repository.Mailer, error) {
+	if os.Getenv("SMTP_HOST") == "" {
+		return mail.NewLogMailer(logger), nil
+	}
+
+	return mail.NewSMTPMailer(mail.SMTPConfig{
+		Host:        os.Getenv("SMTP_HOST"),
+		Port:        int(envInt64("SMTP_PORT", 0)),
+		Username:    os.Getenv("SMTP_USERNAME"),
+		Password:    os.Getenv("SMTP_PASSWORD"),
+		From:        os.Getenv("SMTP_FROM"),
+		ImplicitTLS: os.Getenv("SMTP_TLS") == "implicit",
+	})
+}
+
+// envInt64 odczytuje liczbę ze zmiennej środowiskowej lub zwraca wartość domyślną
+func envInt64(name string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// envDuration odczytuje czas trwania (np. "30m") ze zmiennej środowiskowej lub zwraca wartość domyślną
+func envDuration(name string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
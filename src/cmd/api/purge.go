@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// trashPurger okresowo trwale usuwa wydarzenia, których czas przechowywania w koszu upłynął
+type trashPurger struct {
+	eventRepository   repository.EventRepository
+	expenseService    *service.ExpenseService
+	attachmentService *service.AttachmentService
+	retention         time.Duration
+	interval          time.Duration
+	logger            *log.Logger
+}
+
+// Run uruchamia zadanie czyszczenia kosza i blokuje do czasu anulowania kontekstu
+func (p *trashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.runOnce(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce usuwa z kosza wydarzenia przechowywane dłużej niż wynosi okres retencji
+func (p *trashPurger) runOnce(ctx context.Context, now time.Time) {
+	deleted, err := p.eventRepository.FindDeleted()
+	if err != nil {
+		p.logger.Printf("Trash purger: failed to get trash: %v", err)
+		return
+	}
+
+	for _, event := range p.expenseService.ExpiredTrash(deleted, p.retention, now) {
+		if err := p.eventRepository.Delete(event.ID); err != nil {
+			p.logger.Printf("Trash purger: failed to purge event %d: %v", event.ID, err)
+			continue
+		}
+		if err := p.attachmentService.DeleteOrphaned(ctx, event, nil); err != nil {
+			p.logger.Printf("Trash purger: failed to delete attachments of event %d: %v", event.ID, err)
+		}
+		p.logger.Printf("Trash purger: purged event %d", event.ID)
+	}
+}
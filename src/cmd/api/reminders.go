@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/notification"
+)
+
+// reminderScheduler okresowo wysyła przypomnienia o niespłaconych rozliczeniach
+// w wydarzeniach z włączonym ReminderDays
+type reminderScheduler struct {
+	eventRepository repository.EventRepository
+	expenseService  *service.ExpenseService
+	notifier        *notification.Notifier
+	interval        time.Duration
+	logger          *log.Logger
+
+	// lastSent to moment ostatniego przypomnienia dla wydarzenia (stan w pamięci procesu)
+	lastSent map[int]time.Time
+}
+
+// Run uruchamia scheduler i blokuje do czasu anulowania kontekstu
+func (s *reminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce wysyła przypomnienia dla wydarzeń, w których minął okres ReminderDays
+func (s *reminderScheduler) runOnce(ctx context.Context, now time.Time) {
+	events, err := s.eventRepository.FindAll()
+	if err != nil {
+		s.logger.Printf("Reminder scheduler: failed to get events: %v", err)
+		return
+	}
+
+	if s.lastSent == nil {
+		s.lastSent = make(map[int]time.Time)
+	}
+	for _, event := range events {
+		if !s.expenseService.ReminderDue(event, s.lastSent[event.ID], now) {
+			continue
+		}
+
+		// Okres liczymy od próby wysyłki, aby nie ponawiać przypomnień przy każdym cyklu
+		s.lastSent[event.ID] = now
+		if sent := s.notifier.Remind(ctx, event); sent > 0 {
+			s.logger.Printf("Reminder scheduler: sent %d reminders for event %d", sent, event.ID)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
+)
+
+// recurringScheduler okresowo materializuje wydatki cykliczne we wszystkich wydarzeniach
+// i publikuje utworzone wydatki subskrybentom zmian
+type recurringScheduler struct {
+	eventRepository repository.EventRepository
+	expenseService  *service.ExpenseService
+	broker          pubsub.Broker
+	interval        time.Duration
+	logger          *log.Logger
+}
+
+// Run uruchamia scheduler i blokuje do czasu anulowania kontekstu
+func (s *recurringScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce tworzy zaległe wystąpienia wydatków cyklicznych do podanego dnia włącznie
+func (s *recurringScheduler) runOnce(now time.Time) {
+	events, err := s.eventRepository.FindAll()
+	if err != nil {
+		s.logger.Printf("Recurring scheduler: failed to get events: %v", err)
+		return
+	}
+
+	today := model.NewDate(now.Year(), now.Month(), now.Day())
+	for _, event := range events {
+		// Zarchiwizowane i rozliczone wydarzenia są tylko do odczytu
+		if len(event.Recurring) == 0 || service.EnsureEditable(event) != nil {
+			continue
+		}
+
+		before := materializedState(event)
+		existing := len(event.Expenses)
+		created, err := s.expenseService.MaterializeRecurring(event, today)
+		if err != nil {
+			s.logger.Printf("Recurring scheduler: event %d: %v", event.ID, err)
+			continue
+		}
+
+		// Zapisujemy tylko gdy coś się zmieniło (nowe lub pominięte wystąpienia)
+		if created == 0 && materializedState(event) == before {
+			continue
+		}
+
+		if err := s.eventRepository.Save(event); err != nil {
+			s.logger.Printf("Recurring scheduler: failed to save event %d: %v", event.ID, err)
+			continue
+		}
+		if created > 0 {
+			s.logger.Printf("Recurring scheduler: created %d expenses in event %d", created, event.ID)
+		}
+
+		// Nowe wystąpienia dopisywane są na końcu listy wydatków
+		s.publish(model.EventChange{Type: model.ChangeEventUpdated, EventID: event.ID}, event)
+		for _, exp := range event.Expenses[existing:] {
+			s.publish(model.EventChange{Type: model.ChangeExpenseCreated, EventID: event.ID, ExpenseID: exp.ID, Expense: &exp}, event)
+		}
+	}
+}
+
+// publish uzupełnia zmianę o czas, wersję i podsumowanie wydarzenia i publikuje ją w brokerze
+func (s *recurringScheduler) publish(change model.EventChange, event *model.Event) {
+	change.At = time.Now().UTC()
+	change.Version = event.Version
+	change.Summary = s.expenseService.CalculateSummary(event)
+	s.broker.Publish(change)
+}
+
+// materializedState zwraca opis stanu materializacji szablonów wydarzenia do porównań
+func materializedState(event *model.Event) string {
+	state := ""
+	for _, rec := range event.Recurring {
+		state += rec.MaterializedUntil.String() + ";"
+	}
+	return state
+}
@@ -0,0 +1,90 @@
+// Polecenie import zamienia eksport Splitwise lub Tricount na wydarzenie Splitty.
+//
+// Użycie:
+//
+//	go run ./cmd/import [-format splitwise|tricount] [-name "Wyjazd"] [-api http://localhost:8080] plik.csv
+//
+// Bez flagi -api wydarzenie wypisywane jest jako JSON na standardowe wyjście,
+// a z flagą -api tworzone jest przez POST /api/events. Pominięte wiersze
+// i ostrzeżenia wypisywane są na standardowe wyjście błędów.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/importer"
+)
+
+func main() {
+	format := flag.String("format", "", "format pliku: splitwise lub tricount (domyślnie rozpoznawany)")
+	name := flag.String("name", "", "nazwa wydarzenia")
+	api := flag.String("api", "", "adres API, w którym zostanie utworzone wydarzenie")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: import [-format splitwise|tricount] [-name NAME] [-api URL] FILE")
+		os.Exit(2)
+	}
+
+	if err := run(*format, *name, *api, flag.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		os.Exit(1)
+	}
+}
+
+// run importuje plik i wypisuje wydarzenie lub tworzy je w API
+func run(format, name, api, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	expenseService := service.NewExpenseService()
+	result, err := importer.NewImporter(expenseService).Import(format, name, file)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range result.Skipped {
+		fmt.Fprintf(os.Stderr, "row %d skipped: %s\n", issue.Row, issue.Reason)
+	}
+	for _, issue := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "row %d warning: %s\n", issue.Row, issue.Reason)
+	}
+
+	if err := expenseService.ValidateEvent(result.Event); err != nil {
+		return fmt.Errorf("imported event is invalid: %w", err)
+	}
+
+	body, err := json.MarshalIndent(result.Event, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if api == "" {
+		_, err = os.Stdout.Write(append(body, '\n'))
+		return err
+	}
+
+	response, err := http.Post(strings.TrimSuffix(api, "/")+"/api/events", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	created, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("API returned %s: %s", response.Status, strings.TrimSpace(string(created)))
+	}
+	_, err = os.Stdout.Write(created)
+	return err
+}
@@ -0,0 +1,54 @@
+// Polecenie webhook-receiver uruchamia lokalnego odbiorcę webhooków Splitty,
+// który weryfikuje podpisy i wypisuje odebrane powiadomienia.
+//
+// Użycie:
+//
+//	go run ./cmd/webhook-receiver -secret SEKRET [-addr :9090] [-fail 503,503]
+//
+// Flaga -fail podaje kody odpowiedzi dla kolejnych powiadomień, co pozwala
+// sprawdzić ponawianie doręczeń.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/infrastructure/webhook"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "adres nasłuchiwania")
+	secret := flag.String("secret", "", "sekret subskrypcji")
+	fail := flag.String("fail", "", "kody odpowiedzi dla kolejnych powiadomień, oddzielone przecinkami")
+	flag.Parse()
+
+	if *secret == "" {
+		fmt.Fprintln(os.Stderr, "usage: webhook-receiver -secret SECRET [-addr ADDR] [-fail CODES]")
+		os.Exit(2)
+	}
+
+	logger := log.New(os.Stdout, "[WEBHOOK] ", log.LstdFlags)
+	receiver := webhook.NewReceiver(*secret)
+	receiver.Logger = logger
+
+	if *fail != "" {
+		for _, value := range strings.Split(*fail, ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || status < 100 || status > 599 {
+				fmt.Fprintf(os.Stderr, "webhook-receiver: invalid status %q\n", value)
+				os.Exit(2)
+			}
+			receiver.FailNext(status)
+		}
+	}
+
+	logger.Printf("Listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, receiver); err != nil {
+		logger.Fatalf("Receiver failed: %v", err)
+	}
+}
@@ -3,6 +3,6 @@ module github.com/inflop/splitty.api
 go 1.24.3
 
 require (
-	github.com/gorilla/mux v1.8.1 // indirect
-	github.com/rs/cors v1.11.1 // indirect
+	github.com/gorilla/mux v1.8.1
+	github.com/rs/cors v1.11.1
 )
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DateLayout to format daty kalendarzowej używany w API
+const DateLayout = "2006-01-02"
+
+// Date reprezentuje datę kalendarzową (bez godziny) serializowaną jako "2006-01-02"
+type Date struct {
+	time.Time
+}
+
+// NewDate tworzy datę kalendarzową z podanego roku, miesiąca i dnia
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parsuje datę w formacie "2006-01-02" lub RFC 3339
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return Date{}, err
+		}
+	}
+	return NewDate(t.Year(), t.Month(), t.Day()), nil
+}
+
+// String zwraca datę w formacie "2006-01-02" lub pusty tekst dla daty zerowej
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(DateLayout)
+}
+
+// Before sprawdza czy data jest wcześniejsza niż inna data
+func (d Date) Before(other Date) bool {
+	return d.Time.Before(other.Time)
+}
+
+// After sprawdza czy data jest późniejsza niż inna data
+func (d Date) After(other Date) bool {
+	return d.Time.After(other.Time)
+}
+
+// AddDays zwraca datę przesuniętą o podaną liczbę dni
+func (d Date) AddDays(days int) Date {
+	return Date{d.Time.AddDate(0, 0, days)}
+}
+
+// MarshalJSON serializuje datę w formacie "2006-01-02"
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON deserializuje datę w formacie "2006-01-02" lub RFC 3339
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == nil || *value == "" {
+		*d = Date{}
+		return nil
+	}
+
+	parsed, err := ParseDate(*value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -1,10 +1,29 @@
 package model
 
+import "time"
+
 // Participant reprezentuje uczestnika wydarzenia
 type Participant struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email,omitempty"`
+	ID       int              `json:"id"`
+	Name     string           `json:"name"`
+	Email    string           `json:"email,omitempty"`
+	UserID   string           `json:"userId,omitempty"`
+	Presence []PresencePeriod `json:"presence,omitempty"`
+	// Dane do wypłaty: rachunek bankowy, posiadacz rachunku (domyślnie Name)
+	// i opcjonalny numer telefonu dla przelewów na telefon (np. BLIK)
+	IBAN          string `json:"iban,omitempty"`
+	BIC           string `json:"bic,omitempty"`
+	AccountHolder string `json:"accountHolder,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	// Locale to język powiadomień e-mail uczestnika (pl lub en)
+	Locale string `json:"locale,omitempty"`
+}
+
+// PresencePeriod to okres obecności uczestnika na wydarzeniu (obie daty włącznie).
+// Pusta data początku lub końca oznacza okres otwarty z tej strony.
+type PresencePeriod struct {
+	From Date `json:"from,omitzero"`
+	To   Date `json:"to,omitzero"`
 }
 
 // Payment reprezentuje pojedynczą płatność w ramach wydatku
@@ -13,39 +32,304 @@ type Payment struct {
 	Amount        float64 `json:"amount"`
 }
 
+// Rodzaje wydatków
+const (
+	// ExpenseKindExpense to zwykły wydatek - płatności to wkład uczestników
+	ExpenseKindExpense = "expense"
+	// ExpenseKindRefund to zwrot lub przychód (kaucja, cashback) - płatności to kwoty
+	// otrzymane przez uczestników, które należy rozdzielić między osoby z SharedWith
+	ExpenseKindRefund = "refund"
+)
+
 // Expense reprezentuje wydatek grupowy
 type Expense struct {
-	ID          int       `json:"id"`
-	Category    string    `json:"category"`
-	TotalAmount float64   `json:"totalAmount"`
-	Payments    []Payment `json:"payments"`
-	SharedWith  []int     `json:"sharedWith"`
+	ID            int           `json:"id"`
+	Kind          string        `json:"kind,omitempty"`
+	Category      string        `json:"category"`
+	TotalAmount   float64       `json:"totalAmount"`
+	Payments      []Payment     `json:"payments"`
+	SharedWith    []int         `json:"sharedWith"`
+	Date          Date          `json:"date,omitzero"`
+	Description   string        `json:"description,omitempty"`
+	Merchant      string        `json:"merchant,omitempty"`
+	Notes         string        `json:"notes,omitempty"`
+	Items         []ExpenseItem `json:"items,omitempty"`
+	Tax           float64       `json:"tax,omitempty"`
+	ServiceCharge float64       `json:"serviceCharge,omitempty"`
+	Tip           float64       `json:"tip,omitempty"`
+	Discount      float64       `json:"discount,omitempty"`
+	Attachments   []Attachment  `json:"attachments,omitempty"`
+	RecurringID   int           `json:"recurringId,omitempty"`
+	PeriodID      int           `json:"periodId,omitempty"`
+	CreatedAt     time.Time     `json:"createdAt,omitzero"`
+	UpdatedAt     time.Time     `json:"updatedAt,omitzero"`
+}
+
+// ExpenseItem reprezentuje pozycję rachunku dzieloną między wybranych uczestników.
+// Podatek, opłata serwisowa, napiwek i rabat wydatku rozkładane są proporcjonalnie do wartości pozycji.
+type ExpenseItem struct {
+	Description  string  `json:"description"`
+	Amount       float64 `json:"amount"`
+	Quantity     float64 `json:"quantity,omitempty"`
+	Participants []int   `json:"participants,omitempty"`
+}
+
+// Attachment reprezentuje plik (np. zdjęcie paragonu) dołączony do wydatku
+type Attachment struct {
+	ID          string    `json:"id"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+}
+
+// RecurringExpense reprezentuje szablon wydatku cyklicznego (czynsz, internet, media).
+// Rule to reguła powtarzania w formacie RRULE (np. "FREQ=MONTHLY;INTERVAL=1")
+// lub skrót "daily", "weekly", "monthly", "yearly".
+type RecurringExpense struct {
+	ID                int                   `json:"id"`
+	Rule              string                `json:"rule"`
+	StartDate         Date                  `json:"startDate"`
+	EndDate           Date                  `json:"endDate,omitzero"`
+	Template          Expense               `json:"template"`
+	Exceptions        []RecurrenceException `json:"exceptions,omitempty"`
+	MaterializedUntil Date                  `json:"materializedUntil,omitzero"`
+}
+
+// RecurrenceException pozwala pominąć lub zmienić pojedyncze wystąpienie wydatku cyklicznego
+type RecurrenceException struct {
+	Date     Date     `json:"date"`
+	Skip     bool     `json:"skip,omitempty"`
+	Override *Expense `json:"override,omitempty"`
+}
+
+// OpeningBalance to bilans uczestnika przeniesiony z zamkniętego okresu rozliczeniowego
+type OpeningBalance struct {
+	ParticipantID int     `json:"participantId"`
+	Amount        float64 `json:"amount"`
+}
+
+// Period reprezentuje zamknięty okres rozliczeniowy wraz z migawką podsumowania.
+// Wydatki zamkniętego okresu nie mogą być modyfikowane.
+type Period struct {
+	ID              int              `json:"id"`
+	StartDate       Date             `json:"startDate,omitzero"`
+	EndDate         Date             `json:"endDate"`
+	ClosedAt        time.Time        `json:"closedAt"`
+	OpeningBalances []OpeningBalance `json:"openingBalances,omitempty"`
+	Summary         Summary          `json:"summary"`
+}
+
+// Household reprezentuje gospodarstwo domowe (para, rodzina), które rozlicza się jako całość.
+// Wydatki dzielone są na osoby, ale przelewy wykonuje skarbnik gospodarstwa.
+type Household struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Members     []int  `json:"members"`
+	TreasurerID int    `json:"treasurerId,omitempty"`
+}
+
+// HouseholdBalance zawiera łączny bilans członków gospodarstwa domowego
+type HouseholdBalance struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Members   []int   `json:"members"`
+	Paid      float64 `json:"paid"`
+	ShouldPay float64 `json:"shouldPay"`
+	Balance   float64 `json:"balance"`
+}
+
+// Category reprezentuje kategorię wydatków w katalogu wydarzenia
+type Category struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Icon    string   `json:"icon,omitempty"`
+	Color   string   `json:"color,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
 }
 
 // Event reprezentuje całe wydarzenie z uczestnikami i wydatkami
 type Event struct {
-	ID           int           `json:"id"`
-	Name         string        `json:"name"`
-	Participants []Participant `json:"participants"`
-	Expenses     []Expense     `json:"expenses"`
+	ID              int                `json:"id"`
+	Name            string             `json:"name"`
+	Currency        string             `json:"currency,omitempty"`
+	Participants    []Participant      `json:"participants"`
+	Expenses        []Expense          `json:"expenses"`
+	Categories      []Category         `json:"categories,omitempty"`
+	Households      []Household        `json:"households,omitempty"`
+	Recurring       []RecurringExpense `json:"recurringExpenses,omitempty"`
+	Periods         []Period           `json:"periods,omitempty"`
+	OpeningBalances []OpeningBalance   `json:"openingBalances,omitempty"`
+	Repayments      []Repayment        `json:"repayments,omitempty"`
+	StartDate       Date               `json:"startDate,omitzero"`
+	EndDate         Date               `json:"endDate,omitzero"`
+	// ShareByPresence sprawia, że datowany wydatek bez SharedWith dzielony jest
+	// między uczestników obecnych w dniu wydatku
+	ShareByPresence bool `json:"shareByPresence,omitempty"`
+	// ReminderDays to odstęp (w dniach) przypomnień e-mail o niespłaconych rozliczeniach;
+	// 0 wyłącza przypomnienia
+	ReminderDays int `json:"reminderDays,omitempty"`
+	// Version to numer wersji zwiększany przy każdym zapisie, służący do wykrywania
+	// równoczesnych zmian
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+	// SettledAt to moment spłacenia wydarzenia w ramach rozliczenia grupy
+	SettledAt time.Time `json:"settledAt,omitzero"`
+	// ArchivedAt to moment archiwizacji; zarchiwizowane wydarzenie jest tylko do odczytu
+	ArchivedAt time.Time `json:"archivedAt,omitzero"`
+	// DeletedAt to moment przeniesienia wydarzenia do kosza
+	DeletedAt time.Time `json:"deletedAt,omitzero"`
 }
 
 // ParticipantBalance zawiera informacje o bilansie uczestnika
 type ParticipantBalance struct {
 	ID        int     `json:"id"`
 	Name      string  `json:"name"`
+	Opening   float64 `json:"opening,omitempty"`
 	Paid      float64 `json:"paid"`
 	ShouldPay float64 `json:"shouldPay"`
-	Balance   float64 `json:"balance"`
+	// Repaid to suma spłat wysłanych pomniejszona o spłaty otrzymane
+	Repaid  float64 `json:"repaid,omitempty"`
+	Balance float64 `json:"balance"`
 }
 
 // Settlement reprezentuje pojedyncze rozliczenie między uczestnikami
 type Settlement struct {
-	From     int     `json:"from"`
-	FromName string  `json:"fromName"`
-	To       int     `json:"to"`
-	ToName   string  `json:"toName"`
-	Amount   float64 `json:"amount"`
+	From          int     `json:"from"`
+	FromName      string  `json:"fromName"`
+	To            int     `json:"to"`
+	ToName        string  `json:"toName"`
+	Amount        float64 `json:"amount"`
+	FromHousehold int     `json:"fromHousehold,omitempty"`
+	ToHousehold   int     `json:"toHousehold,omitempty"`
+}
+
+// Rodzaje zmian wydarzeń publikowanych do subskrybentów
+const (
+	ChangeSnapshot           = "snapshot"
+	ChangeEventCreated       = "event.created"
+	ChangeEventUpdated       = "event.updated"
+	ChangeEventDeleted       = "event.deleted"
+	ChangeEventRestored      = "event.restored"
+	ChangeEventPurged        = "event.purged"
+	ChangeEventArchived      = "event.archived"
+	ChangeEventUnarchived    = "event.unarchived"
+	ChangeEventSettled       = "event.settled"
+	ChangePeriodClosed       = "period.closed"
+	ChangeParticipantRemoved = "participant.removed"
+	ChangeParticipantsMerged = "participants.merged"
+	ChangeRepaymentsAdded    = "repayments.added"
+	ChangeExpenseCreated     = "expense.created"
+	ChangeExpenseUpdated     = "expense.updated"
+	ChangeExpenseDeleted     = "expense.deleted"
+)
+
+// EventChange to powiadomienie o zmianie wydarzenia wraz z przeliczonym podsumowaniem
+// (podsumowanie jest pomijane dla wydarzeń usuniętych)
+type EventChange struct {
+	ID      uint64    `json:"id,omitempty"`
+	Type    string    `json:"type"`
+	EventID int       `json:"eventId"`
+	At      time.Time `json:"at"`
+	Version int       `json:"version,omitempty"`
+	// Actor to nazwa osoby, która wprowadziła zmianę (dla zmian z kanału współpracy),
+	// a ActorID to ID uczestnika, jeśli osoba przedstawiła się jako uczestnik
+	Actor   string `json:"actor,omitempty"`
+	ActorID int    `json:"actorId,omitempty"`
+	// ExpenseID i Expense opisują zmieniony wydatek (Expense jest pomijany po usunięciu)
+	ExpenseID int      `json:"expenseId,omitempty"`
+	Expense   *Expense `json:"expense,omitempty"`
+	Summary   *Summary `json:"summary,omitempty"`
+}
+
+// EmailMessage to wiadomość e-mail do jednego odbiorcy
+type EmailMessage struct {
+	To      string `json:"to"`
+	ToName  string `json:"toName,omitempty"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// WebhookSubscription to subskrypcja powiadomień HTTP o zmianach wydarzeń
+type WebhookSubscription struct {
+	ID int `json:"id"`
+	// EventID ogranicza subskrypcję do jednego wydarzenia (0 oznacza wszystkie wydarzenia)
+	EventID int    `json:"eventId,omitempty"`
+	URL     string `json:"url"`
+	// Secret to klucz podpisu HMAC-SHA256 powiadomień, zwracany tylko przy tworzeniu subskrypcji
+	Secret string `json:"secret,omitempty"`
+	// Types to rodzaje zmian (np. expense.created lub expense.*); pusta lista oznacza wszystkie
+	Types     []string  `json:"types,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+}
+
+// WebhookDelivery to wpis dziennika pojedynczej próby doręczenia powiadomienia
+type WebhookDelivery struct {
+	ID             int       `json:"id"`
+	SubscriptionID int       `json:"subscriptionId"`
+	ChangeID       uint64    `json:"changeId"`
+	Type           string    `json:"type"`
+	EventID        int       `json:"eventId"`
+	Attempt        int       `json:"attempt"`
+	Success        bool      `json:"success"`
+	StatusCode     int       `json:"statusCode,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"durationMs"`
+	At             time.Time `json:"at"`
+	// NextAttemptAt to czas kolejnej próby (pusty, jeśli doręczenie zakończono)
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitzero"`
+}
+
+// BalanceChange to nowy bilans uczestnika wraz ze zmianą względem poprzedniego podsumowania
+type BalanceChange struct {
+	ID      int     `json:"id"`
+	Name    string  `json:"name"`
+	Balance float64 `json:"balance"`
+	Change  float64 `json:"change"`
+}
+
+// SummaryDelta opisuje różnicę między dwoma podsumowaniami wydarzenia: zmienione bilanse
+// uczestników oraz aktualną listę rozliczeń, jeśli uległa zmianie
+type SummaryDelta struct {
+	TotalAmount        float64         `json:"totalAmount"`
+	TotalChange        float64         `json:"totalChange"`
+	Balances           []BalanceChange `json:"balances,omitempty"`
+	SettlementsChanged bool            `json:"settlementsChanged"`
+	Settlements        []Settlement    `json:"settlements,omitempty"`
+}
+
+// PaymentRequest to dane przelewu spłacającego rozliczenie na rachunek odbiorcy
+type PaymentRequest struct {
+	Settlement  Settlement `json:"settlement"`
+	Currency    string     `json:"currency,omitempty"`
+	Beneficiary string     `json:"beneficiary"`
+	IBAN        string     `json:"iban,omitempty"`
+	BIC         string     `json:"bic,omitempty"`
+	Phone       string     `json:"phone,omitempty"`
+	Remittance  string     `json:"remittance"`
+	// EPC to treść kodu QR w formacie EPC069-12 (tylko dla przelewów w euro na IBAN)
+	EPC string `json:"epc,omitempty"`
+}
+
+// CategoryShare zawiera udział uczestnika w wydatkach danej kategorii
+type CategoryShare struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Amount     float64 `json:"amount"`
+	Percentage float64 `json:"percentage"`
+}
+
+// CategorySummary zawiera statystyki wydatków w danej kategorii
+type CategorySummary struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Icon          string          `json:"icon,omitempty"`
+	Color         string          `json:"color,omitempty"`
+	TotalAmount   float64         `json:"totalAmount"`
+	Percentage    float64         `json:"percentage"`
+	ExpenseCount  int             `json:"expenseCount"`
+	ByParticipant []CategoryShare `json:"byParticipant"`
 }
 
 // Summary reprezentuje podsumowanie wydarzenia
@@ -54,4 +338,109 @@ type Summary struct {
 	PerPersonAmount float64              `json:"perPersonAmount"`
 	PaidByPerson    []ParticipantBalance `json:"paidByPerson"`
 	Settlements     []Settlement         `json:"settlements"`
+	Categories      []CategorySummary    `json:"categories"`
+	// Bilanse i rozliczenia gospodarstw domowych (tylko gdy wydarzenie je definiuje)
+	HouseholdBalances    []HouseholdBalance `json:"householdBalances,omitempty"`
+	HouseholdSettlements []Settlement       `json:"householdSettlements,omitempty"`
+}
+
+// TimelinePoint reprezentuje skumulowane bilanse uczestników po danym dniu, wydatku lub spłacie
+type TimelinePoint struct {
+	Date        Date                 `json:"date,omitzero"`
+	ExpenseID   int                  `json:"expenseId,omitempty"`
+	TotalAmount float64              `json:"totalAmount"`
+	Balances    []ParticipantBalance `json:"balances"`
+}
+
+// PersonEventBalance zawiera bilans osoby w jednym z wydarzeń
+type PersonEventBalance struct {
+	EventID       int     `json:"eventId"`
+	EventName     string  `json:"eventName"`
+	ParticipantID int     `json:"participantId"`
+	Balance       float64 `json:"balance"`
+}
+
+// PersonSettlement reprezentuje rozliczenie między osobami po skompensowaniu długów ze wszystkich wydarzeń
+type PersonSettlement struct {
+	From     string  `json:"from"`
+	FromName string  `json:"fromName"`
+	To       string  `json:"to"`
+	ToName   string  `json:"toName"`
+	Amount   float64 `json:"amount"`
+}
+
+// PersonBalances zawiera łączny bilans osoby we wszystkich wydarzeniach, w których uczestniczy
+type PersonBalances struct {
+	Person      string               `json:"person"`
+	Name        string               `json:"name"`
+	NetBalance  float64              `json:"netBalance"`
+	Events      []PersonEventBalance `json:"events"`
+	Settlements []PersonSettlement   `json:"settlements"`
+}
+
+// GroupMember reprezentuje członka grupy identyfikowanego w wydarzeniach przez konto lub e-mail
+type GroupMember struct {
+	Name   string `json:"name"`
+	Email  string `json:"email,omitempty"`
+	UserID string `json:"userId,omitempty"`
+}
+
+// Group reprezentuje grupę znajomych, która organizuje wiele wydarzeń i rozlicza je łącznie
+type Group struct {
+	ID        int           `json:"id"`
+	Name      string        `json:"name"`
+	Members   []GroupMember `json:"members"`
+	EventIDs  []int         `json:"eventIds"`
+	CreatedAt time.Time     `json:"createdAt,omitzero"`
+	UpdatedAt time.Time     `json:"updatedAt,omitzero"`
+}
+
+// GroupMemberBalance zawiera łączny bilans osoby we wszystkich otwartych wydarzeniach grupy
+type GroupMemberBalance struct {
+	Person  string  `json:"person"`
+	Name    string  `json:"name"`
+	Balance float64 `json:"balance"`
+}
+
+// GroupSummary reprezentuje łączne podsumowanie otwartych wydarzeń grupy
+type GroupSummary struct {
+	GroupID     int                  `json:"groupId"`
+	EventIDs    []int                `json:"eventIds"`
+	Balances    []GroupMemberBalance `json:"balances"`
+	Settlements []PersonSettlement   `json:"settlements"`
+}
+
+// EventTemplate to nazwany szablon wydarzenia z uczestnikami, kategoriami i ustawieniami,
+// od którego można rozpocząć nowe wydarzenie
+type EventTemplate struct {
+	ID              int           `json:"id"`
+	Name            string        `json:"name"`
+	Participants    []Participant `json:"participants"`
+	Categories      []Category    `json:"categories,omitempty"`
+	Households      []Household   `json:"households,omitempty"`
+	ShareByPresence bool          `json:"shareByPresence,omitempty"`
+	CreatedAt       time.Time     `json:"createdAt,omitzero"`
+	UpdatedAt       time.Time     `json:"updatedAt,omitzero"`
+}
+
+// Repayment reprezentuje spłatę długu między uczestnikami (np. rozpoznaną w wyciągu bankowym)
+type Repayment struct {
+	ID     int     `json:"id"`
+	From   int     `json:"from"`
+	To     int     `json:"to"`
+	Amount float64 `json:"amount"`
+	Date   Date    `json:"date,omitzero"`
+	// Reference to identyfikator transakcji bankowej, zapobiegający podwójnemu zaksięgowaniu
+	Reference string `json:"reference,omitempty"`
+	PeriodID  int    `json:"periodId,omitempty"`
+}
+
+// BankTransaction reprezentuje transakcję z wyciągu bankowego. Kwota jest ujemna dla obciążeń.
+type BankTransaction struct {
+	Date         Date    `json:"date,omitzero"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency,omitempty"`
+	Counterparty string  `json:"counterparty,omitempty"`
+	Description  string  `json:"description,omitempty"`
+	Reference    string  `json:"reference,omitempty"`
 }
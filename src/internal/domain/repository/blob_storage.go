@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound zwracany jest gdy w magazynie nie ma pliku o podanym kluczu
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStorage definiuje interfejs magazynu plików (np. załączników do wydatków)
+type BlobStorage interface {
+	Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/inflop/splitty.api/internal/domain/model"
+
+// GroupRepository definiuje interfejs dla repozytorium grup
+type GroupRepository interface {
+	Save(group *model.Group) error
+	FindByID(id int) (*model.Group, error)
+	Delete(id int) error
+	FindAll() ([]*model.Group, error)
+}
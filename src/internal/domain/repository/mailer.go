@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Mailer definiuje interfejs wysyłki wiadomości e-mail
+type Mailer interface {
+	Send(ctx context.Context, message model.EmailMessage) error
+}
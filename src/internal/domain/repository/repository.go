@@ -1,11 +1,25 @@
 package repository
 
-import "github.com/inflop/splitty.api/internal/domain/model"
+import (
+	"errors"
+	"time"
 
-// EventRepository definiuje interfejs dla repozytorium wydarzeń
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// ErrVersionConflict zwracany jest przez Save, gdy wydarzenie zostało w międzyczasie zmienione
+var ErrVersionConflict = errors.New("event was modified concurrently")
+
+// EventRepository definiuje interfejs dla repozytorium wydarzeń.
+// FindByID i FindAll pomijają wydarzenia przeniesione do kosza, a Delete usuwa wydarzenie trwale.
+// Save zwiększa wersję wydarzenia i zwraca ErrVersionConflict, gdy zapisywana wersja
+// różni się od wersji przechowywanej.
 type EventRepository interface {
 	Save(event *model.Event) error
 	FindByID(id int) (*model.Event, error)
 	Delete(id int) error
 	FindAll() ([]*model.Event, error)
+	SoftDelete(id int, at time.Time) error
+	Restore(id int) error
+	FindDeleted() ([]*model.Event, error)
 }
@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/inflop/splitty.api/internal/domain/model"
+
+// TemplateRepository definiuje interfejs dla repozytorium szablonów wydarzeń
+type TemplateRepository interface {
+	Save(template *model.EventTemplate) error
+	FindByID(id int) (*model.EventTemplate, error)
+	Delete(id int) error
+	FindAll() ([]*model.EventTemplate, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import "github.com/inflop/splitty.api/internal/domain/model"
+
+// WebhookRepository definiuje interfejs dla repozytorium subskrypcji webhooków
+// i dziennika ich doręczeń. FindDeliveries zwraca wpisy od najnowszego.
+type WebhookRepository interface {
+	Save(subscription *model.WebhookSubscription) error
+	FindByID(id int) (*model.WebhookSubscription, error)
+	Delete(id int) error
+	FindAll() ([]*model.WebhookSubscription, error)
+	AddDelivery(delivery *model.WebhookDelivery) error
+	FindDeliveries(subscriptionID int) ([]*model.WebhookDelivery, error)
+}
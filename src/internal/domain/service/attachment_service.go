@@ -0,0 +1,225 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// DefaultMaxAttachmentSize to domyślny maksymalny rozmiar załącznika (10 MB)
+const DefaultMaxAttachmentSize = 10 << 20
+
+// Błędy zwracane przez usługę załączników
+var (
+	ErrExpenseNotFound       = errors.New("expense not found")
+	ErrAttachmentNotFound    = errors.New("attachment not found")
+	ErrAttachmentTooLarge    = errors.New("attachment is too large")
+	ErrUnsupportedAttachment = errors.New("unsupported attachment type")
+)
+
+// Typy plików akceptowane jako załączniki
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// AttachmentService obsługuje załączniki (zdjęcia i skany paragonów) do wydatków
+type AttachmentService struct {
+	storage repository.BlobStorage
+	maxSize int64
+}
+
+// NewAttachmentService tworzy nową instancję usługi załączników
+func NewAttachmentService(storage repository.BlobStorage, maxSize int64) *AttachmentService {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxAttachmentSize
+	}
+	return &AttachmentService{
+		storage: storage,
+		maxSize: maxSize,
+	}
+}
+
+// MaxSize zwraca maksymalny rozmiar pojedynczego załącznika w bajtach
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// AttachmentKey zwraca klucz pliku załącznika w magazynie
+func AttachmentKey(eventID, expenseID int, attachmentID string) string {
+	return fmt.Sprintf("events/%d/expenses/%d/%s", eventID, expenseID, attachmentID)
+}
+
+// Upload zapisuje plik w magazynie i dodaje załącznik do wydatku.
+// Typ pliku wyznaczany jest na podstawie jego zawartości, a nie nagłówków klienta.
+func (s *AttachmentService) Upload(ctx context.Context, event *model.Event, expenseID int, fileName string, data io.Reader) (*model.Attachment, error) {
+	expense := findExpense(event, expenseID)
+	if expense == nil {
+		return nil, ErrExpenseNotFound
+	}
+
+	content, err := io.ReadAll(io.LimitReader(data, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > s.maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	contentType := http.DetectContentType(content)
+	if !allowedAttachmentTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAttachment, contentType)
+	}
+
+	id, err := newAttachmentID()
+	if err != nil {
+		return nil, err
+	}
+
+	key := AttachmentKey(event.ID, expenseID, id)
+	if err := s.storage.Put(ctx, key, bytes.NewReader(content), int64(len(content)), contentType); err != nil {
+		return nil, err
+	}
+
+	attachment := model.Attachment{
+		ID:          id,
+		FileName:    filepath.Base(fileName),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		UploadedAt:  time.Now().UTC(),
+	}
+	expense.Attachments = append(expense.Attachments, attachment)
+
+	return &attachment, nil
+}
+
+// DiscardUpload usuwa z magazynu plik przesłany przez Upload, gdy wydarzenie z nowym
+// załącznikiem nie zostało zapisane
+func (s *AttachmentService) DiscardUpload(ctx context.Context, eventID, expenseID int, attachmentID string) error {
+	return s.storage.Delete(ctx, AttachmentKey(eventID, expenseID, attachmentID))
+}
+
+// Open otwiera plik załącznika wydatku
+func (s *AttachmentService) Open(ctx context.Context, event *model.Event, expenseID int, attachmentID string) (*model.Attachment, io.ReadCloser, error) {
+	attachment, err := findAttachment(event, expenseID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := s.storage.Get(ctx, AttachmentKey(event.ID, expenseID, attachmentID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, reader, nil
+}
+
+// Remove usuwa załącznik z wydatku oraz jego plik z magazynu
+func (s *AttachmentService) Remove(ctx context.Context, event *model.Event, expenseID int, attachmentID string) error {
+	if _, err := findAttachment(event, expenseID, attachmentID); err != nil {
+		return err
+	}
+
+	expense := findExpense(event, expenseID)
+	attachments := expense.Attachments[:0]
+	for _, a := range expense.Attachments {
+		if a.ID != attachmentID {
+			attachments = append(attachments, a)
+		}
+	}
+	expense.Attachments = attachments
+
+	return s.storage.Delete(ctx, AttachmentKey(event.ID, expenseID, attachmentID))
+}
+
+// PreserveAttachments przenosi załączniki z zapisanej wersji wydarzenia do nowej.
+// Załączniki zarządzane są wyłącznie przez serwer, więc dane od klienta są ignorowane.
+func (s *AttachmentService) PreserveAttachments(previous, event *model.Event) {
+	stored := make(map[int][]model.Attachment)
+	if previous != nil {
+		for _, e := range previous.Expenses {
+			stored[e.ID] = e.Attachments
+		}
+	}
+
+	for i := range event.Expenses {
+		event.Expenses[i].Attachments = stored[event.Expenses[i].ID]
+	}
+}
+
+// DeleteOrphaned usuwa z magazynu pliki załączników, których nie ma już w nowej wersji wydarzenia.
+// Przekazanie nil jako nowej wersji usuwa wszystkie załączniki wydarzenia.
+func (s *AttachmentService) DeleteOrphaned(ctx context.Context, previous, event *model.Event) error {
+	if previous == nil {
+		return nil
+	}
+
+	kept := make(map[string]bool)
+	if event != nil {
+		for _, e := range event.Expenses {
+			for _, a := range e.Attachments {
+				kept[AttachmentKey(event.ID, e.ID, a.ID)] = true
+			}
+		}
+	}
+
+	var errs []error
+	for _, e := range previous.Expenses {
+		for _, a := range e.Attachments {
+			key := AttachmentKey(previous.ID, e.ID, a.ID)
+			if kept[key] {
+				continue
+			}
+			if err := s.storage.Delete(ctx, key); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// findExpense zwraca wskaźnik na wydatek o podanym ID lub nil
+func findExpense(event *model.Event, expenseID int) *model.Expense {
+	for i := range event.Expenses {
+		if event.Expenses[i].ID == expenseID {
+			return &event.Expenses[i]
+		}
+	}
+	return nil
+}
+
+// findAttachment zwraca załącznik wydatku o podanym ID
+func findAttachment(event *model.Event, expenseID int, attachmentID string) (*model.Attachment, error) {
+	expense := findExpense(event, expenseID)
+	if expense == nil {
+		return nil, ErrExpenseNotFound
+	}
+	for i := range expense.Attachments {
+		if expense.Attachments[i].ID == attachmentID {
+			return &expense.Attachments[i], nil
+		}
+	}
+	return nil, ErrAttachmentNotFound
+}
+
+// newAttachmentID generuje losowy identyfikator załącznika
+func newAttachmentID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
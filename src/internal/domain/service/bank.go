@@ -0,0 +1,148 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// SettlementMatch łączy przelew przychodzący z rozliczeniem, które spłaca
+type SettlementMatch struct {
+	Transaction model.BankTransaction `json:"transaction"`
+	Settlement  model.Settlement      `json:"settlement"`
+}
+
+// StatementSuggestions to propozycje wynikające z wyciągu bankowego uczestnika
+type StatementSuggestions struct {
+	// Expenses to szkice wydatków z obciążeń rachunku, opłaconych przez uczestnika
+	Expenses []model.Expense `json:"expenses"`
+	// Matches to przelewy przychodzące rozpoznane jako spłaty rozliczeń
+	Matches []SettlementMatch `json:"matches"`
+	// Unmatched to przelewy przychodzące bez pasującego rozliczenia
+	Unmatched []model.BankTransaction `json:"unmatched"`
+}
+
+// SuggestFromStatement analizuje transakcje z wyciągu uczestnika: obciążenia zamienia na szkice
+// wydatków, a przelewy przychodzące dopasowuje do rozliczeń, w których uczestnik jest odbiorcą.
+// Rozliczenie pasuje, gdy kwota jest równa, a nadawca przelewu nazywa się jak dłużnik
+// (lub jest to jedyne rozliczenie na tę kwotę). Transakcje już zaksięgowane jako spłaty są pomijane.
+func (s *ExpenseService) SuggestFromStatement(event *model.Event, participantID int, transactions []model.BankTransaction) (*StatementSuggestions, error) {
+	if findParticipant(event, participantID) == nil {
+		return nil, ErrParticipantNotFound
+	}
+
+	imported := make(map[string]bool, len(event.Repayments))
+	for _, r := range event.Repayments {
+		if r.Reference != "" {
+			imported[r.Reference] = true
+		}
+	}
+
+	var outstanding []model.Settlement
+	for _, settlement := range s.CalculateSummary(event).Settlements {
+		if settlement.To == participantID {
+			outstanding = append(outstanding, settlement)
+		}
+	}
+
+	result := &StatementSuggestions{
+		Expenses:  []model.Expense{},
+		Matches:   []SettlementMatch{},
+		Unmatched: []model.BankTransaction{},
+	}
+	for _, tx := range transactions {
+		if tx.Reference != "" && imported[tx.Reference] {
+			continue
+		}
+		if event.Currency != "" && tx.Currency != "" && !strings.EqualFold(event.Currency, tx.Currency) {
+			if tx.Amount > 0 {
+				result.Unmatched = append(result.Unmatched, tx)
+			}
+			continue
+		}
+
+		if tx.Amount < 0 {
+			result.Expenses = append(result.Expenses, s.expenseDraft(event, participantID, tx))
+			continue
+		}
+
+		index := s.matchSettlement(event, outstanding, tx)
+		if index < 0 {
+			result.Unmatched = append(result.Unmatched, tx)
+			continue
+		}
+		result.Matches = append(result.Matches, SettlementMatch{Transaction: tx, Settlement: outstanding[index]})
+		outstanding = append(outstanding[:index], outstanding[index+1:]...)
+	}
+
+	return result, nil
+}
+
+// ApplyRepayments księguje dopasowane przelewy jako spłaty rozliczeń i zwraca dodane spłaty
+func (s *ExpenseService) ApplyRepayments(event *model.Event, matches []SettlementMatch) []model.Repayment {
+	nextID := 1
+	for _, r := range event.Repayments {
+		nextID = max(nextID, r.ID+1)
+	}
+
+	added := make([]model.Repayment, 0, len(matches))
+	for _, match := range matches {
+		repayment := model.Repayment{
+			ID:        nextID,
+			From:      match.Settlement.From,
+			To:        match.Settlement.To,
+			Amount:    match.Settlement.Amount,
+			Date:      match.Transaction.Date,
+			Reference: match.Transaction.Reference,
+		}
+		nextID++
+		event.Repayments = append(event.Repayments, repayment)
+		added = append(added, repayment)
+	}
+	return added
+}
+
+// expenseDraft buduje szkic wydatku z obciążenia rachunku uczestnika, dzielony między wszystkich
+// (lub obecnych, gdy wydarzenie dzieli wydatki według obecności)
+func (s *ExpenseService) expenseDraft(event *model.Event, participantID int, tx model.BankTransaction) model.Expense {
+	amount := s.RoundToTwo(-tx.Amount)
+	draft := model.Expense{
+		TotalAmount: amount,
+		Payments:    []model.Payment{{ParticipantID: participantID, Amount: amount}},
+		Date:        tx.Date,
+		Description: tx.Description,
+		Merchant:    tx.Counterparty,
+	}
+	if draft.Description == "" {
+		draft.Description = tx.Counterparty
+	}
+	if !event.ShareByPresence || tx.Date.IsZero() {
+		for _, p := range event.Participants {
+			draft.SharedWith = append(draft.SharedWith, p.ID)
+		}
+	}
+	return draft
+}
+
+// matchSettlement zwraca indeks rozliczenia spłacanego przez przelew lub -1
+func (s *ExpenseService) matchSettlement(event *model.Event, outstanding []model.Settlement, tx model.BankTransaction) int {
+	sender := strings.ToLower(tx.Counterparty + " " + tx.Description)
+
+	candidate, candidates := -1, 0
+	for i, settlement := range outstanding {
+		if s.RoundToTwo(settlement.Amount-tx.Amount) != 0 {
+			continue
+		}
+		if debtor := findParticipant(event, settlement.From); debtor != nil && debtor.Name != "" &&
+			strings.Contains(sender, strings.ToLower(debtor.Name)) {
+			return i
+		}
+		candidate = i
+		candidates++
+	}
+
+	if candidates == 1 {
+		return candidate
+	}
+	return -1
+}
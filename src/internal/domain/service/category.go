@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// OtherCategoryID to identyfikator kategorii dla wydatków bez kategorii
+const OtherCategoryID = "other"
+
+// DefaultCategories zwraca domyślny katalog kategorii wspólny dla wszystkich wydarzeń
+func DefaultCategories() []model.Category {
+	return []model.Category{
+		{ID: "food", Name: "Jedzenie", Icon: "🍽️", Color: "#E67E22", Aliases: []string{"Food", "Restauracja", "Restaurant", "Zakupy spożywcze", "Groceries"}},
+		{ID: "accommodation", Name: "Nocleg", Icon: "🏠", Color: "#8E44AD", Aliases: []string{"Accommodation", "Hotel", "Zakwaterowanie"}},
+		{ID: "transport", Name: "Transport", Icon: "🚗", Color: "#2980B9", Aliases: []string{"Benzyna", "Paliwo", "Fuel", "Taxi", "Bilety"}},
+		{ID: "entertainment", Name: "Rozrywka", Icon: "🎉", Color: "#27AE60", Aliases: []string{"Entertainment", "Atrakcje"}},
+		{ID: "utilities", Name: "Media", Icon: "💡", Color: "#F1C40F", Aliases: []string{"Utilities", "Rachunki", "Bills"}},
+		{ID: OtherCategoryID, Name: "Inne", Icon: "📦", Color: "#95A5A6", Aliases: []string{"Other", "Różne"}},
+	}
+}
+
+// CategoryCatalogue zwraca katalog kategorii wydarzenia uzupełniony o kategorie domyślne.
+// Kategorie zdefiniowane w wydarzeniu nadpisują domyślne o tym samym ID.
+func (s *ExpenseService) CategoryCatalogue(event *model.Event) []model.Category {
+	catalogue := make([]model.Category, 0, len(event.Categories)+len(DefaultCategories()))
+	defined := make(map[string]bool, len(event.Categories))
+
+	for _, c := range event.Categories {
+		catalogue = append(catalogue, c)
+		defined[strings.ToLower(c.ID)] = true
+	}
+
+	for _, c := range DefaultCategories() {
+		if !defined[c.ID] {
+			catalogue = append(catalogue, c)
+		}
+	}
+
+	return catalogue
+}
+
+// NormalizeCategory dopasowuje dowolną nazwę kategorii do pozycji z katalogu wydarzenia.
+// Nazwy spoza katalogu zwracane są jako nowa kategoria o ID wyznaczonym z nazwy.
+func (s *ExpenseService) NormalizeCategory(event *model.Event, name string) model.Category {
+	name = strings.TrimSpace(name)
+	catalogue := s.CategoryCatalogue(event)
+
+	if name == "" {
+		name = OtherCategoryID
+	}
+
+	for _, c := range catalogue {
+		if strings.EqualFold(c.ID, name) || strings.EqualFold(c.Name, name) {
+			return c
+		}
+		for _, alias := range c.Aliases {
+			if strings.EqualFold(alias, name) {
+				return c
+			}
+		}
+	}
+
+	return model.Category{
+		ID:   categorySlug(name),
+		Name: name,
+	}
+}
+
+// CalculateCategoryBreakdown oblicza sumy wydatków w podziale na kategorie i uczestników
+func (s *ExpenseService) CalculateCategoryBreakdown(event *model.Event) []model.CategorySummary {
+	type categoryWork struct {
+		summary model.CategorySummary
+		shares  map[int]float64
+	}
+
+	var order []string
+	byID := make(map[string]*categoryWork)
+	totalAmount := 0.0
+
+	for _, exp := range event.Expenses {
+		category := s.NormalizeCategory(event, exp.Category)
+		work, exists := byID[category.ID]
+		if !exists {
+			work = &categoryWork{
+				summary: model.CategorySummary{
+					ID:    category.ID,
+					Name:  category.Name,
+					Icon:  category.Icon,
+					Color: category.Color,
+				},
+				shares: make(map[int]float64),
+			}
+			byID[category.ID] = work
+			order = append(order, category.ID)
+		}
+
+		totalExp, _ := s.ExpenseTotal(exp)
+		work.summary.TotalAmount = s.RoundToTwo(work.summary.TotalAmount + totalExp)
+		work.summary.ExpenseCount++
+		totalAmount = s.RoundToTwo(totalAmount + totalExp)
+
+		for id, share := range s.ExpenseShares(event, exp) {
+			work.shares[id] = s.RoundToTwo(work.shares[id] + share)
+		}
+	}
+
+	categories := make([]model.CategorySummary, 0, len(order))
+	for _, id := range order {
+		work := byID[id]
+		summary := work.summary
+		summary.Percentage = s.percentage(summary.TotalAmount, totalAmount)
+
+		summary.ByParticipant = make([]model.CategoryShare, 0, len(event.Participants))
+		for _, person := range event.Participants {
+			amount, ok := work.shares[person.ID]
+			if !ok {
+				continue
+			}
+			summary.ByParticipant = append(summary.ByParticipant, model.CategoryShare{
+				ID:         person.ID,
+				Name:       person.Name,
+				Amount:     amount,
+				Percentage: s.percentage(amount, summary.TotalAmount),
+			})
+		}
+
+		categories = append(categories, summary)
+	}
+
+	// Sortowanie malejąco po kwocie, przy równych kwotach po nazwie
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].TotalAmount != categories[j].TotalAmount {
+			return categories[i].TotalAmount > categories[j].TotalAmount
+		}
+		return categories[i].Name < categories[j].Name
+	})
+
+	return categories
+}
+
+// ValidateCategories sprawdza poprawność katalogu kategorii wydarzenia
+func (s *ExpenseService) ValidateCategories(categories []model.Category) error {
+	seen := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		if strings.TrimSpace(c.ID) == "" {
+			return errors.New("category ID is required")
+		}
+		if strings.TrimSpace(c.Name) == "" {
+			return fmt.Errorf("category %q: name is required", c.ID)
+		}
+		key := strings.ToLower(c.ID)
+		if seen[key] {
+			return fmt.Errorf("duplicate category ID %q", c.ID)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// percentage zwraca udział procentowy części w całości zaokrąglony do dwóch miejsc
+func (s *ExpenseService) percentage(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return s.RoundToTwo(part / total * 100)
+}
+
+// categorySlug wyznacza identyfikator kategorii na podstawie jej nazwy
+func categorySlug(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"math"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// AddExpense dodaje wydatek do wydarzenia, nadając mu kolejny identyfikator,
+// i zwraca dodany wydatek
+func (s *ExpenseService) AddExpense(event *model.Event, exp model.Expense) *model.Expense {
+	maxID := 0
+	for _, e := range event.Expenses {
+		maxID = max(maxID, e.ID)
+	}
+
+	exp.ID = maxID + 1
+	event.Expenses = append(event.Expenses, exp)
+	return &event.Expenses[len(event.Expenses)-1]
+}
+
+// ReplaceExpense zastępuje wydatek o tym samym identyfikatorze
+func (s *ExpenseService) ReplaceExpense(event *model.Event, exp model.Expense) (*model.Expense, error) {
+	target := findExpense(event, exp.ID)
+	if target == nil {
+		return nil, ErrExpenseNotFound
+	}
+
+	*target = exp
+	return target, nil
+}
+
+// SetPayments zastępuje płatności wydatku
+func (s *ExpenseService) SetPayments(event *model.Event, expenseID int, payments []model.Payment) (*model.Expense, error) {
+	target := findExpense(event, expenseID)
+	if target == nil {
+		return nil, ErrExpenseNotFound
+	}
+
+	target.Payments = payments
+	return target, nil
+}
+
+// RemoveExpense usuwa wydatek z wydarzenia
+func (s *ExpenseService) RemoveExpense(event *model.Event, expenseID int) error {
+	for i, e := range event.Expenses {
+		if e.ID == expenseID {
+			event.Expenses = append(event.Expenses[:i], event.Expenses[i+1:]...)
+			return nil
+		}
+	}
+	return ErrExpenseNotFound
+}
+
+// DiffSummaries wyznacza zmiany między poprzednim a bieżącym podsumowaniem. Bilanse
+// zawierają tylko uczestników, których bilans się zmienił (także dodanych i usuniętych,
+// z bilansem zero), a rozliczenia są podawane w całości, jeśli się zmieniły.
+func (s *ExpenseService) DiffSummaries(previous, current *model.Summary) model.SummaryDelta {
+	if previous == nil {
+		previous = &model.Summary{}
+	}
+
+	delta := model.SummaryDelta{
+		TotalAmount: current.TotalAmount,
+		TotalChange: s.RoundToTwo(current.TotalAmount - previous.TotalAmount),
+	}
+
+	before := make(map[int]model.ParticipantBalance, len(previous.PaidByPerson))
+	for _, b := range previous.PaidByPerson {
+		before[b.ID] = b
+	}
+	for _, b := range current.PaidByPerson {
+		old, found := before[b.ID]
+		delete(before, b.ID)
+		if found && math.Abs(b.Balance-old.Balance) < 0.005 {
+			continue
+		}
+		delta.Balances = append(delta.Balances, model.BalanceChange{
+			ID:      b.ID,
+			Name:    b.Name,
+			Balance: b.Balance,
+			Change:  s.RoundToTwo(b.Balance - old.Balance),
+		})
+	}
+	for _, old := range previous.PaidByPerson {
+		if _, removed := before[old.ID]; removed {
+			delta.Balances = append(delta.Balances, model.BalanceChange{
+				ID:     old.ID,
+				Name:   old.Name,
+				Change: s.RoundToTwo(-old.Balance),
+			})
+		}
+	}
+
+	if !equalSettlements(previous.Settlements, current.Settlements) {
+		delta.SettlementsChanged = true
+		delta.Settlements = current.Settlements
+	}
+
+	return delta
+}
+
+// equalSettlements porównuje listy rozliczeń z dokładnością do grosza
+func equalSettlements(a, b []model.Settlement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].From != b[i].From || a[i].To != b[i].To || math.Abs(a[i].Amount-b[i].Amount) >= 0.005 {
+			return false
+		}
+	}
+	return true
+}
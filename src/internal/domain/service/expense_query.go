@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Pola, po których można sortować wydatki
+const (
+	SortByID        = "id"
+	SortByDate      = "date"
+	SortByAmount    = "amount"
+	SortByCategory  = "category"
+	SortByCreatedAt = "createdAt"
+)
+
+// ExpenseQuery opisuje filtrowanie i sortowanie listy wydatków
+type ExpenseQuery struct {
+	From       model.Date
+	To         model.Date
+	SortBy     string
+	Descending bool
+}
+
+// ParseExpenseSort parsuje parametr sortowania, np. "date" lub "-amount" (malejąco)
+func ParseExpenseSort(value string) (string, bool, error) {
+	descending := strings.HasPrefix(value, "-")
+	field := strings.TrimPrefix(value, "-")
+
+	switch field {
+	case "":
+		return SortByID, false, nil
+	case SortByID, SortByDate, SortByAmount, SortByCategory, SortByCreatedAt:
+		return field, descending, nil
+	default:
+		return "", false, fmt.Errorf("unsupported sort field %q", field)
+	}
+}
+
+// QueryExpenses zwraca wydatki wydarzenia z zakresu dat posortowane według zapytania.
+// Przy podanym zakresie dat wydatki bez daty są pomijane.
+func (s *ExpenseService) QueryExpenses(event *model.Event, query ExpenseQuery) []model.Expense {
+	expenses := make([]model.Expense, 0, len(event.Expenses))
+	for _, exp := range event.Expenses {
+		if !query.From.IsZero() || !query.To.IsZero() {
+			if exp.Date.IsZero() {
+				continue
+			}
+			if !query.From.IsZero() && exp.Date.Before(query.From) {
+				continue
+			}
+			if !query.To.IsZero() && exp.Date.After(query.To) {
+				continue
+			}
+		}
+		expenses = append(expenses, exp)
+	}
+
+	less := func(a, b model.Expense) bool {
+		switch query.SortBy {
+		case SortByDate:
+			if !a.Date.Equal(b.Date.Time) {
+				return a.Date.Before(b.Date)
+			}
+		case SortByAmount:
+			totalA, _ := s.ExpenseTotal(a)
+			totalB, _ := s.ExpenseTotal(b)
+			if totalA != totalB {
+				return totalA < totalB
+			}
+		case SortByCategory:
+			if a.Category != b.Category {
+				return a.Category < b.Category
+			}
+		case SortByCreatedAt:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.ID < b.ID
+	}
+
+	sort.SliceStable(expenses, func(i, j int) bool {
+		if query.Descending {
+			return less(expenses[j], expenses[i])
+		}
+		return less(expenses[i], expenses[j])
+	})
+
+	return expenses
+}
@@ -19,38 +19,69 @@ func (s *ExpenseService) RoundToTwo(num float64) float64 {
 	return math.Round(num*100) / 100
 }
 
-// CalculateSummary oblicza podsumowanie wydarzenia
-func (s *ExpenseService) CalculateSummary(event *model.Event) *model.Summary {
-	// Przetwarzanie wydatków
-	totalAmount := 0.0
+// ExpenseSign zwraca -1 dla zwrotów i przychodów oraz 1 dla zwykłych wydatków
+func (s *ExpenseService) ExpenseSign(exp model.Expense) float64 {
+	if exp.Kind == model.ExpenseKindRefund {
+		return -1
+	}
+	return 1
+}
 
-	type processedExpense struct {
-		expense              model.Expense
-		processedTotalAmount float64
-		processedPayments    float64
+// ExpenseTotal zwraca kwotę wydatku oraz sumę jego płatności.
+// Jeśli nie podano totalAmount, kwotą wydatku jest suma płatności.
+// Dla zwrotów obie kwoty są ujemne, dzięki czemu pomniejszają sumy i udziały.
+func (s *ExpenseService) ExpenseTotal(exp model.Expense) (float64, float64) {
+	paymentsSum := 0.0
+	for _, payment := range exp.Payments {
+		paymentsSum = s.RoundToTwo(paymentsSum + payment.Amount)
 	}
 
-	processedExpenses := make([]processedExpense, len(event.Expenses))
+	totalExp := exp.TotalAmount
+	if totalExp == 0 && len(exp.Items) > 0 {
+		_, totalExp = s.ItemsTotal(exp)
+	}
+	if totalExp == 0 {
+		totalExp = paymentsSum
+	}
 
-	for i, exp := range event.Expenses {
-		// Suma wszystkich płatności
-		paymentsSum := 0.0
-		for _, payment := range exp.Payments {
-			paymentsSum = s.RoundToTwo(paymentsSum + payment.Amount)
-		}
+	sign := s.ExpenseSign(exp)
+	return sign * totalExp, sign * paymentsSum
+}
 
-		// Jeśli podano totalAmount, używamy go, w przeciwnym razie suma płatności
-		totalExp := exp.TotalAmount
-		if totalExp == 0 {
-			totalExp = paymentsSum
-		}
+// ExpenseShares zwraca kwoty, które poszczególni uczestnicy powinni pokryć w ramach wydatku.
+// Dla rachunków z pozycjami udziały wyznaczane są z pozycji zamiast z SharedWith.
+func (s *ExpenseService) ExpenseShares(event *model.Event, exp model.Expense) map[int]float64 {
+	sharedWith := s.EffectiveSharedWith(event, exp)
+	if len(exp.Items) > 0 {
+		return s.itemShares(exp, sharedWith)
+	}
 
-		processedExpenses[i] = processedExpense{
-			expense:              exp,
-			processedTotalAmount: totalExp,
-			processedPayments:    paymentsSum,
-		}
+	totalExp, _ := s.ExpenseTotal(exp)
+
+	shares := make(map[int]float64, len(sharedWith))
+	sharedCount := len(sharedWith)
+	if sharedCount == 0 {
+		return shares
+	}
+
+	perPersonInExpense := s.RoundToTwo(totalExp / float64(sharedCount))
+	for _, id := range sharedWith {
+		shares[id] = perPersonInExpense
+	}
+
+	return shares
+}
+
+// CalculateSummary oblicza podsumowanie wydarzenia.
+// Uwzględnia tylko bieżący (niezamknięty) okres rozliczeniowy wraz z bilansami otwarcia.
+func (s *ExpenseService) CalculateSummary(event *model.Event) *model.Summary {
+	event = s.CurrentPeriod(event)
+
+	// Przetwarzanie wydatków
+	totalAmount := 0.0
 
+	for _, exp := range event.Expenses {
+		totalExp, _ := s.ExpenseTotal(exp)
 		totalAmount = s.RoundToTwo(totalAmount + totalExp)
 	}
 
@@ -60,63 +91,82 @@ func (s *ExpenseService) CalculateSummary(event *model.Event) *model.Summary {
 		perPersonAmount = s.RoundToTwo(totalAmount / float64(len(event.Participants)))
 	}
 
+	// Bilanse uczestników
+	paidByPerson := s.CalculateBalances(event)
+
+	// Obliczanie rozliczeń
+	settlements := s.CalculateSettlements(paidByPerson)
+
+	return &model.Summary{
+		TotalAmount:          totalAmount,
+		PerPersonAmount:      perPersonAmount,
+		PaidByPerson:         paidByPerson,
+		Settlements:          settlements,
+		Categories:           s.CalculateCategoryBreakdown(event),
+		HouseholdBalances:    s.CalculateHouseholdBalances(event, paidByPerson),
+		HouseholdSettlements: s.CalculateHouseholdSettlements(event, paidByPerson),
+	}
+}
+
+// CalculateBalances oblicza ile każdy uczestnik zapłacił, ile powinien zapłacić oraz jego bilans
+func (s *ExpenseService) CalculateBalances(event *model.Event) []model.ParticipantBalance {
+	expenseShares := make([]map[int]float64, len(event.Expenses))
+	for i, exp := range event.Expenses {
+		expenseShares[i] = s.ExpenseShares(event, exp)
+	}
+
+	// Bilanse przeniesione z zamkniętych okresów
+	opening := make(map[int]float64, len(event.OpeningBalances))
+	for _, ob := range event.OpeningBalances {
+		opening[ob.ParticipantID] = s.RoundToTwo(opening[ob.ParticipantID] + ob.Amount)
+	}
+
+	// Spłaty między uczestnikami: wysłane zwiększają bilans, otrzymane go zmniejszają
+	repaid := make(map[int]float64, len(event.Repayments))
+	for _, r := range event.Repayments {
+		repaid[r.From] = s.RoundToTwo(repaid[r.From] + r.Amount)
+		repaid[r.To] = s.RoundToTwo(repaid[r.To] - r.Amount)
+	}
+
 	// Ile każdy zapłacił
 	paidByPerson := make([]model.ParticipantBalance, len(event.Participants))
 
 	for i, person := range event.Participants {
 		paidAmount := 0.0
 
-		// Obliczanie ile osoba zapłaciła
+		// Obliczanie ile osoba zapłaciła (otrzymane zwroty pomniejszają wpłaty)
 		for _, exp := range event.Expenses {
+			sign := s.ExpenseSign(exp)
 			for _, payment := range exp.Payments {
 				if payment.ParticipantID == person.ID {
-					paidAmount = s.RoundToTwo(paidAmount + payment.Amount)
+					paidAmount = s.RoundToTwo(paidAmount + sign*payment.Amount)
 				}
 			}
 		}
 
 		// Obliczanie ile osoba powinna zapłacić
 		shouldPay := 0.0
-		for _, exp := range processedExpenses {
-			isShared := false
-			for _, id := range exp.expense.SharedWith {
-				if id == person.ID {
-					isShared = true
-					break
-				}
-			}
-
-			if isShared {
-				sharedCount := len(exp.expense.SharedWith)
-				if sharedCount == 0 {
-					sharedCount = 1
-				}
-				perPersonInExpense := s.RoundToTwo(exp.processedTotalAmount / float64(sharedCount))
-				shouldPay = s.RoundToTwo(shouldPay + perPersonInExpense)
+		for _, shares := range expenseShares {
+			if share, ok := shares[person.ID]; ok {
+				shouldPay = s.RoundToTwo(shouldPay + share)
 			}
 		}
 
 		// Bilans
-		balance := s.RoundToTwo(paidAmount - shouldPay)
+		balance := s.RoundToTwo(opening[person.ID] + paidAmount - shouldPay + repaid[person.ID])
 
 		paidByPerson[i] = model.ParticipantBalance{
 			ID:        person.ID,
 			Name:      person.Name,
+			Opening:   opening[person.ID],
 			Paid:      paidAmount,
 			ShouldPay: shouldPay,
+			Repaid:    repaid[person.ID],
 			Balance:   balance,
 		}
 	}
 
-	// Obliczanie rozliczeń
-	settlements := s.CalculateSettlements(paidByPerson)
-
-	return &model.Summary{
-		TotalAmount:     totalAmount,
-		PerPersonAmount: perPersonAmount,
-		PaidByPerson:    paidByPerson,
-		Settlements:     settlements,
-	}
+	return paidByPerson
 }
 
 // CalculateSettlements oblicza rozliczenia między uczestnikami
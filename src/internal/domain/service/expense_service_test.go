@@ -1,10 +1,15 @@
 package service_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/inflop/splitty.api/internal/domain/model"
+	domain "github.com/inflop/splitty.api/internal/domain/repository"
 	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/repository"
 )
 
 func TestCalculateSummary(t *testing.T) {
@@ -101,3 +106,1512 @@ func TestCalculateSummary(t *testing.T) {
 		}
 	}
 }
+
+func TestCalculateCategoryBreakdown(t *testing.T) {
+	// Przygotowanie danych testowych - "Jedzenie" i "Food" to ta sama kategoria
+	event := &model.Event{
+		ID:   1,
+		Name: "Test Event",
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+		},
+		Categories: []model.Category{
+			{ID: "ski", Name: "Narty", Aliases: []string{"Skipass"}},
+		},
+		Expenses: []model.Expense{
+			{
+				ID:          1,
+				Category:    "Jedzenie",
+				TotalAmount: 100,
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 100}},
+				SharedWith:  []int{1, 2},
+			},
+			{
+				ID:          2,
+				Category:    "food",
+				TotalAmount: 50,
+				Payments:    []model.Payment{{ParticipantID: 2, Amount: 50}},
+				SharedWith:  []int{2},
+			},
+			{
+				ID:          3,
+				Category:    "Skipass",
+				TotalAmount: 250,
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 250}},
+				SharedWith:  []int{1, 2},
+			},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+	categories := expenseService.CalculateSummary(event).Categories
+
+	if len(categories) != 2 {
+		t.Fatalf("Expected 2 categories, got %d", len(categories))
+	}
+
+	// Kategorie są posortowane malejąco po kwocie
+	if categories[0].ID != "ski" || categories[0].TotalAmount != 250 {
+		t.Errorf("Expected first category ski with 250, got %s with %v", categories[0].ID, categories[0].TotalAmount)
+	}
+
+	food := categories[1]
+	if food.ID != "food" || food.TotalAmount != 150 || food.ExpenseCount != 2 {
+		t.Errorf("Expected food with 150 in 2 expenses, got %s with %v in %d", food.ID, food.TotalAmount, food.ExpenseCount)
+	}
+
+	if food.Percentage != 37.5 {
+		t.Errorf("Expected food percentage to be 37.5, got %v", food.Percentage)
+	}
+
+	// Alice: 50 z 150, Bob: 50 + 50 = 100 z 150
+	for _, share := range food.ByParticipant {
+		switch share.ID {
+		case 1:
+			if share.Amount != 50 || share.Percentage != 33.33 {
+				t.Errorf("Expected Alice to consume 50 (33.33%%), got %v (%v%%)", share.Amount, share.Percentage)
+			}
+		case 2:
+			if share.Amount != 100 || share.Percentage != 66.67 {
+				t.Errorf("Expected Bob to consume 100 (66.67%%), got %v (%v%%)", share.Amount, share.Percentage)
+			}
+		}
+	}
+}
+
+func TestQueryExpenses(t *testing.T) {
+	event := &model.Event{
+		Expenses: []model.Expense{
+			{ID: 1, TotalAmount: 30, Date: model.NewDate(2024, 7, 3)},
+			{ID: 2, TotalAmount: 10, Date: model.NewDate(2024, 7, 1)},
+			{ID: 3, TotalAmount: 20},
+			{ID: 4, TotalAmount: 40, Date: model.NewDate(2024, 7, 10)},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	// Filtrowanie po zakresie dat pomija wydatki bez daty
+	expenses := expenseService.QueryExpenses(event, service.ExpenseQuery{
+		From:   model.NewDate(2024, 7, 1),
+		To:     model.NewDate(2024, 7, 5),
+		SortBy: service.SortByDate,
+	})
+	if len(expenses) != 2 || expenses[0].ID != 2 || expenses[1].ID != 1 {
+		t.Errorf("Expected expenses 2 and 1 sorted by date, got %+v", expenses)
+	}
+
+	// Sortowanie malejąco po kwocie
+	expenses = expenseService.QueryExpenses(event, service.ExpenseQuery{
+		SortBy:     service.SortByAmount,
+		Descending: true,
+	})
+	ids := []int{}
+	for _, e := range expenses {
+		ids = append(ids, e.ID)
+	}
+	if len(ids) != 4 || ids[0] != 4 || ids[1] != 1 || ids[2] != 3 || ids[3] != 2 {
+		t.Errorf("Expected expenses sorted by amount descending [4 1 3 2], got %v", ids)
+	}
+}
+
+func TestValidateExpenseIDs(t *testing.T) {
+	event := &model.Event{
+		Participants: []model.Participant{{ID: 1, Name: "Alice"}},
+		Expenses: []model.Expense{
+			{ID: 1, TotalAmount: 10, Payments: []model.Payment{{ParticipantID: 1, Amount: 10}}},
+			{ID: 2, TotalAmount: 20, Payments: []model.Payment{{ParticipantID: 1, Amount: 20}}},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Fatalf("Expected unique expense IDs to be valid, got %v", err)
+	}
+
+	event.Expenses[1].ID = 1
+	if err := expenseService.ValidateEvent(event); err == nil {
+		t.Error("Expected duplicate expense ID to be invalid")
+	}
+
+	event.Expenses[1].ID = 0
+	if err := expenseService.ValidateEvent(event); err == nil {
+		t.Error("Expected zero expense ID to be invalid")
+	}
+}
+
+func TestRecurringAddedAfterClosedPeriod(t *testing.T) {
+	event := &model.Event{
+		Participants: []model.Participant{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}},
+		Expenses: []model.Expense{{
+			ID:          1,
+			TotalAmount: 100,
+			Date:        model.NewDate(2024, 1, 15),
+			Payments:    []model.Payment{{ParticipantID: 1, Amount: 100}},
+			SharedWith:  []int{1, 2},
+		}},
+	}
+
+	expenseService := service.NewExpenseService()
+	if _, err := expenseService.ClosePeriod(event, model.NewDate(2024, 6, 30), time.Now()); err != nil {
+		t.Fatalf("Failed to close period: %v", err)
+	}
+	previous := *event
+
+	// Szablon dodany po zamknięciu okresu, zaczynający się w zamkniętym okresie
+	event.Recurring = []model.RecurringExpense{{
+		ID:        1,
+		Rule:      "monthly",
+		StartDate: model.NewDate(2024, 1, 1),
+		Template:  model.Expense{TotalAmount: 50, Payments: []model.Payment{{ParticipantID: 2, Amount: 50}}, SharedWith: []int{1, 2}},
+	}}
+	if err := expenseService.PreserveServerState(&previous, event); err != nil {
+		t.Fatalf("Expected template to be accepted, got %v", err)
+	}
+
+	created, err := expenseService.MaterializeRecurring(event, model.NewDate(2024, 8, 15))
+	if err != nil || created != 2 {
+		t.Fatalf("Expected only July and August occurrences, got %d (%v)", created, err)
+	}
+	if total := expenseService.CalculateSummary(event).TotalAmount; total != 100 {
+		t.Errorf("Expected current period total 100, got %v", total)
+	}
+
+	// Kolejny zapis nie narusza zamkniętego okresu
+	saved := *event
+	update := *event
+	update.Expenses = append([]model.Expense(nil), event.Expenses...)
+	update.Recurring = append([]model.RecurringExpense(nil), event.Recurring...)
+	if err := expenseService.PreserveServerState(&saved, &update); err != nil {
+		t.Errorf("Expected later update to succeed, got %v", err)
+	}
+}
+
+func TestCalculateTimeline(t *testing.T) {
+	event := &model.Event{
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+		},
+		Expenses: []model.Expense{
+			{
+				ID:          1,
+				TotalAmount: 100,
+				Date:        model.NewDate(2024, 7, 2),
+				Payments:    []model.Payment{{ParticipantID: 2, Amount: 100}},
+				SharedWith:  []int{1, 2},
+			},
+			{
+				ID:          2,
+				TotalAmount: 60,
+				Date:        model.NewDate(2024, 7, 1),
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 60}},
+				SharedWith:  []int{1, 2},
+			},
+			{
+				ID:          3,
+				TotalAmount: 40,
+				Date:        model.NewDate(2024, 7, 2),
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 40}},
+				SharedWith:  []int{1, 2},
+			},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	timeline, err := expenseService.CalculateTimeline(event, service.TimelineByDay)
+	if err != nil {
+		t.Fatalf("Failed to calculate timeline: %v", err)
+	}
+
+	if len(timeline) != 2 {
+		t.Fatalf("Expected 2 days on timeline, got %d", len(timeline))
+	}
+
+	// Po pierwszym dniu Alice jest na plusie 30
+	if timeline[0].TotalAmount != 60 || timeline[0].Balances[0].Balance != 30 {
+		t.Errorf("Expected Alice's balance after day 1 to be 30, got %v", timeline[0].Balances[0].Balance)
+	}
+
+	// Po drugim dniu: Alice zapłaciła 100, powinna 100
+	if timeline[1].TotalAmount != 200 || timeline[1].Balances[0].Balance != 0 {
+		t.Errorf("Expected Alice's balance after day 2 to be 0, got %v", timeline[1].Balances[0].Balance)
+	}
+
+	timeline, _ = expenseService.CalculateTimeline(event, service.TimelineByExpense)
+	if len(timeline) != 3 || timeline[0].ExpenseID != 2 {
+		t.Errorf("Expected 3 points starting with expense 2, got %+v", timeline)
+	}
+
+	// Spłata z drugiego dnia nie wpływa na pierwszy punkt, a na kolejne już tak
+	event.Expenses = append(event.Expenses, model.Expense{
+		ID:          4,
+		TotalAmount: 20,
+		Date:        model.NewDate(2024, 7, 3),
+		Payments:    []model.Payment{{ParticipantID: 2, Amount: 20}},
+		SharedWith:  []int{1, 2},
+	})
+	event.Repayments = []model.Repayment{{ID: 1, From: 2, To: 1, Amount: 30, Date: model.NewDate(2024, 7, 2)}}
+	timeline, _ = expenseService.CalculateTimeline(event, service.TimelineByDay)
+	if len(timeline) != 3 {
+		t.Fatalf("Expected 3 days on timeline, got %d", len(timeline))
+	}
+	for i, expected := range []float64{30, -30, -40} {
+		if balance := timeline[i].Balances[0].Balance; balance != expected {
+			t.Errorf("Expected Alice's balance after day %d to be %v, got %v", i+1, expected, balance)
+		}
+	}
+	if final := expenseService.CalculateBalances(event); timeline[2].Balances[0].Balance != final[0].Balance {
+		t.Errorf("Expected last point to match current balance %v", final[0].Balance)
+	}
+
+	// Spłata po ostatnim wydatku tworzy własny punkt zgodny z podsumowaniem
+	late := &model.Event{
+		Participants: event.Participants,
+		Expenses: []model.Expense{{
+			ID:          1,
+			TotalAmount: 100,
+			Date:        model.NewDate(2024, 7, 1),
+			Payments:    []model.Payment{{ParticipantID: 1, Amount: 100}},
+			SharedWith:  []int{1, 2},
+		}},
+		Repayments: []model.Repayment{{ID: 1, From: 2, To: 1, Amount: 50, Date: model.NewDate(2024, 7, 5)}},
+	}
+	for _, granularity := range []string{service.TimelineByDay, service.TimelineByExpense} {
+		timeline, _ = expenseService.CalculateTimeline(late, granularity)
+		if len(timeline) != 2 {
+			t.Fatalf("Expected 2 points for %s granularity, got %d", granularity, len(timeline))
+		}
+		last := timeline[1]
+		if !last.Date.Equal(model.NewDate(2024, 7, 5).Time) || last.ExpenseID != 0 || last.TotalAmount != 100 {
+			t.Errorf("Expected repayment point on 2024-07-05, got %+v", last)
+		}
+		if last.Balances[0].Balance != 0 || last.Balances[1].Balance != 0 {
+			t.Errorf("Expected settled balances after repayment, got %+v", last.Balances)
+		}
+	}
+
+	// Wydarzenie bez wydatków nadal pokazuje spłaty
+	late.Expenses = nil
+	timeline, _ = expenseService.CalculateTimeline(late, service.TimelineByExpense)
+	if len(timeline) != 1 || timeline[0].Balances[0].Balance != -50 {
+		t.Errorf("Expected single repayment point, got %+v", timeline)
+	}
+
+	if _, err := expenseService.CalculateTimeline(event, "hour"); err == nil {
+		t.Error("Expected error for unsupported granularity")
+	}
+}
+
+func TestCalculateSummaryWithRefund(t *testing.T) {
+	// Alice zapłaciła kaucję 300, po wyjeździe kaucja wróciła na jej konto
+	event := &model.Event{
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+			{ID: 3, Name: "Charlie"},
+		},
+		Expenses: []model.Expense{
+			{
+				ID:          1,
+				Category:    "Nocleg",
+				TotalAmount: 900,
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 900}},
+				SharedWith:  []int{1, 2, 3},
+			},
+			{
+				ID:         2,
+				Kind:       model.ExpenseKindRefund,
+				Category:   "Nocleg",
+				Payments:   []model.Payment{{ParticipantID: 1, Amount: 300}},
+				SharedWith: []int{1, 2, 3},
+			},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Fatalf("Expected refund to be valid, got %v", err)
+	}
+
+	summary := expenseService.CalculateSummary(event)
+
+	if summary.TotalAmount != 600 {
+		t.Errorf("Expected net total amount to be 600, got %v", summary.TotalAmount)
+	}
+
+	// Alice: zapłaciła 900 - 300 = 600, powinna 300 - 100 = 200, bilans +400
+	alice := summary.PaidByPerson[0]
+	if alice.Paid != 600 || alice.ShouldPay != 200 || alice.Balance != 400 {
+		t.Errorf("Expected Alice paid 600, should pay 200, balance 400, got %+v", alice)
+	}
+
+	if len(summary.Categories) != 1 || summary.Categories[0].TotalAmount != 600 {
+		t.Errorf("Expected accommodation category total to be 600, got %+v", summary.Categories)
+	}
+
+	// Zwrot bez odbiorców jest niepoprawny
+	event.Expenses[1].SharedWith = nil
+	if err := expenseService.ValidateEvent(event); err == nil {
+		t.Error("Expected refund without SharedWith to be invalid")
+	}
+}
+
+func TestCalculateSummaryWithItems(t *testing.T) {
+	// Rachunek w restauracji: 10% podatku i 18 napiwku rozłożone proporcjonalnie do pozycji
+	event := &model.Event{
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+			{ID: 3, Name: "Charlie"},
+		},
+		Expenses: []model.Expense{
+			{
+				ID:       1,
+				Category: "Jedzenie",
+				Payments: []model.Payment{{ParticipantID: 1, Amount: 216}},
+				Items: []model.ExpenseItem{
+					{Description: "Steak", Amount: 80, Participants: []int{1}},
+					{Description: "Pasta", Amount: 40, Participants: []int{2}},
+					{Description: "Wine", Amount: 20, Quantity: 3, Participants: []int{2, 3}},
+				},
+				Tax: 18,
+				Tip: 18,
+			},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Fatalf("Expected itemised expense to be valid, got %v", err)
+	}
+
+	summary := expenseService.CalculateSummary(event)
+
+	if summary.TotalAmount != 216 {
+		t.Fatalf("Expected total amount to be 216, got %v", summary.TotalAmount)
+	}
+
+	// Współczynnik 216/180 = 1.2: Alice 80*1.2, Bob (40+30)*1.2, Charlie 30*1.2
+	expected := map[int]float64{1: 96, 2: 84, 3: 36}
+	for _, balance := range summary.PaidByPerson {
+		if balance.ShouldPay != expected[balance.ID] {
+			t.Errorf("Expected participant %d to owe %v, got %v", balance.ID, expected[balance.ID], balance.ShouldPay)
+		}
+	}
+
+	// Niezgodna kwota całkowita jest błędem
+	event.Expenses[0].TotalAmount = 200
+	if err := expenseService.ValidateEvent(event); err == nil {
+		t.Error("Expected mismatched total amount to be invalid")
+	}
+}
+
+func TestMaterializeRecurring(t *testing.T) {
+	event := &model.Event{
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+		},
+		Expenses: []model.Expense{{ID: 7, TotalAmount: 10}},
+		Recurring: []model.RecurringExpense{
+			{
+				ID:        1,
+				Rule:      "FREQ=MONTHLY;INTERVAL=1",
+				StartDate: model.NewDate(2024, 1, 31),
+				Template: model.Expense{
+					Category:    "Media",
+					Description: "Czynsz",
+					TotalAmount: 2000,
+					Payments:    []model.Payment{{ParticipantID: 1, Amount: 2000}},
+					SharedWith:  []int{1, 2},
+				},
+				Exceptions: []model.RecurrenceException{
+					{Date: model.NewDate(2024, 2, 29), Skip: true},
+					{Date: model.NewDate(2024, 3, 31), Override: &model.Expense{TotalAmount: 2100, Payments: []model.Payment{{ParticipantID: 1, Amount: 2100}}}},
+				},
+			},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Fatalf("Expected recurring expense to be valid, got %v", err)
+	}
+
+	created, err := expenseService.MaterializeRecurring(event, model.NewDate(2024, 4, 30))
+	if err != nil {
+		t.Fatalf("Failed to materialize recurring expenses: %v", err)
+	}
+
+	// Styczeń, marzec (zmieniony) i kwiecień (30. dzień), luty pominięty
+	if created != 3 || len(event.Expenses) != 4 {
+		t.Fatalf("Expected 3 created expenses, got %d (%d total)", created, len(event.Expenses))
+	}
+
+	march := event.Expenses[2]
+	if march.ID != 9 || march.TotalAmount != 2100 || !march.Date.Equal(model.NewDate(2024, 3, 31).Time) {
+		t.Errorf("Expected overridden March occurrence with ID 9, got %+v", march)
+	}
+	if april := event.Expenses[3]; !april.Date.Equal(model.NewDate(2024, 4, 30).Time) {
+		t.Errorf("Expected April occurrence clamped to the 30th, got %v", april.Date)
+	}
+
+	// Usunięte wystąpienie nie jest tworzone ponownie
+	event.Expenses = event.Expenses[:3]
+	created, _ = expenseService.MaterializeRecurring(event, model.NewDate(2024, 4, 30))
+	if created != 0 {
+		t.Errorf("Expected no new expenses on second run, got %d", created)
+	}
+
+	// Szablon starszy niż limit jednego przebiegu jest uzupełniany w kolejnych przebiegach,
+	// a COUNT liczony jest od początku reguły
+	daily := &model.Event{
+		Participants: []model.Participant{{ID: 1, Name: "Alice"}},
+		Recurring: []model.RecurringExpense{{
+			ID:        1,
+			Rule:      "FREQ=DAILY;COUNT=1200",
+			StartDate: model.NewDate(2020, 1, 1),
+			Template:  model.Expense{TotalAmount: 1, Payments: []model.Payment{{ParticipantID: 1, Amount: 1}}, SharedWith: []int{1}},
+		}},
+	}
+	var runs []int
+	for range 3 {
+		created, err := expenseService.MaterializeRecurring(daily, model.NewDate(2024, 1, 1))
+		if err != nil {
+			t.Fatalf("Failed to materialize daily expense: %v", err)
+		}
+		runs = append(runs, created)
+	}
+	if runs[0] != 1000 || runs[1] != 200 || runs[2] != 0 {
+		t.Errorf("Expected 1000, 200 and 0 occurrences per run, got %v", runs)
+	}
+	if last := daily.Expenses[len(daily.Expenses)-1].Date; !last.Equal(model.NewDate(2020, 1, 1).AddDays(1199).Time) {
+		t.Errorf("Expected last occurrence to be the 1200th day, got %v", last)
+	}
+}
+
+func TestClosePeriod(t *testing.T) {
+	event := &model.Event{
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+		},
+		Expenses: []model.Expense{
+			{
+				ID:          1,
+				TotalAmount: 100,
+				Date:        model.NewDate(2024, 1, 10),
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 100}},
+				SharedWith:  []int{1, 2},
+			},
+			{
+				ID:          2,
+				TotalAmount: 40,
+				Date:        model.NewDate(2024, 2, 5),
+				Payments:    []model.Payment{{ParticipantID: 2, Amount: 40}},
+				SharedWith:  []int{1, 2},
+			},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	period, err := expenseService.ClosePeriod(event, model.NewDate(2024, 1, 31), time.Now())
+	if err != nil {
+		t.Fatalf("Failed to close period: %v", err)
+	}
+
+	// Migawka obejmuje tylko styczeń: Bob jest winny Alice 50
+	if len(period.Summary.Settlements) != 1 || period.Summary.Settlements[0].Amount != 50 {
+		t.Errorf("Expected one settlement of 50 in closed period, got %+v", period.Summary.Settlements)
+	}
+
+	// Bieżący okres: bilans otwarcia Alice +50, luty: Alice -20
+	summary := expenseService.CalculateSummary(event)
+	if summary.TotalAmount != 40 {
+		t.Errorf("Expected current period total to be 40, got %v", summary.TotalAmount)
+	}
+	if alice := summary.PaidByPerson[0]; alice.Opening != 50 || alice.Balance != 30 {
+		t.Errorf("Expected Alice's opening 50 and balance 30, got %+v", alice)
+	}
+
+	// Wydatki zamkniętego okresu są zamrożone
+	previous := *event
+	update := *event
+	update.Expenses = []model.Expense{event.Expenses[1]}
+	if err := expenseService.PreservePeriods(&previous, &update); !errors.Is(err, service.ErrPeriodFrozen) {
+		t.Errorf("Expected ErrPeriodFrozen when removing closed expense, got %v", err)
+	}
+
+	if _, err := expenseService.ClosePeriod(event, model.NewDate(2024, 1, 15), time.Now()); err == nil {
+		t.Error("Expected error when closing a period ending before the last closed one")
+	}
+}
+
+func TestShareByPresence(t *testing.T) {
+	// Charlie dołączył do wyjazdu trzeciego dnia
+	event := &model.Event{
+		ShareByPresence: true,
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+			{ID: 3, Name: "Charlie", Presence: []model.PresencePeriod{{From: model.NewDate(2024, 7, 3)}}},
+		},
+		Expenses: []model.Expense{
+			{
+				ID:          1,
+				TotalAmount: 100,
+				Date:        model.NewDate(2024, 7, 1),
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 100}},
+			},
+			{
+				ID:          2,
+				TotalAmount: 90,
+				Date:        model.NewDate(2024, 7, 3),
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 90}},
+			},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Fatalf("Expected event to be valid, got %v", err)
+	}
+
+	// Charlie płaci tylko za trzeci dzień: 90 / 3 = 30
+	expected := map[int]float64{1: 80, 2: 80, 3: 30}
+	for _, balance := range expenseService.CalculateSummary(event).PaidByPerson {
+		if balance.ShouldPay != expected[balance.ID] {
+			t.Errorf("Expected participant %d to owe %v, got %v", balance.ID, expected[balance.ID], balance.ShouldPay)
+		}
+	}
+
+	// Jawne dzielenie z nieobecnym uczestnikiem jest błędem
+	event.Expenses[0].SharedWith = []int{1, 2, 3}
+	if err := expenseService.ValidateEvent(event); err == nil {
+		t.Error("Expected sharing with an absent participant to be invalid")
+	}
+}
+
+func TestHouseholdSettlements(t *testing.T) {
+	// Alice i Bob to para, Charlie i Dave rozliczają się osobno
+	event := &model.Event{
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+			{ID: 3, Name: "Charlie"},
+			{ID: 4, Name: "Dave"},
+		},
+		Households: []model.Household{
+			{ID: 1, Name: "Alice & Bob", Members: []int{1, 2}, TreasurerID: 2},
+		},
+		Expenses: []model.Expense{
+			{
+				ID:          1,
+				TotalAmount: 400,
+				Payments:    []model.Payment{{ParticipantID: 3, Amount: 400}},
+				SharedWith:  []int{1, 2, 3, 4},
+			},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Fatalf("Expected households to be valid, got %v", err)
+	}
+
+	summary := expenseService.CalculateSummary(event)
+
+	if len(summary.HouseholdBalances) != 1 || summary.HouseholdBalances[0].Balance != -200 {
+		t.Fatalf("Expected household balance -200, got %+v", summary.HouseholdBalances)
+	}
+
+	// Jeden przelew od pary (przez skarbnika Boba) i jeden od Dave'a
+	if len(summary.HouseholdSettlements) != 2 {
+		t.Fatalf("Expected 2 household settlements, got %+v", summary.HouseholdSettlements)
+	}
+	first := summary.HouseholdSettlements[0]
+	if first.From != 2 || first.FromHousehold != 1 || first.To != 3 || first.Amount != 200 {
+		t.Errorf("Expected household to pay Charlie 200 via Bob, got %+v", first)
+	}
+
+	// Uczestnik nie może należeć do dwóch gospodarstw
+	event.Households = append(event.Households, model.Household{ID: 2, Name: "Other", Members: []int{2}})
+	if err := expenseService.ValidateEvent(event); err == nil {
+		t.Error("Expected participant in two households to be invalid")
+	}
+}
+
+func TestRemoveParticipant(t *testing.T) {
+	newEvent := func() *model.Event {
+		return &model.Event{
+			Participants: []model.Participant{
+				{ID: 1, Name: "Alice"},
+				{ID: 2, Name: "Bob"},
+				{ID: 3, Name: "Bobby", Email: "bob@example.com"},
+			},
+			Expenses: []model.Expense{
+				{
+					ID:          1,
+					TotalAmount: 90,
+					Payments:    []model.Payment{{ParticipantID: 1, Amount: 60}, {ParticipantID: 3, Amount: 30}},
+					SharedWith:  []int{1, 2, 3},
+				},
+			},
+		}
+	}
+
+	expenseService := service.NewExpenseService()
+
+	// Domyślnie usunięcie uczestnika z odwołaniami jest odrzucane
+	event := newEvent()
+	if err := expenseService.RemoveParticipant(event, 3, service.RemovalRefuse, 0); !errors.Is(err, service.ErrParticipantReferenced) {
+		t.Errorf("Expected ErrParticipantReferenced, got %v", err)
+	}
+
+	// Połączenie duplikatu przenosi płatności i udziały bez zmiany sumy
+	event = newEvent()
+	if err := expenseService.MergeParticipants(event, 3, 2); err != nil {
+		t.Fatalf("Failed to merge participants: %v", err)
+	}
+	if len(event.Participants) != 2 || event.Participants[1].Email != "bob@example.com" {
+		t.Errorf("Expected Bob to take over Bobby's email, got %+v", event.Participants)
+	}
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Errorf("Expected merged event to be valid, got %v", err)
+	}
+	// Bob przejmuje udział Bobby'ego zamiast dzielić go ze wszystkimi
+	summary := expenseService.CalculateSummary(event)
+	if summary.TotalAmount != 90 || summary.PaidByPerson[1].Paid != 30 || summary.PaidByPerson[1].ShouldPay != 60 {
+		t.Errorf("Expected Bob to pay 30 and owe 60 after merge, got %+v", summary.PaidByPerson[1])
+	}
+
+	// Przeniesienie udziałów na osobę, która już dzieli wydatek, także z szablonu cyklicznego
+	// i zmiany wystąpienia z inną kwotą
+	event = newEvent()
+	event.Expenses[0].Payments = []model.Payment{{ParticipantID: 2, Amount: 90}}
+	event.Recurring = []model.RecurringExpense{{
+		ID:        1,
+		Rule:      "monthly",
+		StartDate: model.NewDate(2024, 1, 1),
+		Template: model.Expense{
+			TotalAmount: 100,
+			Payments:    []model.Payment{{ParticipantID: 2, Amount: 100}},
+			SharedWith:  []int{1, 2, 3},
+		},
+		Exceptions: []model.RecurrenceException{{
+			Date:     model.NewDate(2024, 2, 1),
+			Override: &model.Expense{TotalAmount: 60, Payments: []model.Payment{{ParticipantID: 2, Amount: 60}}},
+		}},
+	}}
+	if err := expenseService.RemoveParticipant(event, 3, service.RemovalReassign, 1); err != nil {
+		t.Fatalf("Failed to reassign participant: %v", err)
+	}
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Fatalf("Expected event to stay valid after reassignment, got %v", err)
+	}
+	if _, err := expenseService.MaterializeRecurring(event, model.NewDate(2024, 2, 1)); err != nil {
+		t.Fatalf("Failed to materialize recurring expense: %v", err)
+	}
+	for i, expected := range [][2]float64{{60, 30}, {66.67, 33.33}, {40, 20}} {
+		shares := expenseService.ExpenseShares(event, event.Expenses[i])
+		if shares[1] != expected[0] || shares[2] != expected[1] {
+			t.Errorf("Expected expense %d shares %v for Alice and Bob, got %v", event.Expenses[i].ID, expected, shares)
+		}
+	}
+
+	// Rozłożenie udziałów wymaga braku płatności usuwanego uczestnika
+	event = newEvent()
+	if err := expenseService.RemoveParticipant(event, 3, service.RemovalRedistribute, 0); err == nil {
+		t.Error("Expected redistribute to fail for participant with payments")
+	}
+	if err := expenseService.RemoveParticipant(event, 2, service.RemovalRedistribute, 0); err != nil {
+		t.Fatalf("Failed to redistribute participant: %v", err)
+	}
+	if shares := event.Expenses[0].SharedWith; len(shares) != 2 {
+		t.Errorf("Expected expense to be shared by 2 participants, got %v", shares)
+	}
+
+	// Udziały usuwane są także z pozycji szablonu cyklicznego i zmian jego wystąpień
+	event = newEvent()
+	event.Recurring = []model.RecurringExpense{{
+		ID:        1,
+		Rule:      "monthly",
+		StartDate: model.NewDate(2024, 1, 1),
+		Template: model.Expense{
+			TotalAmount: 30,
+			Payments:    []model.Payment{{ParticipantID: 1, Amount: 30}},
+			Items:       []model.ExpenseItem{{Description: "Internet", Amount: 30, Participants: []int{1, 2}}},
+		},
+		Exceptions: []model.RecurrenceException{{
+			Date:     model.NewDate(2024, 2, 1),
+			Override: &model.Expense{Description: "Internet i TV", SharedWith: []int{1, 2}},
+		}},
+	}}
+	if err := expenseService.RemoveParticipant(event, 2, service.RemovalRedistribute, 0); err != nil {
+		t.Fatalf("Failed to redistribute participant with recurring expense: %v", err)
+	}
+	if refs := expenseService.ParticipantReferences(event, 2); len(refs) != 0 {
+		t.Errorf("Expected no references to removed participant, got %v", refs)
+	}
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Errorf("Expected event to stay valid after redistribution, got %v", err)
+	}
+
+	// Zmiana wystąpienia obciążająca tylko usuwanego uczestnika blokuje rozłożenie udziałów
+	event.Expenses[0].Payments = []model.Payment{{ParticipantID: 1, Amount: 90}}
+	event.Recurring[0].Exceptions[0].Override.SharedWith = []int{3}
+	if err := expenseService.RemoveParticipant(event, 3, service.RemovalRedistribute, 0); err == nil || !strings.Contains(err.Error(), "override") {
+		t.Errorf("Expected override shared only with participant 3 to block redistribution, got %v", err)
+	}
+}
+
+func TestCalculatePersonBalances(t *testing.T) {
+	// Alice jest winna Bobowi 50 za kolację, a Bob Alice 30 za wyjazd
+	events := []*model.Event{
+		{
+			ID:   1,
+			Name: "Kolacja",
+			Participants: []model.Participant{
+				{ID: 1, Name: "Alice", Email: "alice@example.com"},
+				{ID: 2, Name: "Bob", Email: "bob@example.com"},
+			},
+			Expenses: []model.Expense{{
+				ID:          1,
+				TotalAmount: 100,
+				Payments:    []model.Payment{{ParticipantID: 2, Amount: 100}},
+				SharedWith:  []int{1, 2},
+			}},
+		},
+		{
+			ID:   2,
+			Name: "Wyjazd",
+			Participants: []model.Participant{
+				{ID: 5, Name: "Bob", Email: "BOB@example.com"},
+				{ID: 6, Name: "Alice", Email: "alice@example.com"},
+			},
+			Expenses: []model.Expense{{
+				ID:          1,
+				TotalAmount: 60,
+				Payments:    []model.Payment{{ParticipantID: 6, Amount: 60}},
+				SharedWith:  []int{5, 6},
+			}},
+		},
+	}
+
+	expenseService := service.NewExpenseService()
+
+	balances, err := expenseService.CalculatePersonBalances(events, service.NormalizePersonID("alice@example.com"))
+	if err != nil {
+		t.Fatalf("Failed to calculate person balances: %v", err)
+	}
+
+	if len(balances.Events) != 2 || balances.NetBalance != -20 {
+		t.Errorf("Expected net balance -20 over 2 events, got %v over %d", balances.NetBalance, len(balances.Events))
+	}
+
+	// Po kompensacji zostaje jeden przelew 20 do Boba
+	if len(balances.Settlements) != 1 || balances.Settlements[0].To != "email:bob@example.com" || balances.Settlements[0].Amount != 20 {
+		t.Errorf("Expected single settlement of 20 to Bob, got %+v", balances.Settlements)
+	}
+
+	if _, err := expenseService.CalculatePersonBalances(events, service.NormalizePersonID("nobody@example.com")); !errors.Is(err, service.ErrPersonNotFound) {
+		t.Errorf("Expected ErrPersonNotFound, got %v", err)
+	}
+
+	// Rozliczona kolacja nie wpływa już na bilans, zostaje tylko dług Boba z wyjazdu
+	events[0].SettledAt = time.Now()
+	balances, err = expenseService.CalculatePersonBalances(events, service.NormalizePersonID("alice@example.com"))
+	if err != nil {
+		t.Fatalf("Failed to calculate person balances: %v", err)
+	}
+	if len(balances.Events) != 1 || balances.Events[0].EventID != 2 || balances.NetBalance != 30 {
+		t.Errorf("Expected net balance 30 from the open event only, got %v over %+v", balances.NetBalance, balances.Events)
+	}
+	if len(balances.Settlements) != 1 || balances.Settlements[0].From != "email:bob@example.com" || balances.Settlements[0].Amount != 30 {
+		t.Errorf("Expected single settlement of 30 from Bob, got %+v", balances.Settlements)
+	}
+
+	events[1].SettledAt = time.Now()
+	if _, err := expenseService.CalculatePersonBalances(events, service.NormalizePersonID("alice@example.com")); !errors.Is(err, service.ErrPersonNotFound) {
+		t.Errorf("Expected ErrPersonNotFound when all events are settled, got %v", err)
+	}
+}
+
+func TestGroupSettlement(t *testing.T) {
+	events := []*model.Event{
+		{
+			ID: 1,
+			Participants: []model.Participant{
+				{ID: 1, Name: "Alice", Email: "alice@example.com"},
+				{ID: 2, Name: "Bob", UserID: "bob"},
+			},
+			Expenses: []model.Expense{{
+				ID:          1,
+				TotalAmount: 100,
+				Payments:    []model.Payment{{ParticipantID: 2, Amount: 100}},
+				SharedWith:  []int{1, 2},
+			}},
+		},
+		{
+			ID: 2,
+			Participants: []model.Participant{
+				{ID: 1, Name: "Bob", UserID: "bob"},
+				{ID: 2, Name: "Alice", Email: "alice@example.com"},
+			},
+			Expenses: []model.Expense{{
+				ID:          1,
+				TotalAmount: 60,
+				Payments:    []model.Payment{{ParticipantID: 2, Amount: 60}},
+				SharedWith:  []int{1, 2},
+			}},
+		},
+		// Wydarzenie spoza grupy nie wpływa na rozliczenie
+		{
+			ID:           3,
+			Participants: []model.Participant{{ID: 1, Name: "Alice", Email: "alice@example.com"}},
+		},
+	}
+	group := &model.Group{
+		ID:       1,
+		Name:     "Znajomi",
+		Members:  []model.GroupMember{{Name: "Ala", Email: "alice@example.com"}, {Name: "Bob", UserID: "bob"}},
+		EventIDs: []int{1, 2},
+	}
+
+	expenseService := service.NewExpenseService()
+	if err := expenseService.ValidateGroup(group); err != nil {
+		t.Fatalf("Expected valid group, got %v", err)
+	}
+
+	summary := expenseService.CalculateGroupSummary(group, events)
+	if len(summary.EventIDs) != 2 {
+		t.Errorf("Expected 2 open events, got %v", summary.EventIDs)
+	}
+	if len(summary.Settlements) != 1 || summary.Settlements[0].FromName != "Ala" || summary.Settlements[0].Amount != 20 {
+		t.Errorf("Expected single settlement of 20 from Ala, got %+v", summary.Settlements)
+	}
+
+	settled, err := expenseService.SettleGroup(group, events, time.Now())
+	if err != nil || len(settled) != 2 {
+		t.Fatalf("Expected 2 settled events, got %d (%v)", len(settled), err)
+	}
+	if !events[2].SettledAt.IsZero() {
+		t.Error("Expected event outside the group to stay open")
+	}
+
+	if _, err := expenseService.SettleGroup(group, events, time.Now()); !errors.Is(err, service.ErrNothingToSettle) {
+		t.Errorf("Expected ErrNothingToSettle, got %v", err)
+	}
+
+	// Rozliczone wydarzenia nie są już uwzględniane w grupie, więc nie można ich zmieniać
+	if !errors.Is(service.EnsureEditable(events[0]), service.ErrEventSettled) {
+		t.Error("Expected settled event to be read-only")
+	}
+}
+
+// failingEventRepository odrzuca zapis wskazanego wydarzenia
+type failingEventRepository struct {
+	domain.EventRepository
+	failID int
+}
+
+func (r *failingEventRepository) Save(event *model.Event) error {
+	if event.ID == r.failID {
+		return errors.New("disk full")
+	}
+	return r.EventRepository.Save(event)
+}
+
+func TestSaveSettledEventsRollback(t *testing.T) {
+	store := repository.NewInMemoryEventRepository()
+	for i := 0; i < 3; i++ {
+		store.Save(&model.Event{Participants: []model.Participant{{ID: 1, Name: "Alice", UserID: "alice"}}})
+	}
+	group := &model.Group{ID: 1, Members: []model.GroupMember{{Name: "Alice", UserID: "alice"}}, EventIDs: []int{1, 2, 3}}
+
+	load := func() []*model.Event {
+		var events []*model.Event
+		for _, id := range group.EventIDs {
+			event, err := store.FindByID(id)
+			if err != nil {
+				t.Fatalf("Failed to load event %d: %v", id, err)
+			}
+			events = append(events, event)
+		}
+		return events
+	}
+
+	expenseService := service.NewExpenseService()
+
+	// Zapis drugiego wydarzenia się nie udaje, pierwsze musi wrócić do stanu otwartego
+	settled, err := expenseService.SettleGroup(group, load(), time.Now())
+	if err != nil {
+		t.Fatalf("Failed to settle group: %v", err)
+	}
+	err = expenseService.SaveSettledEvents(&failingEventRepository{EventRepository: store, failID: 2}, settled)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("Expected save error, got %v", err)
+	}
+	for _, event := range settled {
+		if !event.SettledAt.IsZero() {
+			t.Errorf("Expected event %d to be reset to open", event.ID)
+		}
+	}
+	for _, event := range load() {
+		if !event.SettledAt.IsZero() {
+			t.Errorf("Expected stored event %d to stay open", event.ID)
+		}
+	}
+
+	// Nieaktualna wersja blokuje zapis wszystkich wydarzeń
+	settled, _ = expenseService.SettleGroup(group, load(), time.Now())
+	settled[2].Version--
+	if err := expenseService.SaveSettledEvents(store, settled); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("Expected version conflict, got %v", err)
+	}
+	for _, event := range load() {
+		if !event.SettledAt.IsZero() {
+			t.Errorf("Expected stored event %d to stay open after conflict", event.ID)
+		}
+	}
+
+	// Zarchiwizowane wydarzenie blokuje rozliczenie bez zmiany pozostałych
+	events := load()
+	events[1].ArchivedAt = time.Now()
+	if _, err := expenseService.SettleGroup(group, events, time.Now()); !errors.Is(err, service.ErrEventArchived) {
+		t.Fatalf("Expected ErrEventArchived, got %v", err)
+	}
+	if !events[0].SettledAt.IsZero() {
+		t.Error("Expected no event to be settled when one is archived")
+	}
+
+	settled, _ = expenseService.SettleGroup(group, load(), time.Now())
+	if err := expenseService.SaveSettledEvents(store, settled); err != nil {
+		t.Fatalf("Failed to save settled events: %v", err)
+	}
+	for _, event := range load() {
+		if event.SettledAt.IsZero() {
+			t.Errorf("Expected stored event %d to be settled", event.ID)
+		}
+	}
+}
+
+func TestCloneEventAndTemplates(t *testing.T) {
+	source := &model.Event{
+		ID:   1,
+		Name: "Narty 2024",
+		Participants: []model.Participant{
+			{ID: 1, Name: "Jan", Presence: []model.PresencePeriod{{From: model.NewDate(2024, time.February, 1)}}},
+			{ID: 2, Name: "Ola"},
+		},
+		Categories:      []model.Category{{ID: "skipass", Name: "Skipass", Aliases: []string{"Karnet"}}},
+		Households:      []model.Household{{ID: 1, Name: "Kowalscy", Members: []int{1, 2}}},
+		ShareByPresence: true,
+		StartDate:       model.NewDate(2024, time.February, 1),
+		Expenses: []model.Expense{{
+			ID:          1,
+			TotalAmount: 100,
+			Payments:    []model.Payment{{ParticipantID: 1, Amount: 100}},
+			Attachments: []model.Attachment{{ID: "a"}},
+			PeriodID:    1,
+		}, {
+			ID:          2,
+			Date:        model.NewDate(2024, time.February, 1),
+			TotalAmount: 50,
+			Payments:    []model.Payment{{ParticipantID: 2, Amount: 50}},
+			SharedWith:  []int{1, 2},
+			RecurringID: 5,
+		}},
+		Recurring: []model.RecurringExpense{{
+			ID:        5,
+			Rule:      "FREQ=MONTHLY;COUNT=2",
+			StartDate: model.NewDate(2024, time.February, 1),
+			Template: model.Expense{
+				TotalAmount: 50,
+				Payments:    []model.Payment{{ParticipantID: 2, Amount: 50}},
+				SharedWith:  []int{1, 2},
+			},
+			Exceptions:        []model.RecurrenceException{{Date: model.NewDate(2024, time.March, 1), Override: &model.Expense{TotalAmount: 70}}},
+			MaterializedUntil: model.NewDate(2024, time.February, 1),
+		}},
+		SettledAt: time.Now(),
+	}
+
+	expenseService := service.NewExpenseService()
+
+	cloneDate := time.Date(2024, time.March, 1, 18, 30, 0, 0, time.UTC)
+	clone := expenseService.CloneEvent(source, service.CloneOptions{Name: "Narty 2025"}, cloneDate)
+	if clone.Name != "Narty 2025" || len(clone.Participants) != 2 || len(clone.Expenses) != 0 {
+		t.Errorf("Expected renamed clone without expenses, got %+v", clone)
+	}
+	if clone.Participants[0].Presence != nil || !clone.StartDate.IsZero() || !clone.SettledAt.IsZero() {
+		t.Error("Expected dates, presence and settlement not to be cloned")
+	}
+	if !clone.ShareByPresence || len(clone.Categories) != 1 || len(clone.Households) != 1 {
+		t.Error("Expected settings, categories and households to be cloned")
+	}
+
+	// Kopia nie współdzieli danych ze źródłem
+	clone.Categories[0].Aliases[0] = "Zmieniony"
+	if source.Categories[0].Aliases[0] != "Karnet" {
+		t.Error("Expected clone not to share category aliases with source")
+	}
+
+	// Wydatki cykliczne kopiowane są pod nowymi identyfikatorami; bez wydatków kopia
+	// dostaje tylko wystąpienia od dnia utworzenia, czyli marcowe, a nie lutowe
+	if len(clone.Recurring) != 1 || clone.Recurring[0].ID != 1 {
+		t.Fatalf("Expected recurring expense cloned with new ID, got %+v", clone.Recurring)
+	}
+	created, err := expenseService.MaterializeRecurring(clone, model.NewDate(2024, time.March, 31))
+	if err != nil || created != 1 || len(clone.Expenses) != 1 || !clone.Expenses[0].Date.Equal(model.NewDate(2024, time.March, 1).Time) {
+		t.Fatalf("Expected only the March occurrence to be materialized in clone, got %d (%v): %+v", created, err, clone.Expenses)
+	}
+	clone.Recurring[0].Exceptions[0].Override.TotalAmount = 80
+	clone.Recurring[0].Template.SharedWith[0] = 3
+	if source.Recurring[0].Exceptions[0].Override.TotalAmount != 70 || source.Recurring[0].Template.SharedWith[0] != 1 {
+		t.Error("Expected clone not to share recurring templates with source")
+	}
+
+	withExpenses := expenseService.CloneEvent(source, service.CloneOptions{IncludeExpenses: true}, cloneDate)
+	if len(withExpenses.Expenses) != 2 || withExpenses.Expenses[0].Attachments != nil || withExpenses.Expenses[0].PeriodID != 0 {
+		t.Errorf("Expected expenses cloned without server state, got %+v", withExpenses.Expenses)
+	}
+	if err := expenseService.ValidateEvent(withExpenses); err != nil {
+		t.Fatalf("Expected valid clone, got %v", err)
+	}
+
+	// Skopiowane wystąpienie wskazuje nowy szablon, więc materializacja dodaje tylko marzec
+	if withExpenses.Expenses[1].RecurringID != 1 {
+		t.Errorf("Expected copied occurrence to reference the cloned template, got %d", withExpenses.Expenses[1].RecurringID)
+	}
+	if !withExpenses.Recurring[0].MaterializedUntil.IsZero() {
+		t.Errorf("Expected materialization to restart when expenses are cloned, got %v", withExpenses.Recurring[0].MaterializedUntil)
+	}
+	created, err = expenseService.MaterializeRecurring(withExpenses, model.NewDate(2024, time.March, 31))
+	if err != nil || created != 1 || withExpenses.Expenses[2].TotalAmount != 70 {
+		t.Errorf("Expected only the overridden March occurrence to be created, got %d (%v)", created, err)
+	}
+
+	template := expenseService.TemplateFromEvent("Narty", source)
+	if err := expenseService.ValidateTemplate(template); err != nil {
+		t.Fatalf("Expected valid template, got %v", err)
+	}
+
+	event := &model.Event{Name: "Narty 2026", Participants: []model.Participant{{ID: 7, Name: "Ewa"}}}
+	expenseService.ApplyTemplate(template, event)
+	if event.Name != "Narty 2026" || len(event.Participants) != 1 || len(event.Categories) != 1 {
+		t.Errorf("Expected template to fill only omitted fields, got %+v", event)
+	}
+}
+
+func TestArchiveAndExpiredTrash(t *testing.T) {
+	expenseService := service.NewExpenseService()
+	now := time.Date(2024, time.March, 31, 12, 0, 0, 0, time.UTC)
+
+	event := &model.Event{ID: 1}
+	expenseService.ArchiveEvent(event, now)
+	if !errors.Is(service.EnsureEditable(event), service.ErrEventArchived) {
+		t.Error("Expected archived event to be read-only")
+	}
+	expenseService.UnarchiveEvent(event)
+	if err := service.EnsureEditable(event); err != nil {
+		t.Errorf("Expected unarchived event to be editable, got %v", err)
+	}
+
+	trash := []*model.Event{
+		{ID: 1, DeletedAt: now.Add(-31 * 24 * time.Hour)},
+		{ID: 2, DeletedAt: now.Add(-time.Hour)},
+	}
+	expired := expenseService.ExpiredTrash(trash, service.DefaultTrashRetention, now)
+	if len(expired) != 1 || expired[0].ID != 1 {
+		t.Errorf("Expected only event 1 to expire, got %+v", expired)
+	}
+}
+
+func TestExportSheets(t *testing.T) {
+	event := &model.Event{
+		Participants: []model.Participant{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}, {ID: 3, Name: "Carol"}},
+		Expenses: []model.Expense{
+			{
+				ID:          1,
+				Category:    "Food",
+				TotalAmount: 90,
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 90}},
+				SharedWith:  []int{1, 2, 3},
+			},
+			{
+				ID:          2,
+				Kind:        model.ExpenseKindRefund,
+				TotalAmount: 30,
+				Payments:    []model.Payment{{ParticipantID: 1, Amount: 30}},
+				SharedWith:  []int{1, 2, 3},
+			},
+			// Wydatek z zamkniętego okresu nie trafia do eksportu
+			{
+				ID:          3,
+				TotalAmount: 50,
+				Payments:    []model.Payment{{ParticipantID: 2, Amount: 50}},
+				SharedWith:  []int{2, 3},
+				PeriodID:    1,
+			},
+		},
+		Periods: []model.Period{{ID: 1, EndDate: model.NewDate(2024, time.June, 30)}},
+	}
+
+	sheets := service.NewExpenseService().ExportSheets(event, service.LocaleEN)
+	if len(sheets) != 3 || sheets[0].Name != "Expenses" || sheets[1].Header[0] != "Participant" {
+		t.Fatalf("Unexpected sheets: %+v", sheets)
+	}
+
+	// Jeden wiersz na uczestnika każdego wydatku
+	expenses := sheets[0].Rows
+	if len(expenses) != 6 {
+		t.Fatalf("Expected 6 expense rows, got %d", len(expenses))
+	}
+	if expenses[0][3] != "Jedzenie" || expenses[0][6] != 90.0 || expenses[0][7] != 30.0 {
+		t.Errorf("Unexpected first expense row: %v", expenses[0])
+	}
+	if expenses[3][2] != model.ExpenseKindRefund || expenses[3][6] != -30.0 || expenses[3][7] != -10.0 {
+		t.Errorf("Expected negative refund row, got %v", expenses[3])
+	}
+
+	if len(sheets[2].Rows) != 2 {
+		t.Errorf("Expected 2 settlements, got %v", sheets[2].Rows)
+	}
+}
+
+func TestSuggestFromStatement(t *testing.T) {
+	expenseService := service.NewExpenseService()
+	event := &model.Event{
+		Participants: []model.Participant{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}, {ID: 3, Name: "Carol"}},
+		Expenses: []model.Expense{{
+			ID:          1,
+			TotalAmount: 90,
+			Payments:    []model.Payment{{ParticipantID: 1, Amount: 90}},
+			SharedWith:  []int{1, 2, 3},
+		}},
+	}
+
+	transactions := []model.BankTransaction{
+		{Date: model.NewDate(2024, time.July, 2), Amount: -45.5, Counterparty: "Pizzeria Roma", Reference: "TX1"},
+		{Date: model.NewDate(2024, time.July, 3), Amount: 30, Counterparty: "BOB KOWALSKI", Reference: "TX2"},
+		{Date: model.NewDate(2024, time.July, 4), Amount: 12, Counterparty: "Dave", Reference: "TX3"},
+	}
+
+	suggestions, err := expenseService.SuggestFromStatement(event, 1, transactions)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(suggestions.Expenses) != 1 || suggestions.Expenses[0].TotalAmount != 45.5 ||
+		suggestions.Expenses[0].Payments[0].ParticipantID != 1 || len(suggestions.Expenses[0].SharedWith) != 3 {
+		t.Errorf("Unexpected expense drafts: %+v", suggestions.Expenses)
+	}
+	if len(suggestions.Matches) != 1 || suggestions.Matches[0].Settlement.From != 2 {
+		t.Fatalf("Expected Bob's transfer to match his settlement, got %+v", suggestions.Matches)
+	}
+	if len(suggestions.Unmatched) != 1 || suggestions.Unmatched[0].Reference != "TX3" {
+		t.Errorf("Expected TX3 to stay unmatched, got %+v", suggestions.Unmatched)
+	}
+
+	expenseService.ApplyRepayments(event, suggestions.Matches)
+	for _, balance := range expenseService.CalculateBalances(event) {
+		if balance.ID == 2 && balance.Balance != 0 {
+			t.Errorf("Expected Bob to be settled after repayment, got %.2f", balance.Balance)
+		}
+	}
+
+	// Ponowny import tego samego wyciągu nie dubluje spłat
+	again, _ := expenseService.SuggestFromStatement(event, 1, transactions)
+	if len(again.Matches) != 0 || len(again.Unmatched) != 1 {
+		t.Errorf("Expected already imported transfer to be skipped, got %+v", again)
+	}
+
+	if _, err := expenseService.SuggestFromStatement(event, 9, transactions); !errors.Is(err, service.ErrParticipantNotFound) {
+		t.Errorf("Expected ErrParticipantNotFound, got %v", err)
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	event := &model.Event{
+		Name:         "Mazury",
+		Currency:     "PLN",
+		StartDate:    model.NewDate(2024, time.July, 1),
+		EndDate:      model.NewDate(2024, time.July, 7),
+		Participants: []model.Participant{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}},
+		Expenses: []model.Expense{{
+			ID:          1,
+			Category:    "Food",
+			Description: "Obiad",
+			Date:        model.NewDate(2024, time.July, 2),
+			TotalAmount: 60,
+			Payments:    []model.Payment{{ParticipantID: 1, Amount: 60}},
+			SharedWith:  []int{1, 2},
+		}, {
+			// Wydatek z zamkniętego okresu nie jest częścią raportu, tak jak w podsumowaniu
+			ID:          2,
+			Description: "Zaliczka",
+			Date:        model.NewDate(2024, time.June, 20),
+			TotalAmount: 200,
+			Payments:    []model.Payment{{ParticipantID: 2, Amount: 200}},
+			SharedWith:  []int{1, 2},
+			PeriodID:    1,
+		}},
+		Periods: []model.Period{{ID: 1, EndDate: model.NewDate(2024, time.June, 30)}},
+	}
+
+	now := time.Date(2024, time.July, 8, 12, 0, 0, 0, time.UTC)
+	report := service.NewExpenseService().BuildReport(event, service.LocalePL, now)
+	if report.Title != "Rozliczenie: Mazury" || report.Footer != "Wygenerowano 08.07.2024" {
+		t.Errorf("Unexpected title or footer: %q, %q", report.Title, report.Footer)
+	}
+	if report.Details[0].Value != "01.07.2024 – 07.07.2024" {
+		t.Errorf("Unexpected dates: %+v", report.Details[0])
+	}
+	if len(report.Expenses.Rows) != 1 || report.Details[3].Value != "1" {
+		t.Fatalf("Expected only the current period expense, got %v (%+v)", report.Expenses.Rows, report.Details)
+	}
+	if row := report.Expenses.Rows[0]; row[0] != "02.07.2024" || row[2] != "Jedzenie" || row[3] != "Alice" || row[4] != "60,00 PLN" {
+		t.Errorf("Unexpected expense row: %v", row)
+	}
+	if len(report.Settlements.Items) != 1 || report.Settlements.Items[0] != "Bob przekazuje Alice: 30,00 PLN" {
+		t.Errorf("Unexpected settlements: %v", report.Settlements.Items)
+	}
+
+	english := service.NewExpenseService().BuildReport(event, service.LocaleEN, now)
+	if english.Settlements.Items[0] != "Bob pays Alice 30.00 PLN" || english.Categories.Bars[0].Value != "60.00 PLN (100.00%)" {
+		t.Errorf("Unexpected English report: %v, %+v", english.Settlements.Items, english.Categories.Bars)
+	}
+}
+
+func TestValidateIBAN(t *testing.T) {
+	for _, iban := range []string{"PL61 1090 1014 0000 0712 1981 2874", "de89370400440532013000", "BE71096123456769"} {
+		if err := service.ValidateIBAN(iban); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", iban, err)
+		}
+	}
+	for _, iban := range []string{"PL61109010140000071219812875", "DE8937040044053201300", "12345", ""} {
+		if err := service.ValidateIBAN(iban); !errors.Is(err, service.ErrInvalidIBAN) {
+			t.Errorf("Expected %q to be invalid, got %v", iban, err)
+		}
+	}
+	if err := service.ValidateBIC("BPOTBE1"); err == nil {
+		t.Error("Expected 7-character BIC to be invalid")
+	}
+}
+
+func TestSettlementPayment(t *testing.T) {
+	expenseService := service.NewExpenseService()
+	event := &model.Event{
+		Name:     "Mazury",
+		Currency: "EUR",
+		Participants: []model.Participant{
+			{ID: 1, Name: "Alice", IBAN: "be71 0961 2345 6769", BIC: "gkccbebb", AccountHolder: "Alice Smith"},
+			{ID: 2, Name: "Bob"},
+		},
+		Expenses: []model.Expense{{
+			ID:          1,
+			TotalAmount: 60,
+			Payments:    []model.Payment{{ParticipantID: 1, Amount: 60}},
+			SharedWith:  []int{1, 2},
+		}},
+	}
+	if err := expenseService.ValidateEvent(event); err != nil {
+		t.Fatalf("Unexpected validation error: %v", err)
+	}
+
+	payment, err := expenseService.SettlementPayment(event, 2, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "BCD\n002\n1\nSCT\nGKCCBEBB\nAlice Smith\nBE71096123456769\nEUR30.00\n\n\nMazury: Bob"
+	if payment.EPC != expected {
+		t.Errorf("Unexpected EPC payload:\n%s", payment.EPC)
+	}
+
+	if _, err := expenseService.SettlementPayment(event, 1, 2); !errors.Is(err, service.ErrSettlementNotFound) {
+		t.Errorf("Expected ErrSettlementNotFound, got %v", err)
+	}
+
+	for _, currency := range []string{"PLN", ""} {
+		event.Currency = currency
+		payment, _ = expenseService.SettlementPayment(event, 2, 1)
+		if !errors.Is(service.EnsureEPC(payment), service.ErrUnsupportedCurrency) {
+			t.Errorf("Expected transfer in currency %q to have no EPC code, got %q", currency, payment.EPC)
+		}
+	}
+
+	event.Participants[0].IBAN = ""
+	if _, err := expenseService.SettlementPayment(event, 2, 1); !errors.Is(err, service.ErrMissingPayoutDetails) {
+		t.Errorf("Expected ErrMissingPayoutDetails, got %v", err)
+	}
+
+	event.Participants[1].IBAN = "PL00109010140000071219812874"
+	if err := expenseService.ValidateEvent(event); !errors.Is(err, service.ErrInvalidIBAN) {
+		t.Errorf("Expected invalid IBAN to fail validation, got %v", err)
+	}
+}
+
+func TestExpenseCommandsAndSummaryDelta(t *testing.T) {
+	event := &model.Event{
+		Participants: []model.Participant{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}, {ID: 3, Name: "Charlie"}},
+		Expenses: []model.Expense{
+			{ID: 4, Category: "Food", TotalAmount: 90, Payments: []model.Payment{{ParticipantID: 1, Amount: 90}}, SharedWith: []int{1, 2, 3}},
+		},
+	}
+	expenseService := service.NewExpenseService()
+	before := expenseService.CalculateSummary(event)
+
+	added := expenseService.AddExpense(event, model.Expense{
+		Category: "Fuel", TotalAmount: 60, Payments: []model.Payment{{ParticipantID: 2, Amount: 60}}, SharedWith: []int{1, 2},
+	})
+	if added.ID != 5 || len(event.Expenses) != 2 {
+		t.Fatalf("Expected expense with ID 5 to be added, got ID %d", added.ID)
+	}
+
+	delta := expenseService.DiffSummaries(before, expenseService.CalculateSummary(event))
+	if delta.TotalAmount != 150 || delta.TotalChange != 60 {
+		t.Errorf("Expected total 150 (+60), got %.2f (%+.2f)", delta.TotalAmount, delta.TotalChange)
+	}
+	// Charlie nie dzieli nowego wydatku, więc jego bilans się nie zmienia
+	if len(delta.Balances) != 2 || delta.Balances[0].ID != 1 || delta.Balances[0].Change != -30 || delta.Balances[1].Change != 30 {
+		t.Errorf("Unexpected balance changes %+v", delta.Balances)
+	}
+	if !delta.SettlementsChanged {
+		t.Error("Expected settlements to change")
+	}
+
+	if _, err := expenseService.SetPayments(event, 4, []model.Payment{{ParticipantID: 3, Amount: 90}}); err != nil {
+		t.Fatalf("Failed to set payments: %v", err)
+	}
+	if event.Expenses[0].Payments[0].ParticipantID != 3 {
+		t.Errorf("Expected payments to be replaced, got %+v", event.Expenses[0].Payments)
+	}
+	if _, err := expenseService.ReplaceExpense(event, model.Expense{ID: 9}); !errors.Is(err, service.ErrExpenseNotFound) {
+		t.Errorf("Expected ErrExpenseNotFound, got %v", err)
+	}
+	if err := expenseService.RemoveExpense(event, 4); err != nil || len(event.Expenses) != 1 || event.Expenses[0].ID != 5 {
+		t.Errorf("Expected expense 4 to be removed, got %v", err)
+	}
+
+	unchanged := expenseService.CalculateSummary(event)
+	if delta := expenseService.DiffSummaries(unchanged, unchanged); len(delta.Balances) != 0 || delta.SettlementsChanged {
+		t.Errorf("Expected empty delta for identical summaries, got %+v", delta)
+	}
+}
+
+func TestWebhookValidationAndExpenseChanges(t *testing.T) {
+	expenseService := service.NewExpenseService()
+
+	valid := &model.WebhookSubscription{URL: "https://bot.example.com/hook", Types: []string{model.ChangeEventSettled, "expense.*"}}
+	if err := expenseService.ValidateWebhook(valid); err != nil {
+		t.Errorf("Expected subscription to be valid, got %v", err)
+	}
+	for _, invalid := range []*model.WebhookSubscription{
+		{URL: "ftp://example.com"},
+		{URL: "/relative"},
+		{URL: "https://example.com", Types: []string{"expense.paid"}},
+		{URL: "https://example.com", Types: []string{"invoice.*"}},
+	} {
+		if err := expenseService.ValidateWebhook(invalid); err == nil {
+			t.Errorf("Expected subscription %+v to be invalid", invalid)
+		}
+	}
+
+	previous := &model.Event{ID: 1, Expenses: []model.Expense{
+		{ID: 1, Category: "Food", TotalAmount: 10, Payments: []model.Payment{{ParticipantID: 1, Amount: 10}}},
+		{ID: 2, Category: "Fuel", TotalAmount: 20},
+		{ID: 3, Category: "Taxi", TotalAmount: 30},
+	}}
+	event := &model.Event{ID: 1, Expenses: []model.Expense{
+		{ID: 1, Category: "Food", TotalAmount: 10, Payments: []model.Payment{{ParticipantID: 1, Amount: 10}}, Items: []model.ExpenseItem{}, UpdatedAt: time.Now()},
+		{ID: 2, Category: "Fuel", TotalAmount: 25},
+		{ID: 4, Category: "Museum", TotalAmount: 40},
+	}}
+
+	changes := expenseService.ExpenseChanges(previous, event)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes)
+	}
+	if changes[0].Type != model.ChangeExpenseUpdated || changes[0].ExpenseID != 2 || changes[0].Expense.TotalAmount != 25 {
+		t.Errorf("Expected expense 2 to be updated, got %+v", changes[0])
+	}
+	if changes[1].Type != model.ChangeExpenseCreated || changes[1].ExpenseID != 4 {
+		t.Errorf("Expected expense 4 to be created, got %+v", changes[1])
+	}
+	if changes[2].Type != model.ChangeExpenseDeleted || changes[2].ExpenseID != 3 || changes[2].Expense != nil {
+		t.Errorf("Expected expense 3 to be deleted, got %+v", changes[2])
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	expenseService := service.NewExpenseService()
+
+	event := &model.Event{
+		ID:           1,
+		Name:         "Trip",
+		Currency:     "PLN",
+		ReminderDays: 7,
+		CreatedAt:    time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Participants: []model.Participant{
+			{ID: 1, Name: "Anna", Email: "anna@example.com", Locale: "en", IBAN: "PL61 1090 1014 0000 0712 1981 2874"},
+			{ID: 2, Name: "Bartek", Email: "bartek@example.com"},
+			{ID: 3, Name: "Celina"},
+		},
+		Expenses: []model.Expense{
+			{ID: 1, Category: "Food", Description: "Dinner", TotalAmount: 90, SharedWith: []int{1, 2, 3},
+				Payments: []model.Payment{{ParticipantID: 1, Amount: 90}}},
+		},
+	}
+
+	messages := expenseService.ExpenseNotifications(event, event.Expenses[0], "Anna", 1, service.LocalePL)
+	if len(messages) != 1 || messages[0].To != "bartek@example.com" {
+		t.Fatalf("Expected a single notification for Bartek, got %+v", messages)
+	}
+	if messages[0].Subject != "Nowy wydatek w „Trip”: Dinner" ||
+		!strings.Contains(messages[0].Body, "Anna dodał(a) wydatek") ||
+		!strings.Contains(messages[0].Body, "Twój udział: 30,00 PLN") ||
+		!strings.Contains(messages[0].Body, "Twój bilans w wydarzeniu: -30,00 PLN") {
+		t.Errorf("Unexpected expense notification: %+v", messages[0])
+	}
+
+	messages = expenseService.SettlementNotifications(event, service.LocalePL)
+	if len(messages) != 2 {
+		t.Fatalf("Expected settlement summaries for Anna and Bartek, got %+v", messages)
+	}
+	if messages[0].Subject != "Settlement summary: Trip" || !strings.Contains(messages[0].Body, "Bartek pays you 30.00 PLN") {
+		t.Errorf("Unexpected English settlement summary: %+v", messages[0])
+	}
+	if !strings.Contains(messages[1].Body, "przekaż 30,00 PLN do Anna (rachunek PL61109010140000071219812874)") {
+		t.Errorf("Unexpected Polish settlement summary: %+v", messages[1])
+	}
+
+	messages = expenseService.ReminderNotifications(event, service.LocaleEN)
+	if len(messages) != 1 || messages[0].To != "bartek@example.com" || !strings.Contains(messages[0].Body, "pay 30.00 PLN to Anna") {
+		t.Fatalf("Expected a reminder in the default locale for Bartek only, got %+v", messages)
+	}
+
+	if expenseService.ReminderDue(event, time.Time{}, event.CreatedAt.Add(6*24*time.Hour)) {
+		t.Error("Expected no reminder before ReminderDays have passed")
+	}
+	if !expenseService.ReminderDue(event, time.Time{}, event.CreatedAt.Add(7*24*time.Hour)) {
+		t.Error("Expected the first reminder after ReminderDays")
+	}
+	lastSent := event.CreatedAt.Add(7 * 24 * time.Hour)
+	if expenseService.ReminderDue(event, lastSent, lastSent.Add(24*time.Hour)) {
+		t.Error("Expected no reminder one day after the previous one")
+	}
+	event.ArchivedAt = lastSent
+	if expenseService.ReminderDue(event, time.Time{}, lastSent.Add(30*24*time.Hour)) {
+		t.Error("Expected no reminders for archived events")
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Identyfikatory arkuszy eksportu
+const (
+	SheetExpenses    = "expenses"
+	SheetBalances    = "balances"
+	SheetSettlements = "settlements"
+)
+
+// ExportSheet to arkusz eksportu wydarzenia. Komórki są typu string, int, float64 (kwoty)
+// lub model.Date, dzięki czemu formaty docelowe mogą zapisać je jako liczby i daty.
+type ExportSheet struct {
+	ID     string
+	Name   string
+	Header []string
+	Rows   [][]any
+}
+
+// exportLabels zawiera nazwy arkuszy i kolumn w obsługiwanych językach
+var exportLabels = map[Locale]map[string]string{
+	LocalePL: {
+		SheetExpenses:    "Wydatki",
+		SheetBalances:    "Bilanse",
+		SheetSettlements: "Rozliczenia",
+		"expenseId":      "ID wydatku",
+		"date":           "Data",
+		"kind":           "Rodzaj",
+		"category":       "Kategoria",
+		"description":    "Opis",
+		"participant":    "Uczestnik",
+		"paid":           "Zapłacił",
+		"share":          "Udział",
+		"opening":        "Bilans otwarcia",
+		"shouldPay":      "Powinien zapłacić",
+		"balance":        "Bilans",
+		"from":           "Od",
+		"to":             "Do",
+		"amount":         "Kwota",
+	},
+	LocaleEN: {
+		SheetExpenses:    "Expenses",
+		SheetBalances:    "Balances",
+		SheetSettlements: "Settlements",
+		"expenseId":      "Expense ID",
+		"date":           "Date",
+		"kind":           "Kind",
+		"category":       "Category",
+		"description":    "Description",
+		"participant":    "Participant",
+		"paid":           "Paid",
+		"share":          "Share",
+		"opening":        "Opening balance",
+		"shouldPay":      "Should pay",
+		"balance":        "Balance",
+		"from":           "From",
+		"to":             "To",
+		"amount":         "Amount",
+	},
+}
+
+// ExportSheets przygotowuje arkusze eksportu wydarzenia: wydatki (wiersz na każdego
+// płacącego lub obciążonego uczestnika), bilanse i rozliczenia z CalculateSummary.
+// Arkusz wydatków, tak jak podsumowanie, obejmuje tylko bieżący okres.
+func (s *ExpenseService) ExportSheets(event *model.Event, locale Locale) []ExportSheet {
+	labels := exportLabels[locale]
+	if labels == nil {
+		labels = exportLabels[LocalePL]
+	}
+	columns := func(keys ...string) []string {
+		header := make([]string, len(keys))
+		for i, key := range keys {
+			header[i] = labels[key]
+		}
+		return header
+	}
+
+	summary := s.CalculateSummary(event)
+
+	expenses := ExportSheet{
+		ID:     SheetExpenses,
+		Name:   labels[SheetExpenses],
+		Header: columns("expenseId", "date", "kind", "category", "description", "participant", "paid", "share"),
+		Rows:   s.expenseRows(s.CurrentPeriod(event)),
+	}
+
+	balances := ExportSheet{
+		ID:     SheetBalances,
+		Name:   labels[SheetBalances],
+		Header: columns("participant", "opening", "paid", "shouldPay", "balance"),
+		Rows:   [][]any{},
+	}
+	for _, b := range summary.PaidByPerson {
+		balances.Rows = append(balances.Rows, []any{b.Name, b.Opening, b.Paid, b.ShouldPay, b.Balance})
+	}
+
+	settlements := ExportSheet{
+		ID:     SheetSettlements,
+		Name:   labels[SheetSettlements],
+		Header: columns("from", "to", "amount"),
+		Rows:   [][]any{},
+	}
+	for _, settlement := range summary.Settlements {
+		settlements.Rows = append(settlements.Rows, []any{settlement.FromName, settlement.ToName, settlement.Amount})
+	}
+
+	return []ExportSheet{expenses, balances, settlements}
+}
+
+// expenseRows buduje wiersze wydatków: po jednym dla każdego uczestnika, który zapłacił
+// lub jest obciążony wydatkiem. Kwoty zwrotów są ujemne, tak jak w bilansach.
+func (s *ExpenseService) expenseRows(event *model.Event) [][]any {
+	names := make(map[int]string, len(event.Participants))
+	for _, p := range event.Participants {
+		names[p.ID] = p.Name
+	}
+
+	rows := [][]any{}
+	for _, exp := range event.Expenses {
+		sign := s.ExpenseSign(exp)
+		paid := make(map[int]float64)
+		for _, payment := range exp.Payments {
+			paid[payment.ParticipantID] = s.RoundToTwo(paid[payment.ParticipantID] + sign*payment.Amount)
+		}
+		shares := s.ExpenseShares(event, exp)
+
+		var ids []int
+		for id := range paid {
+			ids = append(ids, id)
+		}
+		for id := range shares {
+			if _, exists := paid[id]; !exists {
+				ids = append(ids, id)
+			}
+		}
+		sort.Ints(ids)
+
+		kind := exp.Kind
+		if kind == "" {
+			kind = model.ExpenseKindExpense
+		}
+		category := s.NormalizeCategory(event, exp.Category).Name
+
+		for _, id := range ids {
+			name, ok := names[id]
+			if !ok {
+				name = fmt.Sprintf("#%d", id)
+			}
+			rows = append(rows, []any{exp.ID, exp.Date, kind, category, exp.Description, name, paid[id], shares[id]})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// ErrNothingToSettle zwracany jest gdy grupa nie ma otwartych wydarzeń do rozliczenia
+var ErrNothingToSettle = errors.New("group has no open events to settle")
+
+// GroupMemberKey zwraca klucz osoby członka grupy zgodny z PersonKey uczestników wydarzeń
+func GroupMemberKey(member model.GroupMember) string {
+	if member.UserID != "" {
+		return "user:" + member.UserID
+	}
+	return "email:" + strings.ToLower(strings.TrimSpace(member.Email))
+}
+
+// ValidateGroup sprawdza poprawność danych grupy
+func (s *ExpenseService) ValidateGroup(group *model.Group) error {
+	if strings.TrimSpace(group.Name) == "" {
+		return errors.New("group name is required")
+	}
+
+	members := make(map[string]bool, len(group.Members))
+	for _, member := range group.Members {
+		if strings.TrimSpace(member.Name) == "" {
+			return errors.New("member name is required")
+		}
+		if member.UserID == "" && strings.TrimSpace(member.Email) == "" {
+			return fmt.Errorf("member %q: email or user ID is required", member.Name)
+		}
+		key := GroupMemberKey(member)
+		if members[key] {
+			return fmt.Errorf("duplicate member %q", member.Name)
+		}
+		members[key] = true
+	}
+
+	events := make(map[int]bool, len(group.EventIDs))
+	for _, id := range group.EventIDs {
+		if events[id] {
+			return fmt.Errorf("duplicate event ID %d", id)
+		}
+		events[id] = true
+	}
+
+	return nil
+}
+
+// OpenGroupEvents zwraca wydarzenia grupy, które nie zostały jeszcze rozliczone
+func OpenGroupEvents(group *model.Group, events []*model.Event) []*model.Event {
+	included := make(map[int]bool, len(group.EventIDs))
+	for _, id := range group.EventIDs {
+		included[id] = true
+	}
+
+	var open []*model.Event
+	for _, event := range events {
+		if included[event.ID] && event.SettledAt.IsZero() {
+			open = append(open, event)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].ID < open[j].ID })
+	return open
+}
+
+// CalculateGroupSummary łączy bilanse otwartych wydarzeń grupy w jeden plan rozliczeń.
+// Uczestnicy są utożsamiani między wydarzeniami tak jak w bilansach osób (konto lub e-mail).
+func (s *ExpenseService) CalculateGroupSummary(group *model.Group, events []*model.Event) *model.GroupSummary {
+	open := OpenGroupEvents(group, events)
+	net := s.netPersonBalances(open, nil)
+
+	// Członkowie grupy występują pod nazwą nadaną w grupie
+	for _, member := range group.Members {
+		if _, exists := net.names[GroupMemberKey(member)]; exists {
+			net.names[GroupMemberKey(member)] = member.Name
+		}
+	}
+
+	summary := &model.GroupSummary{
+		GroupID:  group.ID,
+		EventIDs: []int{},
+		Balances: make([]model.GroupMemberBalance, 0, len(net.keys)),
+	}
+	for _, event := range open {
+		summary.EventIDs = append(summary.EventIDs, event.ID)
+	}
+	for _, key := range net.keys {
+		summary.Balances = append(summary.Balances, model.GroupMemberBalance{
+			Person:  key,
+			Name:    net.names[key],
+			Balance: net.amounts[key],
+		})
+	}
+	summary.Settlements = s.personSettlements(net)
+
+	return summary
+}
+
+// SettleGroup oznacza wszystkie otwarte wydarzenia grupy jako rozliczone i zwraca je.
+// Jeśli którekolwiek z nich jest zarchiwizowane, żadne wydarzenie nie zostaje zmienione.
+func (s *ExpenseService) SettleGroup(group *model.Group, events []*model.Event, now time.Time) ([]*model.Event, error) {
+	open := OpenGroupEvents(group, events)
+	if len(open) == 0 {
+		return nil, ErrNothingToSettle
+	}
+
+	for _, event := range open {
+		if err := EnsureEditable(event); err != nil {
+			return nil, fmt.Errorf("event %d: %w", event.ID, err)
+		}
+	}
+
+	for _, event := range open {
+		event.SettledAt = now
+	}
+	return open, nil
+}
+
+// SaveSettledEvents zapisuje wydarzenia rozliczone przez SettleGroup jako całość. Przed
+// zapisem sprawdza, że każde wydarzenie nadal istnieje, nie zostało zarchiwizowane ani
+// zmienione w międzyczasie. Jeśli zapis któregoś z nich się nie powiedzie, wcześniej
+// zapisane wydarzenia są przywracane do stanu sprzed rozliczenia.
+func (s *ExpenseService) SaveSettledEvents(repo repository.EventRepository, events []*model.Event) error {
+	for _, event := range events {
+		current, err := repo.FindByID(event.ID)
+		if err != nil {
+			return fmt.Errorf("event %d: %w", event.ID, repository.ErrVersionConflict)
+		}
+		if current.Version != event.Version {
+			return fmt.Errorf("event %d: %w", event.ID, repository.ErrVersionConflict)
+		}
+		if err := EnsureEditable(current); err != nil {
+			return fmt.Errorf("event %d: %w", event.ID, err)
+		}
+	}
+
+	for i, event := range events {
+		err := repo.Save(event)
+		if err == nil {
+			continue
+		}
+
+		// Zapisane wydarzenia mają już nowe wersje, więc można je nadpisać stanem otwartym
+		errs := []error{fmt.Errorf("event %d: %w", event.ID, err)}
+		for _, saved := range events[:i] {
+			saved.SettledAt = time.Time{}
+			if rollbackErr := repo.Save(saved); rollbackErr != nil {
+				errs = append(errs, fmt.Errorf("rollback event %d: %w", saved.ID, rollbackErr))
+			}
+		}
+		for _, pending := range events[i:] {
+			pending.SettledAt = time.Time{}
+		}
+		return errors.Join(errs...)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// CalculateHouseholdBalances sumuje bilanse członków każdego gospodarstwa domowego
+func (s *ExpenseService) CalculateHouseholdBalances(event *model.Event, balances []model.ParticipantBalance) []model.HouseholdBalance {
+	if len(event.Households) == 0 {
+		return nil
+	}
+
+	byParticipant := make(map[int]model.ParticipantBalance, len(balances))
+	for _, b := range balances {
+		byParticipant[b.ID] = b
+	}
+
+	result := make([]model.HouseholdBalance, len(event.Households))
+	for i, household := range event.Households {
+		hb := model.HouseholdBalance{
+			ID:      household.ID,
+			Name:    household.Name,
+			Members: append([]int(nil), household.Members...),
+		}
+		for _, id := range household.Members {
+			b := byParticipant[id]
+			hb.Paid = s.RoundToTwo(hb.Paid + b.Paid)
+			hb.ShouldPay = s.RoundToTwo(hb.ShouldPay + b.ShouldPay)
+			hb.Balance = s.RoundToTwo(hb.Balance + b.Balance)
+		}
+		result[i] = hb
+	}
+
+	return result
+}
+
+// CalculateHouseholdSettlements oblicza rozliczenia, w których każde gospodarstwo domowe
+// występuje jako jedna strona (jeden przelew na rodzinę). Przelewy wykonuje i otrzymuje
+// skarbnik gospodarstwa, a uczestnicy spoza gospodarstw rozliczają się indywidualnie.
+func (s *ExpenseService) CalculateHouseholdSettlements(event *model.Event, balances []model.ParticipantBalance) []model.Settlement {
+	if len(event.Households) == 0 {
+		return nil
+	}
+
+	// Strona rozliczenia: gospodarstwo lub pojedynczy uczestnik
+	type unit struct {
+		participantID int
+		name          string
+		householdID   int
+	}
+
+	var units []unit
+	var unitBalances []model.ParticipantBalance
+	inHousehold := make(map[int]bool)
+
+	householdBalances := s.CalculateHouseholdBalances(event, balances)
+	for i, household := range event.Households {
+		hb := householdBalances[i]
+		units = append(units, unit{
+			participantID: treasurerOf(household),
+			name:          hb.Name,
+			householdID:   hb.ID,
+		})
+		unitBalances = append(unitBalances, model.ParticipantBalance{ID: len(units), Name: hb.Name, Balance: hb.Balance})
+		for _, id := range household.Members {
+			inHousehold[id] = true
+		}
+	}
+
+	for _, b := range balances {
+		if inHousehold[b.ID] {
+			continue
+		}
+		units = append(units, unit{participantID: b.ID, name: b.Name})
+		unitBalances = append(unitBalances, model.ParticipantBalance{ID: len(units), Name: b.Name, Balance: b.Balance})
+	}
+
+	// Rozliczenia wyznaczane są na numerach stron, a następnie tłumaczone na uczestników
+	settlements := s.CalculateSettlements(unitBalances)
+	for i, settlement := range settlements {
+		from, to := units[settlement.From-1], units[settlement.To-1]
+		settlements[i] = model.Settlement{
+			From:          from.participantID,
+			FromName:      from.name,
+			To:            to.participantID,
+			ToName:        to.name,
+			Amount:        settlement.Amount,
+			FromHousehold: from.householdID,
+			ToHousehold:   to.householdID,
+		}
+	}
+
+	return settlements
+}
+
+// validateHouseholds sprawdza poprawność gospodarstw domowych wydarzenia
+func (s *ExpenseService) validateHouseholds(event *model.Event) error {
+	participants := make(map[int]bool, len(event.Participants))
+	for _, p := range event.Participants {
+		participants[p.ID] = true
+	}
+
+	ids := make(map[int]bool, len(event.Households))
+	memberOf := make(map[int]int)
+	for _, household := range event.Households {
+		if ids[household.ID] {
+			return fmt.Errorf("duplicate household ID %d", household.ID)
+		}
+		ids[household.ID] = true
+
+		if household.Name == "" {
+			return fmt.Errorf("household %d: name is required", household.ID)
+		}
+		if len(household.Members) == 0 {
+			return fmt.Errorf("household %d: at least one member is required", household.ID)
+		}
+
+		for _, id := range household.Members {
+			if !participants[id] {
+				return fmt.Errorf("household %d: unknown participant %d", household.ID, id)
+			}
+			if other, ok := memberOf[id]; ok {
+				return fmt.Errorf("participant %d belongs to households %d and %d", id, other, household.ID)
+			}
+			memberOf[id] = household.ID
+		}
+
+		if household.TreasurerID != 0 && memberOf[household.TreasurerID] != household.ID {
+			return errors.New("household treasurer must be one of its members")
+		}
+	}
+	return nil
+}
+
+// treasurerOf zwraca uczestnika wykonującego przelewy w imieniu gospodarstwa
+func treasurerOf(household model.Household) int {
+	if household.TreasurerID != 0 {
+		return household.TreasurerID
+	}
+	return household.Members[0]
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// ItemTotal zwraca wartość pozycji rachunku (cena razy ilość, domyślnie 1 sztuka)
+func (s *ExpenseService) ItemTotal(item model.ExpenseItem) float64 {
+	quantity := item.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	return s.RoundToTwo(item.Amount * quantity)
+}
+
+// ItemsTotal zwraca sumę pozycji rachunku oraz kwotę po doliczeniu podatku,
+// opłaty serwisowej i napiwku oraz odjęciu rabatu
+func (s *ExpenseService) ItemsTotal(exp model.Expense) (float64, float64) {
+	subtotal := 0.0
+	for _, item := range exp.Items {
+		subtotal = s.RoundToTwo(subtotal + s.ItemTotal(item))
+	}
+	return subtotal, s.RoundToTwo(subtotal + s.itemsAdjustment(exp))
+}
+
+// itemsAdjustment zwraca łączną kwotę dopłat i rabatów rozkładaną na pozycje
+func (s *ExpenseService) itemsAdjustment(exp model.Expense) float64 {
+	return s.RoundToTwo(exp.Tax + exp.ServiceCharge + exp.Tip - exp.Discount)
+}
+
+// itemShares wyznacza udziały uczestników na podstawie pozycji rachunku.
+// Dopłaty i rabaty dzielone są proporcjonalnie do wartości pozycji, a pozycja bez
+// wskazanych uczestników dzielona jest między wszystkie osoby z sharedWith.
+func (s *ExpenseService) itemShares(exp model.Expense, sharedWith []int) map[int]float64 {
+	shares := make(map[int]float64)
+
+	subtotal, _ := s.ItemsTotal(exp)
+	if subtotal == 0 {
+		return shares
+	}
+
+	// Współczynnik rozkładający dopłaty i rabaty (oraz znak zwrotu) na pozycje
+	totalExp, _ := s.ExpenseTotal(exp)
+	ratio := totalExp / subtotal
+
+	for _, item := range exp.Items {
+		participants := item.Participants
+		if len(participants) == 0 {
+			participants = sharedWith
+		}
+		if len(participants) == 0 {
+			continue
+		}
+
+		itemCost := s.ItemTotal(item) * ratio
+		perPerson := itemCost / float64(len(participants))
+		for _, id := range participants {
+			shares[id] += perPerson
+		}
+	}
+
+	for id, share := range shares {
+		shares[id] = s.RoundToTwo(share)
+	}
+
+	return shares
+}
+
+// validateItems sprawdza poprawność pozycji rachunku
+func (s *ExpenseService) validateItems(event *model.Event, exp model.Expense) error {
+	if len(exp.Items) == 0 {
+		if exp.Tax != 0 || exp.ServiceCharge != 0 || exp.Tip != 0 || exp.Discount != 0 {
+			return errors.New("tax, service charge, tip and discount require line items")
+		}
+		return nil
+	}
+
+	if exp.Tax < 0 || exp.ServiceCharge < 0 || exp.Tip < 0 || exp.Discount < 0 {
+		return errors.New("tax, service charge, tip and discount must not be negative")
+	}
+
+	participants := make(map[int]bool, len(event.Participants))
+	for _, p := range event.Participants {
+		participants[p.ID] = true
+	}
+
+	for i, item := range exp.Items {
+		if item.Amount < 0 || item.Quantity < 0 {
+			return fmt.Errorf("item %d: amount and quantity must not be negative", i+1)
+		}
+		if len(item.Participants) == 0 && len(s.EffectiveSharedWith(event, exp)) == 0 {
+			return fmt.Errorf("item %d: participants are required when expense has no sharedWith", i+1)
+		}
+		for _, id := range item.Participants {
+			if !participants[id] {
+				return fmt.Errorf("item %d: unknown participant %d", i+1, id)
+			}
+		}
+	}
+
+	subtotal, itemsTotal := s.ItemsTotal(exp)
+	if itemsTotal < 0 {
+		return errors.New("discount must not exceed the sum of items")
+	}
+	if exp.TotalAmount != 0 && subtotal != 0 && s.RoundToTwo(exp.TotalAmount-itemsTotal) != 0 {
+		return fmt.Errorf("total amount %.2f does not match items total %.2f", exp.TotalAmount, itemsTotal)
+	}
+	return nil
+}
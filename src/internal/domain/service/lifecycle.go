@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// DefaultTrashRetention to domyślny czas przechowywania wydarzeń w koszu
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// ErrEventArchived zwracany jest przy próbie zmiany zarchiwizowanego wydarzenia
+var ErrEventArchived = errors.New("event is archived and read-only")
+
+// ErrEventSettled zwracany jest przy próbie zmiany wydarzenia rozliczonego w grupie
+var ErrEventSettled = errors.New("event is settled and read-only")
+
+// EnsureEditable sprawdza czy wydarzenie można modyfikować. Rozliczone wydarzenia są tylko
+// do odczytu, bo rozliczenia grupy i bilanse osób już ich nie uwzględniają.
+func EnsureEditable(event *model.Event) error {
+	if !event.ArchivedAt.IsZero() {
+		return ErrEventArchived
+	}
+	if !event.SettledAt.IsZero() {
+		return ErrEventSettled
+	}
+	return nil
+}
+
+// ArchiveEvent archiwizuje wydarzenie. Ponowna archiwizacja zachowuje pierwotny czas.
+func (s *ExpenseService) ArchiveEvent(event *model.Event, now time.Time) {
+	if event.ArchivedAt.IsZero() {
+		event.ArchivedAt = now.UTC()
+	}
+}
+
+// UnarchiveEvent przywraca możliwość modyfikacji wydarzenia
+func (s *ExpenseService) UnarchiveEvent(event *model.Event) {
+	event.ArchivedAt = time.Time{}
+}
+
+// ExpiredTrash zwraca wydarzenia z kosza, których czas przechowywania upłynął
+func (s *ExpenseService) ExpiredTrash(events []*model.Event, retention time.Duration, now time.Time) []*model.Event {
+	var expired []*model.Event
+	for _, event := range events {
+		if !event.DeletedAt.IsZero() && !event.DeletedAt.Add(retention).After(now) {
+			expired = append(expired, event)
+		}
+	}
+	return expired
+}
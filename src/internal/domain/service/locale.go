@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Locale to język i format liczb używany w eksportach i raportach
+type Locale string
+
+// Obsługiwane języki
+const (
+	LocalePL Locale = "pl"
+	LocaleEN Locale = "en"
+)
+
+// ParseLocale parsuje kod języka (np. "pl", "en-US"). Pusty kod oznacza język polski.
+func ParseLocale(value string) (Locale, error) {
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(value)), "-")
+	switch Locale(language) {
+	case "", LocalePL:
+		return LocalePL, nil
+	case LocaleEN:
+		return LocaleEN, nil
+	default:
+		return "", fmt.Errorf("unsupported locale %q", value)
+	}
+}
+
+// FormatDecimal formatuje kwotę z dwoma miejscami po przecinku zgodnie z językiem,
+// bez separatora tysięcy, aby arkusze kalkulacyjne rozpoznawały ją jako liczbę
+func FormatDecimal(amount float64, locale Locale) string {
+	value := strconv.FormatFloat(amount, 'f', 2, 64)
+	if locale == LocalePL {
+		value = strings.Replace(value, ".", ",", 1)
+	}
+	return value
+}
+
+// FormatDate formatuje datę zgodnie z językiem (02.01.2006 lub 2006-01-02)
+func FormatDate(date model.Date, locale Locale) string {
+	if date.IsZero() {
+		return ""
+	}
+	if locale == LocalePL {
+		return date.Format("02.01.2006")
+	}
+	return date.String()
+}
+
+// FormatMoney formatuje kwotę wraz z walutą wydarzenia (jeśli jest znana)
+func FormatMoney(amount float64, currency string, locale Locale) string {
+	if currency == "" {
+		return FormatDecimal(amount, locale)
+	}
+	return FormatDecimal(amount, locale) + " " + currency
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Rodzaje powiadomień e-mail
+const (
+	notificationExpense    = "expense"
+	notificationSettlement = "settlement"
+	notificationReminder   = "reminder"
+)
+
+// notificationTemplates zawiera szablony tematu i treści powiadomień w obsługiwanych językach
+var notificationTemplates = map[Locale]map[string][2]string{
+	LocalePL: {
+		notificationExpense: {
+			`Nowy wydatek w „{{.Event}}”: {{.Title}}`,
+			`Cześć {{.Name}},
+
+{{if .Actor}}{{.Actor}} dodał(a){{else}}Dodano{{end}} wydatek w wydarzeniu „{{.Event}}”.
+
+Wydatek: {{.Title}}
+{{if .Date}}Data: {{.Date}}
+{{end}}Kwota: {{.Amount}}
+Zapłacił(a): {{.PaidBy}}
+{{if .Paid}}Twoja płatność: {{.Paid}}
+{{end}}Twój udział: {{.Share}}
+
+Twój bilans w wydarzeniu: {{.Balance}}
+`,
+		},
+		notificationSettlement: {
+			`Podsumowanie rozliczenia: {{.Event}}`,
+			`Cześć {{.Name}},
+
+wydarzenie „{{.Event}}” zostało zamknięte. Oto podsumowanie rozliczenia.
+
+Suma wydatków: {{.Total}}
+Twoje płatności: {{.Paid}}
+Twój udział: {{.Share}}
+Twój bilans: {{.Balance}}
+{{if .Transfers}}
+Twoje rozliczenia:
+{{range .Transfers}}- {{.}}
+{{end}}{{else}}
+Jesteś rozliczony(a) – nie musisz nic robić.
+{{end}}`,
+		},
+		notificationReminder: {
+			`Przypomnienie: rozliczenie „{{.Event}}”`,
+			`Cześć {{.Name}},
+
+przypominamy o niespłaconych rozliczeniach w wydarzeniu „{{.Event}}”:
+
+{{range .Transfers}}- {{.}}
+{{end}}
+Jeśli już zapłaciłeś(-aś), poproś odbiorcę o zarejestrowanie spłaty.
+`,
+		},
+	},
+	LocaleEN: {
+		notificationExpense: {
+			`New expense in "{{.Event}}": {{.Title}}`,
+			`Hi {{.Name}},
+
+{{if .Actor}}{{.Actor}} added{{else}}There is{{end}} a new expense in "{{.Event}}".
+
+Expense: {{.Title}}
+{{if .Date}}Date: {{.Date}}
+{{end}}Amount: {{.Amount}}
+Paid by: {{.PaidBy}}
+{{if .Paid}}You paid: {{.Paid}}
+{{end}}Your share: {{.Share}}
+
+Your balance in the event: {{.Balance}}
+`,
+		},
+		notificationSettlement: {
+			`Settlement summary: {{.Event}}`,
+			`Hi {{.Name}},
+
+"{{.Event}}" has been closed. Here is the settlement summary.
+
+Total spent: {{.Total}}
+You paid: {{.Paid}}
+Your share: {{.Share}}
+Your balance: {{.Balance}}
+{{if .Transfers}}
+Your settlements:
+{{range .Transfers}}- {{.}}
+{{end}}{{else}}
+You are settled up - nothing to do.
+{{end}}`,
+		},
+		notificationReminder: {
+			`Reminder: settle up "{{.Event}}"`,
+			`Hi {{.Name}},
+
+this is a reminder about outstanding settlements in "{{.Event}}":
+
+{{range .Transfers}}- {{.}}
+{{end}}
+If you have already paid, ask the recipient to record the repayment.
+`,
+		},
+	},
+}
+
+// notificationLabels zawiera teksty wstawiane do treści powiadomień
+var notificationLabels = map[Locale]map[string]string{
+	LocalePL: {
+		"youPay":   "przekaż %s do %s",
+		"account":  " (rachunek %s)",
+		"youGet":   "%s przekazuje Tobie %s",
+		"signoff":  "\n— Splitty\n",
+		"multiple": ", ",
+	},
+	LocaleEN: {
+		"youPay":   "pay %s to %s",
+		"account":  " (account %s)",
+		"youGet":   "%s pays you %s",
+		"signoff":  "\n— Splitty\n",
+		"multiple": ", ",
+	},
+}
+
+// parsedNotificationTemplates to szablony powiadomień przetworzone przy starcie
+var parsedNotificationTemplates = func() map[Locale]map[string][2]*template.Template {
+	parsed := make(map[Locale]map[string][2]*template.Template, len(notificationTemplates))
+	for locale, kinds := range notificationTemplates {
+		parsed[locale] = make(map[string][2]*template.Template, len(kinds))
+		for kind, texts := range kinds {
+			parsed[locale][kind] = [2]*template.Template{
+				template.Must(template.New(kind + "Subject").Parse(texts[0])),
+				template.Must(template.New(kind + "Body").Parse(texts[1])),
+			}
+		}
+	}
+	return parsed
+}()
+
+// ExpenseNotifications przygotowuje powiadomienia o nowym wydatku dla uczestników, których
+// wydatek dotyczy (płacących lub dzielących koszt) i którzy mają adres e-mail. Uczestnik
+// actorID, który sam dodał wydatek, nie jest powiadamiany.
+func (s *ExpenseService) ExpenseNotifications(event *model.Event, exp model.Expense, actor string, actorID int, defaultLocale Locale) []model.EmailMessage {
+	shares := s.ExpenseShares(event, exp)
+	paid := make(map[int]float64, len(exp.Payments))
+	for _, payment := range exp.Payments {
+		paid[payment.ParticipantID] += payment.Amount
+	}
+	balances := make(map[int]float64, len(event.Participants))
+	for _, b := range s.CalculateSummary(event).PaidByPerson {
+		balances[b.ID] = b.Balance
+	}
+
+	var payers []string
+	for _, payment := range exp.Payments {
+		if p := findParticipant(event, payment.ParticipantID); p != nil {
+			payers = append(payers, p.Name)
+		}
+	}
+
+	total, _ := s.ExpenseTotal(exp)
+	var messages []model.EmailMessage
+	for _, p := range event.Participants {
+		_, shared := shares[p.ID]
+		_, paying := paid[p.ID]
+		if p.Email == "" || p.ID == actorID || (!shared && !paying) {
+			continue
+		}
+
+		locale := recipientLocale(p, defaultLocale)
+		money := func(amount float64) string { return FormatMoney(amount, event.Currency, locale) }
+		data := map[string]string{
+			"Name":    p.Name,
+			"Event":   event.Name,
+			"Actor":   actor,
+			"Title":   expenseTitle(s, event, exp),
+			"Date":    FormatDate(exp.Date, locale),
+			"Amount":  money(s.ExpenseSign(exp) * total),
+			"PaidBy":  strings.Join(payers, notificationLabels[locale]["multiple"]),
+			"Share":   money(s.ExpenseSign(exp) * shares[p.ID]),
+			"Balance": money(balances[p.ID]),
+		}
+		if paying {
+			data["Paid"] = money(paid[p.ID])
+		}
+
+		messages = append(messages, renderNotification(p, locale, notificationExpense, data))
+	}
+	return messages
+}
+
+// SettlementNotifications przygotowuje podsumowanie rozliczenia dla każdego uczestnika
+// z adresem e-mail: jego bilans oraz przelewy, które ma wykonać lub otrzymać
+func (s *ExpenseService) SettlementNotifications(event *model.Event, defaultLocale Locale) []model.EmailMessage {
+	summary := s.CalculateSummary(event)
+
+	var messages []model.EmailMessage
+	for _, p := range event.Participants {
+		if p.Email == "" {
+			continue
+		}
+
+		locale := recipientLocale(p, defaultLocale)
+		money := func(amount float64) string { return FormatMoney(amount, event.Currency, locale) }
+		data := map[string]any{
+			"Name":      p.Name,
+			"Event":     event.Name,
+			"Total":     money(summary.TotalAmount),
+			"Transfers": s.participantTransfers(event, summary.Settlements, p.ID, true, locale),
+		}
+		for _, b := range summary.PaidByPerson {
+			if b.ID == p.ID {
+				data["Paid"] = money(b.Paid)
+				data["Share"] = money(b.ShouldPay)
+				data["Balance"] = money(b.Balance)
+			}
+		}
+
+		messages = append(messages, renderNotification(p, locale, notificationSettlement, data))
+	}
+	return messages
+}
+
+// ReminderNotifications przygotowuje przypomnienia dla uczestników z adresem e-mail,
+// którzy mają niespłacone rozliczenia
+func (s *ExpenseService) ReminderNotifications(event *model.Event, defaultLocale Locale) []model.EmailMessage {
+	summary := s.CalculateSummary(event)
+
+	var messages []model.EmailMessage
+	for _, p := range event.Participants {
+		if p.Email == "" {
+			continue
+		}
+
+		locale := recipientLocale(p, defaultLocale)
+		transfers := s.participantTransfers(event, summary.Settlements, p.ID, false, locale)
+		if len(transfers) == 0 {
+			continue
+		}
+
+		data := map[string]any{"Name": p.Name, "Event": event.Name, "Transfers": transfers}
+		messages = append(messages, renderNotification(p, locale, notificationReminder, data))
+	}
+	return messages
+}
+
+// ReminderDue sprawdza czy należy wysłać przypomnienie o rozliczeniu wydarzenia. Przypomnienia
+// dotyczą tylko otwartych wydarzeń z włączonym ReminderDays; pierwsze wysyłane jest po
+// ReminderDays dniach od utworzenia wydarzenia, kolejne co ReminderDays dni.
+func (s *ExpenseService) ReminderDue(event *model.Event, lastSent, now time.Time) bool {
+	if event.ReminderDays <= 0 || !event.ArchivedAt.IsZero() || !event.SettledAt.IsZero() || !event.DeletedAt.IsZero() {
+		return false
+	}
+
+	since := lastSent
+	if since.IsZero() {
+		since = event.CreatedAt
+	}
+	return !now.Before(since.Add(time.Duration(event.ReminderDays) * 24 * time.Hour))
+}
+
+// participantTransfers opisuje przelewy uczestnika: wychodzące (z rachunkiem odbiorcy,
+// jeśli jest znany) oraz, gdy incoming jest ustawione, przychodzące
+func (s *ExpenseService) participantTransfers(event *model.Event, settlements []model.Settlement, participantID int, incoming bool, locale Locale) []string {
+	labels := notificationLabels[locale]
+	money := func(amount float64) string { return FormatMoney(amount, event.Currency, locale) }
+
+	var transfers []string
+	for _, settlement := range settlements {
+		switch {
+		case settlement.From == participantID:
+			transfer := fmt.Sprintf(labels["youPay"], money(settlement.Amount), settlement.ToName)
+			if payee := findParticipant(event, settlement.To); payee != nil && payee.IBAN != "" {
+				transfer += fmt.Sprintf(labels["account"], NormalizeIBAN(payee.IBAN))
+			}
+			transfers = append(transfers, transfer)
+		case incoming && settlement.To == participantID:
+			transfers = append(transfers, fmt.Sprintf(labels["youGet"], settlement.FromName, money(settlement.Amount)))
+		}
+	}
+	return transfers
+}
+
+// renderNotification wypełnia szablon powiadomienia danymi odbiorcy
+func renderNotification(p model.Participant, locale Locale, kind string, data any) model.EmailMessage {
+	templates := parsedNotificationTemplates[locale][kind]
+
+	var subject, body strings.Builder
+	// Szablony są stałe i sprawdzane przez testy, więc błędy wykonania nie występują
+	templates[0].Execute(&subject, data)
+	templates[1].Execute(&body, data)
+	body.WriteString(notificationLabels[locale]["signoff"])
+
+	return model.EmailMessage{To: p.Email, ToName: p.Name, Subject: subject.String(), Body: body.String()}
+}
+
+// recipientLocale zwraca język powiadomień uczestnika lub język domyślny
+func recipientLocale(p model.Participant, defaultLocale Locale) Locale {
+	if locale, err := ParseLocale(p.Locale); err == nil && p.Locale != "" {
+		return locale
+	}
+	if notificationTemplates[defaultLocale] == nil {
+		return LocalePL
+	}
+	return defaultLocale
+}
+
+// expenseTitle zwraca opis wydatku lub nazwę jego kategorii
+func expenseTitle(s *ExpenseService, event *model.Event, exp model.Expense) string {
+	if exp.Description != "" {
+		return exp.Description
+	}
+	return s.NormalizeCategory(event, exp.Category).Name
+}
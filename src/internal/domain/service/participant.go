@@ -0,0 +1,463 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Sposoby usuwania uczestnika, do którego odwołują się wydatki
+const (
+	// RemovalRefuse odmawia usunięcia, jeśli istnieją odwołania do uczestnika
+	RemovalRefuse = "refuse"
+	// RemovalReassign przenosi płatności i udziały uczestnika na innego uczestnika
+	RemovalReassign = "reassign"
+	// RemovalRedistribute rozkłada udziały uczestnika na pozostałe osoby dzielące wydatek
+	RemovalRedistribute = "redistribute"
+)
+
+// Błędy zwracane przy usuwaniu i łączeniu uczestników
+var (
+	ErrParticipantNotFound   = errors.New("participant not found")
+	ErrParticipantReferenced = errors.New("participant is referenced by expenses")
+)
+
+// ParticipantReferences zwraca opisy miejsc, w których wydarzenie odwołuje się do uczestnika
+func (s *ExpenseService) ParticipantReferences(event *model.Event, participantID int) []string {
+	var refs []string
+
+	for _, exp := range event.Expenses {
+		refs = append(refs, expenseReferences(fmt.Sprintf("expense %d", exp.ID), exp, participantID)...)
+	}
+	for _, rec := range event.Recurring {
+		refs = append(refs, expenseReferences(fmt.Sprintf("recurring expense %d", rec.ID), rec.Template, participantID)...)
+		for _, ex := range rec.Exceptions {
+			if ex.Override != nil {
+				refs = append(refs, expenseReferences(fmt.Sprintf("recurring expense %d override on %s", rec.ID, ex.Date), *ex.Override, participantID)...)
+			}
+		}
+	}
+	for _, ob := range event.OpeningBalances {
+		if ob.ParticipantID == participantID && ob.Amount != 0 {
+			refs = append(refs, "opening balance")
+		}
+	}
+	for _, r := range event.Repayments {
+		if r.From == participantID || r.To == participantID {
+			refs = append(refs, fmt.Sprintf("repayment %d", r.ID))
+		}
+	}
+
+	return refs
+}
+
+// RemoveParticipant usuwa uczestnika z wydarzenia. W zależności od trybu odmawia usunięcia
+// przy istniejących odwołaniach, przenosi je na uczestnika targetID albo rozkłada udziały
+// na pozostałe osoby (tryb redistribute wymaga, aby uczestnik nie miał płatności).
+func (s *ExpenseService) RemoveParticipant(event *model.Event, participantID int, mode string, targetID int) error {
+	if findParticipant(event, participantID) == nil {
+		return ErrParticipantNotFound
+	}
+	if err := s.checkFrozenReferences(event, participantID); err != nil {
+		return err
+	}
+
+	switch mode {
+	case "", RemovalRefuse:
+		if refs := s.ParticipantReferences(event, participantID); len(refs) > 0 {
+			return fmt.Errorf("%w: %v", ErrParticipantReferenced, refs)
+		}
+	case RemovalReassign:
+		if targetID == participantID || findParticipant(event, targetID) == nil {
+			return fmt.Errorf("%w: reassignment target %d", ErrParticipantNotFound, targetID)
+		}
+		s.reassignParticipant(event, participantID, targetID)
+	case RemovalRedistribute:
+		if err := s.redistributeParticipant(event, participantID); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown removal mode %q", mode)
+	}
+
+	removeParticipant(event, participantID)
+	return nil
+}
+
+// MergeParticipants łączy zduplikowanego uczestnika sourceID z uczestnikiem targetID:
+// przenosi wszystkie płatności i udziały, uzupełnia brakujące dane i usuwa duplikat
+func (s *ExpenseService) MergeParticipants(event *model.Event, sourceID, targetID int) error {
+	if sourceID == targetID {
+		return errors.New("cannot merge participant with itself")
+	}
+
+	source := findParticipant(event, sourceID)
+	target := findParticipant(event, targetID)
+	if source == nil || target == nil {
+		return ErrParticipantNotFound
+	}
+	if err := s.checkFrozenReferences(event, sourceID); err != nil {
+		return err
+	}
+
+	if target.Email == "" {
+		target.Email = source.Email
+	}
+	target.Presence = mergePresence(target.Presence, source.Presence)
+
+	s.reassignParticipant(event, sourceID, targetID)
+	removeParticipant(event, sourceID)
+	return nil
+}
+
+// checkFrozenReferences odmawia zmian, które dotknęłyby wydatków zamkniętych okresów
+func (s *ExpenseService) checkFrozenReferences(event *model.Event, participantID int) error {
+	for _, exp := range event.Expenses {
+		if exp.PeriodID != 0 && len(expenseReferences("", exp, participantID)) > 0 {
+			return fmt.Errorf("%w: expense %d references participant %d", ErrPeriodFrozen, exp.ID, participantID)
+		}
+	}
+	for _, r := range event.Repayments {
+		if r.PeriodID != 0 && (r.From == participantID || r.To == participantID) {
+			return fmt.Errorf("%w: repayment %d references participant %d", ErrPeriodFrozen, r.ID, participantID)
+		}
+	}
+	return nil
+}
+
+// reassignParticipant zastępuje odwołania do uczestnika odwołaniami do innego uczestnika
+func (s *ExpenseService) reassignParticipant(event *model.Event, fromID, toID int) {
+	for i := range event.Expenses {
+		s.reassignInExpense(&event.Expenses[i], fromID, toID)
+	}
+	for i := range event.Recurring {
+		rec := &event.Recurring[i]
+		original := rec.Template
+		s.reassignInExpense(&rec.Template, fromID, toID)
+
+		// Szablon zamieniony na pozycje ignorowałby kwotę i podział ze zmian wystąpień,
+		// więc zmiany, które je ustawiają, dostają własne pozycje
+		converted := len(original.Items) == 0 && len(rec.Template.Items) > 0
+		for j := range rec.Exceptions {
+			override := rec.Exceptions[j].Override
+			if override == nil {
+				continue
+			}
+			if converted && override.Items == nil && (override.TotalAmount != 0 || override.SharedWith != nil) {
+				effective := applyOverride(original, *override)
+				s.reassignInExpense(&effective, fromID, toID)
+				if len(effective.Items) == 0 {
+					effective.Items = []model.ExpenseItem{{
+						Description:  effective.Description,
+						Amount:       s.unsignedTotal(effective),
+						Participants: effective.SharedWith,
+					}}
+				}
+				override.Items = effective.Items
+			}
+			s.reassignInExpense(override, fromID, toID)
+		}
+	}
+
+	// Bilanse otwarcia są sumowane
+	var balances []model.OpeningBalance
+	amounts := make(map[int]float64)
+	for _, ob := range event.OpeningBalances {
+		id := ob.ParticipantID
+		if id == fromID {
+			id = toID
+		}
+		if _, exists := amounts[id]; !exists {
+			balances = append(balances, model.OpeningBalance{ParticipantID: id})
+		}
+		amounts[id] = s.RoundToTwo(amounts[id] + ob.Amount)
+	}
+	for i := range balances {
+		balances[i].Amount = amounts[balances[i].ParticipantID]
+	}
+	event.OpeningBalances = balances
+
+	// Spłaty; spłata między połączonymi osobami przestaje mieć znaczenie
+	repayments := event.Repayments[:0]
+	for _, r := range event.Repayments {
+		if r.From == fromID {
+			r.From = toID
+		}
+		if r.To == fromID {
+			r.To = toID
+		}
+		if r.From != r.To {
+			repayments = append(repayments, r)
+		}
+	}
+	event.Repayments = repayments
+
+	// Gospodarstwa domowe
+	for i := range event.Households {
+		household := &event.Households[i]
+		household.Members = replaceID(household.Members, fromID, toID)
+		if household.TreasurerID == fromID {
+			household.TreasurerID = toID
+		}
+	}
+}
+
+// reassignInExpense przenosi płatności i udziały w pojedynczym wydatku, łącząc zduplikowane płatności
+func (s *ExpenseService) reassignInExpense(exp *model.Expense, fromID, toID int) {
+	var payments []model.Payment
+	for _, payment := range exp.Payments {
+		if payment.ParticipantID == fromID {
+			payment.ParticipantID = toID
+		}
+		merged := false
+		for j := range payments {
+			if payments[j].ParticipantID == payment.ParticipantID {
+				payments[j].Amount = s.RoundToTwo(payments[j].Amount + payment.Amount)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			payments = append(payments, payment)
+		}
+	}
+	exp.Payments = payments
+
+	// Gdy osoba docelowa już dzieli koszt, połączenie list uczestników rozłożyłoby udział
+	// przenoszonej osoby na wszystkich. Wydatek zamieniany jest wtedy na pozycję, a pozycje
+	// dzielone przez obie osoby na jawne udziały poszczególnych uczestników.
+	if len(exp.Items) == 0 && containsID(exp.SharedWith, fromID) && containsID(exp.SharedWith, toID) {
+		exp.Items = []model.ExpenseItem{{Description: exp.Description, Amount: s.unsignedTotal(*exp)}}
+	}
+	var items []model.ExpenseItem
+	for _, item := range exp.Items {
+		participants := item.Participants
+		if len(participants) == 0 {
+			participants = exp.SharedWith
+		}
+		if containsID(participants, fromID) && containsID(participants, toID) {
+			items = append(items, s.splitItem(item, participants, fromID, toID)...)
+			continue
+		}
+		item.Participants = replaceID(item.Participants, fromID, toID)
+		items = append(items, item)
+	}
+	exp.Items = items
+
+	exp.SharedWith = replaceID(exp.SharedWith, fromID, toID)
+}
+
+// splitItem zamienia pozycję na pozycje poszczególnych uczestników o kwotach równych ich
+// udziałom, przenosząc udział uczestnika fromID na toID. Grosze pozostałe z podziału
+// trafiają do pierwszych uczestników, więc suma pozycji się nie zmienia.
+func (s *ExpenseService) splitItem(item model.ExpenseItem, participants []int, fromID, toID int) []model.ExpenseItem {
+	cents := int(math.Round(s.ItemTotal(item) * 100))
+	shares := make(map[int]int, len(participants))
+	var order []int
+	for i, id := range participants {
+		share := cents / len(participants)
+		if i < cents%len(participants) {
+			share++
+		}
+		if id == fromID {
+			id = toID
+		}
+		if _, exists := shares[id]; !exists {
+			order = append(order, id)
+		}
+		shares[id] += share
+	}
+
+	items := make([]model.ExpenseItem, 0, len(order))
+	for _, id := range order {
+		items = append(items, model.ExpenseItem{
+			Description:  item.Description,
+			Amount:       float64(shares[id]) / 100,
+			Participants: []int{id},
+		})
+	}
+	return items
+}
+
+// unsignedTotal zwraca kwotę wydatku bez znaku zwrotu
+func (s *ExpenseService) unsignedTotal(exp model.Expense) float64 {
+	total, _ := s.ExpenseTotal(exp)
+	return s.ExpenseSign(exp) * total
+}
+
+// redistributeParticipant usuwa uczestnika z podziału wydatków, tak aby jego udziały
+// pokryły pozostałe osoby dzielące dany wydatek lub pozycję. Dotyczy to także szablonów
+// wydatków cyklicznych i zmian ich pojedynczych wystąpień.
+func (s *ExpenseService) redistributeParticipant(event *model.Event, participantID int) error {
+	targets := splitTargets(event)
+
+	for _, target := range targets {
+		for _, payment := range target.expense.Payments {
+			if payment.ParticipantID == participantID {
+				return fmt.Errorf("%w: %s has payments by participant %d, reassign them instead", ErrParticipantReferenced, target.label, participantID)
+			}
+		}
+	}
+	for _, ob := range event.OpeningBalances {
+		if ob.ParticipantID == participantID && ob.Amount != 0 {
+			return fmt.Errorf("%w: participant %d has an opening balance, reassign it instead", ErrParticipantReferenced, participantID)
+		}
+	}
+	for _, r := range event.Repayments {
+		if r.From == participantID || r.To == participantID {
+			return fmt.Errorf("%w: repayment %d references participant %d, reassign it instead", ErrParticipantReferenced, r.ID, participantID)
+		}
+	}
+
+	// Sprawdzenie, czy po usunięciu każdy wydatek nadal ma kogo obciążyć
+	for _, target := range targets {
+		exp := target.expense
+		if containsID(exp.SharedWith, participantID) && len(exp.SharedWith) == 1 && len(exp.Items) == 0 {
+			return fmt.Errorf("%s is shared only with participant %d", target.label, participantID)
+		}
+		for j, item := range exp.Items {
+			if containsID(item.Participants, participantID) && len(item.Participants) == 1 {
+				return fmt.Errorf("%s item %d is assigned only to participant %d", target.label, j+1, participantID)
+			}
+		}
+	}
+
+	for _, target := range targets {
+		exp := target.expense
+		exp.SharedWith = removeID(exp.SharedWith, participantID)
+		for j := range exp.Items {
+			exp.Items[j].Participants = removeID(exp.Items[j].Participants, participantID)
+		}
+	}
+
+	return nil
+}
+
+// splitTarget to wydatek, szablon lub zmiana wystąpienia, w których zapisany jest podział kosztu
+type splitTarget struct {
+	label   string
+	expense *model.Expense
+}
+
+// splitTargets zwraca wszystkie miejsca wydarzenia, w których zapisany jest podział kosztu
+func splitTargets(event *model.Event) []splitTarget {
+	var targets []splitTarget
+	for i := range event.Expenses {
+		targets = append(targets, splitTarget{fmt.Sprintf("expense %d", event.Expenses[i].ID), &event.Expenses[i]})
+	}
+	for i := range event.Recurring {
+		rec := &event.Recurring[i]
+		targets = append(targets, splitTarget{fmt.Sprintf("recurring expense %d", rec.ID), &rec.Template})
+		for j := range rec.Exceptions {
+			if rec.Exceptions[j].Override != nil {
+				label := fmt.Sprintf("recurring expense %d override on %s", rec.ID, rec.Exceptions[j].Date)
+				targets = append(targets, splitTarget{label, rec.Exceptions[j].Override})
+			}
+		}
+	}
+	return targets
+}
+
+// expenseReferences zwraca opisy odwołań do uczestnika w wydatku
+func expenseReferences(label string, exp model.Expense, participantID int) []string {
+	var refs []string
+	for _, payment := range exp.Payments {
+		if payment.ParticipantID == participantID {
+			refs = append(refs, label+" payment")
+			break
+		}
+	}
+	if containsID(exp.SharedWith, participantID) {
+		refs = append(refs, label+" sharedWith")
+	}
+	for j, item := range exp.Items {
+		if containsID(item.Participants, participantID) {
+			refs = append(refs, fmt.Sprintf("%s item %d", label, j+1))
+		}
+	}
+	return refs
+}
+
+// removeParticipant usuwa uczestnika z listy uczestników i gospodarstw domowych
+func removeParticipant(event *model.Event, participantID int) {
+	participants := make([]model.Participant, 0, len(event.Participants))
+	for _, p := range event.Participants {
+		if p.ID != participantID {
+			participants = append(participants, p)
+		}
+	}
+	event.Participants = participants
+
+	households := make([]model.Household, 0, len(event.Households))
+	for _, h := range event.Households {
+		h.Members = removeID(h.Members, participantID)
+		if h.TreasurerID == participantID {
+			h.TreasurerID = 0
+		}
+		if len(h.Members) > 0 {
+			households = append(households, h)
+		}
+	}
+	event.Households = households
+}
+
+// findParticipant zwraca wskaźnik na uczestnika o podanym ID lub nil
+func findParticipant(event *model.Event, participantID int) *model.Participant {
+	for i := range event.Participants {
+		if event.Participants[i].ID == participantID {
+			return &event.Participants[i]
+		}
+	}
+	return nil
+}
+
+// mergePresence łączy okresy obecności; brak okresów oznacza obecność przez cały czas
+func mergePresence(a, b []model.PresencePeriod) []model.PresencePeriod {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	return append(append([]model.PresencePeriod(nil), a...), b...)
+}
+
+// replaceID zastępuje ID w liście, usuwając powstałe duplikaty
+func replaceID(ids []int, fromID, toID int) []int {
+	if !containsID(ids, fromID) {
+		return ids
+	}
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id == fromID {
+			id = toID
+		}
+		if !containsID(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// removeID usuwa ID z listy
+func removeID(ids []int, removed int) []int {
+	if !containsID(ids, removed) {
+		return ids
+	}
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id != removed {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// containsID sprawdza czy lista zawiera ID
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Błędy zwracane przy walidacji danych do wypłaty i przygotowaniu przelewu
+var (
+	ErrInvalidIBAN          = errors.New("invalid IBAN")
+	ErrInvalidBIC           = errors.New("invalid BIC")
+	ErrSettlementNotFound   = errors.New("settlement not found")
+	ErrMissingPayoutDetails = errors.New("payee has no payout details")
+	ErrUnsupportedCurrency  = errors.New("EPC QR codes support only EUR transfers")
+)
+
+// Ograniczenia długości pól kodu EPC069-12
+const (
+	epcMaxName       = 70
+	epcMaxRemittance = 140
+	epcMaxAmount     = 999999999.99
+)
+
+// ibanLengths to długości numerów IBAN w krajach SEPA; dla pozostałych krajów
+// sprawdzany jest tylko zakres 15-34 znaków i suma kontrolna
+var ibanLengths = map[string]int{
+	"AD": 24, "AT": 20, "BE": 16, "BG": 22, "CH": 21, "CY": 28, "CZ": 24, "DE": 22, "DK": 18,
+	"EE": 20, "ES": 24, "FI": 18, "FR": 27, "GB": 22, "GI": 23, "GR": 27, "HR": 21, "HU": 28,
+	"IE": 22, "IS": 26, "IT": 27, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MT": 31,
+	"NL": 18, "NO": 15, "PL": 28, "PT": 25, "RO": 24, "SE": 24, "SI": 19, "SK": 24, "SM": 27,
+	"VA": 22,
+}
+
+var (
+	ibanFormat = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	bicFormat  = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// NormalizeIBAN usuwa spacje i myślniki oraz zamienia litery na wielkie
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(iban)))
+}
+
+// ValidateIBAN sprawdza format, długość dla kraju i sumę kontrolną (mod 97) numeru IBAN
+func ValidateIBAN(iban string) error {
+	iban = NormalizeIBAN(iban)
+	if !ibanFormat.MatchString(iban) {
+		return fmt.Errorf("%w: unexpected format", ErrInvalidIBAN)
+	}
+	if length, ok := ibanLengths[iban[:2]]; ok && len(iban) != length {
+		return fmt.Errorf("%w: %s IBAN must have %d characters", ErrInvalidIBAN, iban[:2], length)
+	}
+
+	// Przeniesienie kraju i cyfr kontrolnych na koniec, litery zamieniane na liczby (A=10 ... Z=35)
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	number, _ := new(big.Int).SetString(digits.String(), 10)
+	if new(big.Int).Mod(number, big.NewInt(97)).Int64() != 1 {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidIBAN)
+	}
+	return nil
+}
+
+// ValidateBIC sprawdza format kodu BIC (8 lub 11 znaków)
+func ValidateBIC(bic string) error {
+	if !bicFormat.MatchString(strings.ToUpper(strings.TrimSpace(bic))) {
+		return ErrInvalidBIC
+	}
+	return nil
+}
+
+// SettlementPayment zwraca dane przelewu dla rozliczenia od uczestnika from do uczestnika to,
+// wraz z treścią kodu QR EPC069-12, gdy odbiorca podał IBAN, a wydarzenie rozliczane jest w euro
+func (s *ExpenseService) SettlementPayment(event *model.Event, from, to int) (*model.PaymentRequest, error) {
+	var settlement *model.Settlement
+	for _, candidate := range s.CalculateSummary(event).Settlements {
+		if candidate.From == from && candidate.To == to {
+			settlement = &candidate
+			break
+		}
+	}
+	if settlement == nil {
+		return nil, ErrSettlementNotFound
+	}
+
+	payee := findParticipant(event, to)
+	if payee == nil {
+		return nil, ErrParticipantNotFound
+	}
+	if payee.IBAN == "" && payee.Phone == "" {
+		return nil, ErrMissingPayoutDetails
+	}
+
+	payment := &model.PaymentRequest{
+		Settlement:  *settlement,
+		Currency:    event.Currency,
+		Beneficiary: payee.AccountHolder,
+		IBAN:        NormalizeIBAN(payee.IBAN),
+		BIC:         strings.ToUpper(strings.TrimSpace(payee.BIC)),
+		Phone:       payee.Phone,
+		Remittance:  truncateRunes(fmt.Sprintf("%s: %s", event.Name, settlement.FromName), epcMaxRemittance),
+	}
+	if payment.Beneficiary == "" {
+		payment.Beneficiary = payee.Name
+	}
+
+	if payment.IBAN != "" && isEuro(event.Currency) {
+		payment.EPC = EPCPayload(payment)
+	}
+	return payment, nil
+}
+
+// EPCPayload buduje treść kodu QR według wytycznych EPC069-12 (wersja 002, kodowanie UTF-8,
+// polecenie przelewu SEPA). Kwota musi mieścić się w zakresie 0,01-999999999,99 EUR.
+func EPCPayload(payment *model.PaymentRequest) string {
+	amount := ""
+	if payment.Settlement.Amount >= 0.01 && payment.Settlement.Amount <= epcMaxAmount {
+		amount = fmt.Sprintf("EUR%.2f", payment.Settlement.Amount)
+	}
+
+	lines := []string{
+		"BCD",
+		"002",
+		"1",
+		"SCT",
+		payment.BIC,
+		truncateRunes(payment.Beneficiary, epcMaxName),
+		payment.IBAN,
+		amount,
+		"", // kod celu płatności
+		"", // referencja strukturalna (wyklucza się z tytułem)
+		truncateRunes(payment.Remittance, epcMaxRemittance),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EnsureEPC sprawdza, czy dla przelewu można wygenerować kod QR EPC
+func EnsureEPC(payment *model.PaymentRequest) error {
+	switch {
+	case payment.IBAN == "":
+		return ErrMissingPayoutDetails
+	case payment.EPC == "":
+		return ErrUnsupportedCurrency
+	}
+	return nil
+}
+
+// validatePayoutDetails sprawdza numer IBAN i kod BIC uczestnika, jeśli zostały podane
+func (s *ExpenseService) validatePayoutDetails(p model.Participant) error {
+	if p.IBAN != "" {
+		if err := ValidateIBAN(p.IBAN); err != nil {
+			return fmt.Errorf("participant %d: %w", p.ID, err)
+		}
+	}
+	if p.BIC != "" {
+		if err := ValidateBIC(p.BIC); err != nil {
+			return fmt.Errorf("participant %d: %w", p.ID, err)
+		}
+	}
+	return nil
+}
+
+// isEuro sprawdza, czy wydarzenie rozliczane jest w euro. Waluta musi być podana jawnie,
+// bo wydarzenie bez waluty nie daje pewności, że przelew EPC trafi w EUR.
+func isEuro(currency string) bool {
+	return strings.EqualFold(strings.TrimSpace(currency), "EUR")
+}
+
+// truncateRunes skraca tekst do podanej liczby znaków
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// ErrPeriodFrozen zwracany jest przy próbie zmiany wydatków zamkniętego okresu
+var ErrPeriodFrozen = errors.New("expense belongs to a closed period")
+
+// CurrentPeriod zwraca widok wydarzenia ograniczony do wydatków bieżącego, niezamkniętego okresu
+func (s *ExpenseService) CurrentPeriod(event *model.Event) *model.Event {
+	if len(event.Periods) == 0 {
+		return event
+	}
+
+	current := *event
+	current.Expenses = make([]model.Expense, 0, len(event.Expenses))
+	for _, exp := range event.Expenses {
+		if exp.PeriodID == 0 {
+			current.Expenses = append(current.Expenses, exp)
+		}
+	}
+	current.Repayments = nil
+	for _, r := range event.Repayments {
+		if r.PeriodID == 0 {
+			current.Repayments = append(current.Repayments, r)
+		}
+	}
+	return &current
+}
+
+// LastClosedDate zwraca datę końca ostatniego zamkniętego okresu lub datę zerową
+func (s *ExpenseService) LastClosedDate(event *model.Event) model.Date {
+	if len(event.Periods) == 0 {
+		return model.Date{}
+	}
+	return event.Periods[len(event.Periods)-1].EndDate
+}
+
+// ClosePeriod zamyka okres rozliczeniowy kończący się podaną datą: zapisuje migawkę podsumowania,
+// zamraża wydatki i spłaty z tego okresu (w tym wydatki bez daty) i przenosi bilanse jako bilanse
+// otwarcia kolejnego okresu.
+func (s *ExpenseService) ClosePeriod(event *model.Event, endDate model.Date, now time.Time) (*model.Period, error) {
+	if endDate.IsZero() {
+		return nil, errors.New("period end date is required")
+	}
+
+	lastClosed := s.LastClosedDate(event)
+	if !lastClosed.IsZero() && !endDate.After(lastClosed) {
+		return nil, fmt.Errorf("period end date must be after %s", lastClosed)
+	}
+
+	period := model.Period{
+		ID:              len(event.Periods) + 1,
+		StartDate:       event.StartDate,
+		EndDate:         endDate,
+		ClosedAt:        now.UTC(),
+		OpeningBalances: event.OpeningBalances,
+	}
+	if !lastClosed.IsZero() {
+		period.StartDate = lastClosed.AddDays(1)
+	}
+
+	// Wydatki należące do zamykanego okresu
+	closing := *event
+	closing.Periods = nil
+	closing.Expenses = nil
+	for _, exp := range event.Expenses {
+		if exp.PeriodID == 0 && (exp.Date.IsZero() || !exp.Date.After(endDate)) {
+			closing.Expenses = append(closing.Expenses, exp)
+		}
+	}
+	closing.Repayments = nil
+	for _, r := range event.Repayments {
+		if r.PeriodID == 0 && (r.Date.IsZero() || !r.Date.After(endDate)) {
+			closing.Repayments = append(closing.Repayments, r)
+		}
+	}
+	period.Summary = *s.CalculateSummary(&closing)
+
+	for i := range event.Expenses {
+		exp := &event.Expenses[i]
+		if exp.PeriodID == 0 && (exp.Date.IsZero() || !exp.Date.After(endDate)) {
+			exp.PeriodID = period.ID
+		}
+	}
+	for i := range event.Repayments {
+		r := &event.Repayments[i]
+		if r.PeriodID == 0 && (r.Date.IsZero() || !r.Date.After(endDate)) {
+			r.PeriodID = period.ID
+		}
+	}
+
+	// Przeniesienie bilansów do kolejnego okresu
+	event.OpeningBalances = nil
+	for _, balance := range period.Summary.PaidByPerson {
+		if balance.Balance != 0 {
+			event.OpeningBalances = append(event.OpeningBalances, model.OpeningBalance{
+				ParticipantID: balance.ID,
+				Amount:        balance.Balance,
+			})
+		}
+	}
+
+	event.Periods = append(event.Periods, period)
+	return &period, nil
+}
+
+// PreservePeriods przenosi zamknięte okresy i bilanse otwarcia z zapisanej wersji wydarzenia
+// i sprawdza, czy wydatki zamkniętych okresów nie zostały zmienione ani usunięte.
+func (s *ExpenseService) PreservePeriods(previous, event *model.Event) error {
+	if previous == nil {
+		event.Periods = nil
+		event.OpeningBalances = nil
+		for i := range event.Expenses {
+			event.Expenses[i].PeriodID = 0
+		}
+		return nil
+	}
+
+	event.Periods = previous.Periods
+	event.OpeningBalances = previous.OpeningBalances
+
+	frozen := make(map[int]model.Expense)
+	for _, exp := range previous.Expenses {
+		if exp.PeriodID != 0 {
+			frozen[exp.ID] = exp
+		}
+	}
+
+	lastClosed := s.LastClosedDate(previous)
+	for i := range event.Expenses {
+		exp := &event.Expenses[i]
+		old, isFrozen := frozen[exp.ID]
+		if !isFrozen {
+			exp.PeriodID = 0
+			if !lastClosed.IsZero() && !exp.Date.IsZero() && !exp.Date.After(lastClosed) {
+				return fmt.Errorf("%w: expense %d is dated on or before %s", ErrPeriodFrozen, exp.ID, lastClosed)
+			}
+			continue
+		}
+
+		exp.PeriodID = old.PeriodID
+		if !sameContent(old, *exp) {
+			return fmt.Errorf("%w: expense %d cannot be modified", ErrPeriodFrozen, exp.ID)
+		}
+		delete(frozen, exp.ID)
+	}
+
+	if len(frozen) > 0 {
+		ids := make([]int, 0, len(frozen))
+		for id := range frozen {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		return fmt.Errorf("%w: expense %d cannot be removed", ErrPeriodFrozen, ids[0])
+	}
+	return nil
+}
+
+// sameContent porównuje wydatki z pominięciem pól zarządzanych przez serwer
+func sameContent(a, b model.Expense) bool {
+	return reflect.DeepEqual(normalizeExpense(a), normalizeExpense(b))
+}
+
+// normalizeExpense zeruje pola zarządzane przez serwer i ujednolica puste listy
+func normalizeExpense(e model.Expense) model.Expense {
+	e.CreatedAt, e.UpdatedAt = time.Time{}, time.Time{}
+	e.Attachments = nil
+	if len(e.Payments) == 0 {
+		e.Payments = nil
+	}
+	if len(e.SharedWith) == 0 {
+		e.SharedWith = nil
+	}
+	if len(e.Items) == 0 {
+		e.Items = nil
+		return e
+	}
+
+	items := make([]model.ExpenseItem, len(e.Items))
+	for i, item := range e.Items {
+		if len(item.Participants) == 0 {
+			item.Participants = nil
+		}
+		items[i] = item
+	}
+	e.Items = items
+	return e
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// ErrPersonNotFound zwracany jest gdy osoba nie uczestniczy w żadnym wydarzeniu
+var ErrPersonNotFound = errors.New("person not found")
+
+// PersonKey zwraca identyfikator osoby wspólny dla wszystkich wydarzeń: ID konta użytkownika
+// lub adres e-mail. Uczestnik bez konta i e-maila jest osobą tylko w obrębie swojego wydarzenia.
+func PersonKey(event *model.Event, participant model.Participant) string {
+	if participant.UserID != "" {
+		return "user:" + participant.UserID
+	}
+	if email := strings.ToLower(strings.TrimSpace(participant.Email)); email != "" {
+		return "email:" + email
+	}
+	return fmt.Sprintf("event:%d:participant:%d", event.ID, participant.ID)
+}
+
+// NormalizePersonID zamienia identyfikator z adresu URL (e-mail lub ID konta) na klucz osoby
+func NormalizePersonID(id string) string {
+	if strings.Contains(id, "@") {
+		return "email:" + strings.ToLower(strings.TrimSpace(id))
+	}
+	return "user:" + id
+}
+
+// CalculatePersonBalances sumuje bilanse osoby w bieżących okresach wszystkich nierozliczonych
+// wydarzeń i wyznacza łączny plan rozliczeń, w którym długi z różnych wydarzeń są kompensowane.
+// Osoba uczestnicząca wyłącznie w rozliczonych wydarzeniach traktowana jest jak nieznana.
+func (s *ExpenseService) CalculatePersonBalances(events []*model.Event, person string) (*model.PersonBalances, error) {
+	result := &model.PersonBalances{
+		Person: person,
+		Events: []model.PersonEventBalance{},
+	}
+
+	// Bilanse wszystkich osób, potrzebne do kompensacji długów między wydarzeniami
+	net := s.netPersonBalances(events, func(event *model.Event, key string, balance model.ParticipantBalance) {
+		if key != person {
+			return
+		}
+		result.Name = balance.Name
+		result.Events = append(result.Events, model.PersonEventBalance{
+			EventID:       event.ID,
+			EventName:     event.Name,
+			ParticipantID: balance.ID,
+			Balance:       balance.Balance,
+		})
+	})
+
+	if len(result.Events) == 0 {
+		return nil, ErrPersonNotFound
+	}
+	result.NetBalance = net.amounts[person]
+
+	result.Settlements = []model.PersonSettlement{}
+	for _, settlement := range s.personSettlements(net) {
+		if settlement.From == person || settlement.To == person {
+			result.Settlements = append(result.Settlements, settlement)
+		}
+	}
+
+	return result, nil
+}
+
+// personNet zawiera łączne bilanse osób z wielu wydarzeń w kolejności pierwszego wystąpienia
+type personNet struct {
+	keys    []string
+	names   map[string]string
+	amounts map[string]float64
+}
+
+// netPersonBalances sumuje bilanse bieżących okresów wydarzeń według klucza osoby. Wydarzenia
+// rozliczone (SettledAt) są pomijane, bo ich długi zostały już spłacone. Funkcja visit (opcjonalna) wywoływana jest dla każdego bilansu uczestnika.
+func (s *ExpenseService) netPersonBalances(events []*model.Event, visit func(event *model.Event, key string, balance model.ParticipantBalance)) personNet {
+	net := personNet{
+		names:   make(map[string]string),
+		amounts: make(map[string]float64),
+	}
+
+	// Stała kolejność wydarzeń daje powtarzalny plan rozliczeń
+	sorted := append([]*model.Event(nil), events...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	for _, event := range sorted {
+		if !event.SettledAt.IsZero() {
+			continue
+		}
+
+		participants := make(map[int]model.Participant, len(event.Participants))
+		for _, p := range event.Participants {
+			participants[p.ID] = p
+		}
+
+		for _, balance := range s.CalculateBalances(s.CurrentPeriod(event)) {
+			key := PersonKey(event, participants[balance.ID])
+			if _, exists := net.names[key]; !exists {
+				net.keys = append(net.keys, key)
+				net.names[key] = balance.Name
+			}
+			net.amounts[key] = s.RoundToTwo(net.amounts[key] + balance.Balance)
+
+			if visit != nil {
+				visit(event, key, balance)
+			}
+		}
+	}
+
+	return net
+}
+
+// personSettlements wyznacza plan rozliczeń między osobami na podstawie łącznych bilansów
+func (s *ExpenseService) personSettlements(net personNet) []model.PersonSettlement {
+	// Rozliczenia wyznaczane są na numerach osób, a następnie tłumaczone na klucze
+	balances := make([]model.ParticipantBalance, len(net.keys))
+	for i, key := range net.keys {
+		balances[i] = model.ParticipantBalance{ID: i + 1, Name: net.names[key], Balance: net.amounts[key]}
+	}
+
+	settlements := []model.PersonSettlement{}
+	for _, settlement := range s.CalculateSettlements(balances) {
+		settlements = append(settlements, model.PersonSettlement{
+			From:     net.keys[settlement.From-1],
+			FromName: settlement.FromName,
+			To:       net.keys[settlement.To-1],
+			ToName:   settlement.ToName,
+			Amount:   settlement.Amount,
+		})
+	}
+	return settlements
+}
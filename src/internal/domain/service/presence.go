@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// IsPresent sprawdza czy uczestnik był obecny na wydarzeniu w podanym dniu.
+// Uczestnik bez okresów obecności jest obecny zawsze.
+func (s *ExpenseService) IsPresent(participant model.Participant, date model.Date) bool {
+	if len(participant.Presence) == 0 || date.IsZero() {
+		return true
+	}
+
+	for _, period := range participant.Presence {
+		if !period.From.IsZero() && date.Before(period.From) {
+			continue
+		}
+		if !period.To.IsZero() && date.After(period.To) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// PresentParticipants zwraca ID uczestników obecnych w podanym dniu
+func (s *ExpenseService) PresentParticipants(event *model.Event, date model.Date) []int {
+	var ids []int
+	for _, p := range event.Participants {
+		if s.IsPresent(p, date) {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
+
+// EffectiveSharedWith zwraca uczestników, między których dzielony jest wydatek.
+// Gdy wydarzenie ma włączone ShareByPresence, datowany wydatek bez SharedWith
+// dzielony jest między uczestników obecnych w dniu wydatku.
+func (s *ExpenseService) EffectiveSharedWith(event *model.Event, exp model.Expense) []int {
+	if len(exp.SharedWith) > 0 || !event.ShareByPresence || exp.Date.IsZero() {
+		return exp.SharedWith
+	}
+	return s.PresentParticipants(event, exp.Date)
+}
+
+// validatePresenceWindows sprawdza poprawność okresów obecności uczestników
+func (s *ExpenseService) validatePresenceWindows(event *model.Event) error {
+	for _, p := range event.Participants {
+		for _, period := range p.Presence {
+			if period.From.IsZero() && period.To.IsZero() {
+				return fmt.Errorf("participant %d: presence period needs a start or end date", p.ID)
+			}
+			if !period.From.IsZero() && !period.To.IsZero() && period.To.Before(period.From) {
+				return fmt.Errorf("participant %d: presence period ends before it starts", p.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// validatePresence sprawdza czy datowany wydatek dzielony jest tylko między obecnych uczestników
+func (s *ExpenseService) validatePresence(event *model.Event, exp model.Expense) error {
+	participants := make(map[int]model.Participant, len(event.Participants))
+	for _, p := range event.Participants {
+		participants[p.ID] = p
+	}
+
+	sharedWith := s.EffectiveSharedWith(event, exp)
+	if event.ShareByPresence && len(exp.SharedWith) == 0 && len(exp.Items) == 0 && len(sharedWith) == 0 {
+		return errors.New("no participants present on the expense date")
+	}
+
+	check := func(ids []int) error {
+		for _, id := range ids {
+			if p, ok := participants[id]; ok && !s.IsPresent(p, exp.Date) {
+				return fmt.Errorf("participant %d was not present on %s", id, exp.Date)
+			}
+		}
+		return nil
+	}
+
+	if err := check(sharedWith); err != nil {
+		return err
+	}
+	for _, item := range exp.Items {
+		if err := check(item.Participants); err != nil {
+			return err
+		}
+	}
+	return nil
+}
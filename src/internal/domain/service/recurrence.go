@@ -0,0 +1,323 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Częstotliwości reguł powtarzania
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// maxOccurrences ogranicza liczbę wystąpień generowanych w jednym przebiegu
+const maxOccurrences = 1000
+
+// RecurrenceRule to sparsowana reguła powtarzania (podzbiór RFC 5545 RRULE)
+type RecurrenceRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    model.Date
+}
+
+// ParseRecurrenceRule parsuje regułę RRULE (FREQ, INTERVAL, COUNT, UNTIL) lub skrót
+// "daily", "weekly", "monthly", "yearly"
+func ParseRecurrenceRule(value string) (RecurrenceRule, error) {
+	rule := RecurrenceRule{Interval: 1}
+
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	switch strings.ToUpper(value) {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+		rule.Freq = strings.ToUpper(value)
+		return rule, nil
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return rule, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return rule, fmt.Errorf("invalid interval %q", val)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return rule, fmt.Errorf("invalid count %q", val)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := time.Parse("20060102", val[:min(len(val), 8)])
+			if err != nil {
+				return rule, fmt.Errorf("invalid until %q", val)
+			}
+			rule.Until = model.NewDate(until.Year(), until.Month(), until.Day())
+		default:
+			return rule, fmt.Errorf("unsupported rule part %q", name)
+		}
+	}
+
+	switch rule.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+		return rule, nil
+	case "":
+		return rule, errors.New("FREQ is required")
+	default:
+		return rule, fmt.Errorf("unsupported frequency %q", rule.Freq)
+	}
+}
+
+// Occurrences zwraca daty wystąpień wydatku cyklicznego późniejsze niż MaterializedUntil
+// i nie późniejsze niż podana data. Limit maxOccurrences dotyczy pojedynczego wywołania,
+// więc szablon starszy niż limit jest materializowany w kolejnych przebiegach.
+func (s *ExpenseService) Occurrences(rec model.RecurringExpense, until model.Date) ([]model.Date, error) {
+	rule, err := ParseRecurrenceRule(rec.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	end := until
+	if !rec.EndDate.IsZero() && rec.EndDate.Before(end) {
+		end = rec.EndDate
+	}
+	if !rule.Until.IsZero() && rule.Until.Before(end) {
+		end = rule.Until
+	}
+
+	var dates []model.Date
+	first := firstOccurrenceAfter(rec.StartDate, rule, rec.MaterializedUntil)
+	for n := first; n < first+maxOccurrences; n++ {
+		if rule.Count > 0 && n >= rule.Count {
+			break
+		}
+		date := occurrenceDate(rec.StartDate, rule, n)
+		if date.After(end) {
+			break
+		}
+		dates = append(dates, date)
+	}
+
+	return dates, nil
+}
+
+// MaterializeRecurring tworzy konkretne wydatki dla wystąpień szablonów cyklicznych
+// przypadających nie później niż podana data. Zwraca liczbę utworzonych wydatków.
+// Wystąpienia już zmaterializowane (także później usunięte) nie są tworzone ponownie,
+// a wystąpienia z zamkniętych okresów rozliczeniowych są pomijane.
+func (s *ExpenseService) MaterializeRecurring(event *model.Event, today model.Date) (int, error) {
+	lastClosed := s.LastClosedDate(event)
+
+	nextID := 1
+	for _, exp := range event.Expenses {
+		if exp.ID >= nextID {
+			nextID = exp.ID + 1
+		}
+	}
+
+	created := 0
+	for i := range event.Recurring {
+		rec := &event.Recurring[i]
+		if rec.MaterializedUntil.Before(lastClosed) {
+			rec.MaterializedUntil = lastClosed
+		}
+
+		dates, err := s.Occurrences(*rec, today)
+		if err != nil {
+			return created, fmt.Errorf("recurring expense %d: %w", rec.ID, err)
+		}
+
+		for _, date := range dates {
+			expense, ok := s.occurrenceExpense(*rec, date)
+			if ok && !hasOccurrence(event, rec.ID, date) {
+				expense.ID = nextID
+				nextID++
+				event.Expenses = append(event.Expenses, expense)
+				created++
+			}
+			rec.MaterializedUntil = date
+		}
+	}
+
+	return created, nil
+}
+
+// PreserveRecurringState przenosi zarządzany przez serwer stan szablonów cyklicznych z zapisanej wersji wydarzenia
+func (s *ExpenseService) PreserveRecurringState(previous, event *model.Event) {
+	stored := make(map[int]model.Date)
+	if previous != nil {
+		for _, rec := range previous.Recurring {
+			stored[rec.ID] = rec.MaterializedUntil
+		}
+	}
+
+	for i := range event.Recurring {
+		event.Recurring[i].MaterializedUntil = stored[event.Recurring[i].ID]
+	}
+}
+
+// validateRecurring sprawdza poprawność szablonów wydatków cyklicznych
+func (s *ExpenseService) validateRecurring(event *model.Event) error {
+	seen := make(map[int]bool, len(event.Recurring))
+	for _, rec := range event.Recurring {
+		if seen[rec.ID] {
+			return fmt.Errorf("duplicate recurring expense ID %d", rec.ID)
+		}
+		seen[rec.ID] = true
+
+		if _, err := ParseRecurrenceRule(rec.Rule); err != nil {
+			return fmt.Errorf("recurring expense %d: invalid rule: %w", rec.ID, err)
+		}
+		if rec.StartDate.IsZero() {
+			return fmt.Errorf("recurring expense %d: start date is required", rec.ID)
+		}
+		if !rec.EndDate.IsZero() && rec.EndDate.Before(rec.StartDate) {
+			return fmt.Errorf("recurring expense %d: end date must not be before start date", rec.ID)
+		}
+
+		template := rec.Template
+		template.Date = model.Date{}
+		if err := s.validateExpense(event, template); err != nil {
+			return fmt.Errorf("recurring expense %d: %w", rec.ID, err)
+		}
+		for _, ex := range rec.Exceptions {
+			if ex.Override == nil {
+				continue
+			}
+			if err := s.validateExpense(event, applyOverride(template, *ex.Override)); err != nil {
+				return fmt.Errorf("recurring expense %d: override on %s: %w", rec.ID, ex.Date, err)
+			}
+		}
+	}
+	return nil
+}
+
+// occurrenceExpense buduje wydatek dla wystąpienia z uwzględnieniem pominięć i zmian.
+// Zwraca false, jeśli wystąpienie zostało pominięte.
+func (s *ExpenseService) occurrenceExpense(rec model.RecurringExpense, date model.Date) (model.Expense, bool) {
+	expense := rec.Template
+	expense.Payments = append([]model.Payment(nil), rec.Template.Payments...)
+	expense.SharedWith = append([]int(nil), rec.Template.SharedWith...)
+	expense.Attachments = nil
+
+	for _, ex := range rec.Exceptions {
+		if !ex.Date.Equal(date.Time) {
+			continue
+		}
+		if ex.Skip {
+			return expense, false
+		}
+		if ex.Override != nil {
+			expense = applyOverride(expense, *ex.Override)
+		}
+	}
+
+	expense.Date = date
+	expense.RecurringID = rec.ID
+	return expense, true
+}
+
+// applyOverride nadpisuje niepuste pola wydatku wartościami z wyjątku
+func applyOverride(expense, override model.Expense) model.Expense {
+	if override.TotalAmount != 0 {
+		expense.TotalAmount = override.TotalAmount
+	}
+	if override.Category != "" {
+		expense.Category = override.Category
+	}
+	if override.Description != "" {
+		expense.Description = override.Description
+	}
+	if override.Notes != "" {
+		expense.Notes = override.Notes
+	}
+	if override.Payments != nil {
+		expense.Payments = append([]model.Payment(nil), override.Payments...)
+	}
+	if override.SharedWith != nil {
+		expense.SharedWith = append([]int(nil), override.SharedWith...)
+	}
+	if override.Items != nil {
+		expense.Items = nil
+		for _, item := range override.Items {
+			item.Participants = append([]int(nil), item.Participants...)
+			expense.Items = append(expense.Items, item)
+		}
+	}
+	return expense
+}
+
+// hasOccurrence sprawdza czy wydarzenie zawiera już wydatek dla danego wystąpienia
+func hasOccurrence(event *model.Event, recurringID int, date model.Date) bool {
+	for _, exp := range event.Expenses {
+		if exp.RecurringID == recurringID && exp.Date.Equal(date.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// occurrenceDate wyznacza datę n-tego wystąpienia. Dla reguł miesięcznych i rocznych
+// dzień wykraczający poza koniec miesiąca (np. 31) przesuwany jest na ostatni dzień miesiąca.
+func occurrenceDate(start model.Date, rule RecurrenceRule, n int) model.Date {
+	step := n * rule.Interval
+	switch rule.Freq {
+	case FreqDaily:
+		return start.AddDays(step)
+	case FreqWeekly:
+		return start.AddDays(7 * step)
+	case FreqMonthly:
+		return addMonthsClamped(start, step)
+	default:
+		return addMonthsClamped(start, 12*step)
+	}
+}
+
+// firstOccurrenceAfter zwraca numer pierwszego wystąpienia późniejszego niż podana data
+// (0, gdy data jest pusta lub wcześniejsza niż początek)
+func firstOccurrenceAfter(start model.Date, rule RecurrenceRule, after model.Date) int {
+	if after.IsZero() || after.Before(start) {
+		return 0
+	}
+
+	// Przybliżony numer wystąpienia, poprawiany krokowo ze względu na krótsze miesiące
+	var n int
+	switch days := int(after.Sub(start.Time).Hours() / 24); rule.Freq {
+	case FreqDaily:
+		n = days / rule.Interval
+	case FreqWeekly:
+		n = days / (7 * rule.Interval)
+	case FreqMonthly:
+		n = ((after.Year()-start.Year())*12 + int(after.Month()-start.Month())) / rule.Interval
+	default:
+		n = (after.Year() - start.Year()) / rule.Interval
+	}
+
+	n = max(n-1, 0)
+	for !occurrenceDate(start, rule, n).After(after) {
+		n++
+	}
+	return n
+}
+
+// addMonthsClamped dodaje miesiące do daty bez przechodzenia na kolejny miesiąc
+func addMonthsClamped(date model.Date, months int) model.Date {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, months, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return model.NewDate(first.Year(), first.Month(), min(date.Day(), lastDay))
+}
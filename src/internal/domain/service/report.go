@@ -0,0 +1,202 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Report to treść raportu rozliczenia wydarzenia z tekstami sformatowanymi zgodnie z językiem.
+// Format docelowy (np. PDF) odpowiada wyłącznie za układ strony.
+type Report struct {
+	Title        string
+	Details      []ReportDetail
+	Participants ReportTable
+	Expenses     ReportTable
+	Categories   ReportChart
+	Settlements  ReportList
+	Footer       string
+}
+
+// ReportDetail to para etykieta-wartość w nagłówku raportu
+type ReportDetail struct {
+	Label string
+	Value string
+}
+
+// ReportTable to tabela raportu; kolumny oznaczone w Numeric wyrównywane są do prawej
+type ReportTable struct {
+	Title   string
+	Header  []string
+	Numeric []bool
+	Rows    [][]string
+}
+
+// ReportChart to wykres słupkowy udziałów kategorii w wydatkach
+type ReportChart struct {
+	Title string
+	Bars  []ReportBar
+}
+
+// ReportBar to słupek wykresu; Fraction to udział w zakresie 0-1, Color w formacie #RRGGBB
+type ReportBar struct {
+	Label    string
+	Value    string
+	Fraction float64
+	Color    string
+}
+
+// ReportList to lista instrukcji (np. kto komu ile przekazuje)
+type ReportList struct {
+	Title string
+	Items []string
+}
+
+// reportLabels zawiera teksty raportu w obsługiwanych językach
+var reportLabels = map[Locale]map[string]string{
+	LocalePL: {
+		"title":        "Rozliczenie: %s",
+		"dates":        "Termin",
+		"currency":     "Waluta",
+		"participants": "Uczestnicy",
+		"expenses":     "Wydatki",
+		"total":        "Suma wydatków",
+		"perPerson":    "Średnio na osobę",
+		"categories":   "Wydatki według kategorii",
+		"settlements":  "Jak się rozliczyć",
+		"date":         "Data",
+		"description":  "Opis",
+		"category":     "Kategoria",
+		"paidBy":       "Zapłacił",
+		"amount":       "Kwota",
+		"name":         "Uczestnik",
+		"paid":         "Zapłacił",
+		"shouldPay":    "Udział",
+		"balance":      "Bilans",
+		"pays":         "%s przekazuje %s: %s",
+		"settled":      "Wszyscy są rozliczeni.",
+		"generated":    "Wygenerowano %s",
+	},
+	LocaleEN: {
+		"title":        "Settlement: %s",
+		"dates":        "Dates",
+		"currency":     "Currency",
+		"participants": "Participants",
+		"expenses":     "Expenses",
+		"total":        "Total spent",
+		"perPerson":    "Average per person",
+		"categories":   "Spending by category",
+		"settlements":  "How to settle up",
+		"date":         "Date",
+		"description":  "Description",
+		"category":     "Category",
+		"paidBy":       "Paid by",
+		"amount":       "Amount",
+		"name":         "Participant",
+		"paid":         "Paid",
+		"shouldPay":    "Share",
+		"balance":      "Balance",
+		"pays":         "%s pays %s %s",
+		"settled":      "Everyone is settled up.",
+		"generated":    "Generated %s",
+	},
+}
+
+// BuildReport przygotowuje raport rozliczenia wydarzenia na podstawie CalculateSummary:
+// nagłówek, tabelę uczestników, listę wydatków, wykres kategorii i instrukcje spłat.
+// Tak jak podsumowanie, lista i liczba wydatków obejmują tylko bieżący okres.
+func (s *ExpenseService) BuildReport(event *model.Event, locale Locale, now time.Time) *Report {
+	labels := reportLabels[locale]
+	if labels == nil {
+		labels = reportLabels[LocalePL]
+	}
+	money := func(amount float64) string {
+		return FormatMoney(amount, event.Currency, locale)
+	}
+
+	summary := s.CalculateSummary(event)
+	current := s.CurrentPeriod(event)
+	report := &Report{
+		Title:  fmt.Sprintf(labels["title"], event.Name),
+		Footer: fmt.Sprintf(labels["generated"], FormatDate(model.NewDate(now.Year(), now.Month(), now.Day()), locale)),
+	}
+
+	if !event.StartDate.IsZero() || !event.EndDate.IsZero() {
+		dates := FormatDate(event.StartDate, locale)
+		if !event.EndDate.IsZero() && event.EndDate != event.StartDate {
+			dates = strings.TrimSpace(dates + " – " + FormatDate(event.EndDate, locale))
+		}
+		report.Details = append(report.Details, ReportDetail{Label: labels["dates"], Value: dates})
+	}
+	if event.Currency != "" {
+		report.Details = append(report.Details, ReportDetail{Label: labels["currency"], Value: event.Currency})
+	}
+	report.Details = append(report.Details,
+		ReportDetail{Label: labels["participants"], Value: strconv.Itoa(len(event.Participants))},
+		ReportDetail{Label: labels["expenses"], Value: strconv.Itoa(len(current.Expenses))},
+		ReportDetail{Label: labels["total"], Value: money(summary.TotalAmount)},
+		ReportDetail{Label: labels["perPerson"], Value: money(summary.PerPersonAmount)},
+	)
+
+	report.Participants = ReportTable{
+		Title:   labels["participants"],
+		Header:  []string{labels["name"], labels["paid"], labels["shouldPay"], labels["balance"]},
+		Numeric: []bool{false, true, true, true},
+	}
+	for _, b := range summary.PaidByPerson {
+		report.Participants.Rows = append(report.Participants.Rows,
+			[]string{b.Name, money(b.Paid), money(b.ShouldPay), money(b.Balance)})
+	}
+
+	names := make(map[int]string, len(event.Participants))
+	for _, p := range event.Participants {
+		names[p.ID] = p.Name
+	}
+	report.Expenses = ReportTable{
+		Title:   labels["expenses"],
+		Header:  []string{labels["date"], labels["description"], labels["category"], labels["paidBy"], labels["amount"]},
+		Numeric: []bool{false, false, false, false, true},
+	}
+	for _, exp := range current.Expenses {
+		var payers []string
+		for _, payment := range exp.Payments {
+			name, ok := names[payment.ParticipantID]
+			if !ok {
+				name = fmt.Sprintf("#%d", payment.ParticipantID)
+			}
+			payers = append(payers, name)
+		}
+		total, _ := s.ExpenseTotal(exp)
+		report.Expenses.Rows = append(report.Expenses.Rows, []string{
+			FormatDate(exp.Date, locale),
+			exp.Description,
+			s.NormalizeCategory(event, exp.Category).Name,
+			strings.Join(payers, ", "),
+			money(s.ExpenseSign(exp) * total),
+		})
+	}
+
+	report.Categories.Title = labels["categories"]
+	for _, category := range summary.Categories {
+		report.Categories.Bars = append(report.Categories.Bars, ReportBar{
+			Label:    category.Name,
+			Value:    fmt.Sprintf("%s (%s%%)", money(category.TotalAmount), FormatDecimal(category.Percentage, locale)),
+			Fraction: category.Percentage / 100,
+			Color:    category.Color,
+		})
+	}
+
+	report.Settlements.Title = labels["settlements"]
+	for _, settlement := range summary.Settlements {
+		report.Settlements.Items = append(report.Settlements.Items,
+			fmt.Sprintf(labels["pays"], settlement.FromName, settlement.ToName, money(settlement.Amount)))
+	}
+	if len(report.Settlements.Items) == 0 {
+		report.Settlements.Items = []string{labels["settled"]}
+	}
+
+	return report
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// CloneOptions określa, co poza składem i ustawieniami kopiowane jest do nowego wydarzenia
+type CloneOptions struct {
+	Name            string `json:"name,omitempty"`
+	IncludeExpenses bool   `json:"includeExpenses,omitempty"`
+}
+
+// CloneEvent tworzy nowe wydarzenie z uczestnikami, kategoriami, gospodarstwami, wydatkami
+// cyklicznymi i ustawieniami wydarzenia źródłowego. Daty i okna obecności kopiowane są tylko
+// razem z wydatkami, bo dotyczą konkretnego terminu. Stan zarządzany przez serwer (okresy,
+// załączniki, rozliczenie, postęp materializacji wydatków cyklicznych) nie jest kopiowany.
+// Bez wydatków szablony cykliczne tworzą tylko wystąpienia od dnia now, aby kopia nie
+// dostała zaległych wydatków z terminu źródłowego.
+//
+// Model nie ma osobnych presetów podziału: sposób dzielenia kosztów opisują gospodarstwa
+// domowe, ShareByPresence oraz podziały w szablonach wydatków cyklicznych i to one są kopiowane.
+func (s *ExpenseService) CloneEvent(source *model.Event, options CloneOptions, now time.Time) *model.Event {
+	clone := &model.Event{
+		Name:            source.Name,
+		Currency:        source.Currency,
+		Participants:    cloneParticipants(source.Participants, options.IncludeExpenses),
+		Categories:      cloneCategories(source.Categories),
+		Households:      cloneHouseholds(source.Households),
+		ShareByPresence: source.ShareByPresence,
+		ReminderDays:    source.ReminderDays,
+		Expenses:        []model.Expense{},
+	}
+	if name := strings.TrimSpace(options.Name); name != "" {
+		clone.Name = name
+	}
+
+	// Szablony cykliczne dostają kolejne identyfikatory. Z wydatkami materializacja zaczyna
+	// się od nowa, bo skopiowane wystąpienia nie zostaną utworzone ponownie; bez nich
+	// pomijane są wystąpienia sprzed dnia utworzenia kopii.
+	materializedUntil := model.Date{}
+	if !options.IncludeExpenses {
+		materializedUntil = model.NewDate(now.Year(), now.Month(), now.Day()).AddDays(-1)
+	}
+	recurringIDs := make(map[int]int, len(source.Recurring))
+	for i, rec := range source.Recurring {
+		recurringIDs[rec.ID] = i + 1
+		cloned := cloneRecurring(rec, i+1)
+		cloned.MaterializedUntil = materializedUntil
+		clone.Recurring = append(clone.Recurring, cloned)
+	}
+
+	if options.IncludeExpenses {
+		clone.StartDate = source.StartDate
+		clone.EndDate = source.EndDate
+		for _, exp := range source.Expenses {
+			cloned := cloneExpense(exp)
+			// Skopiowane wystąpienia pozostają powiązane z szablonem, aby nie zostały
+			// utworzone ponownie przy materializacji
+			cloned.RecurringID = recurringIDs[exp.RecurringID]
+			clone.Expenses = append(clone.Expenses, cloned)
+		}
+	}
+
+	return clone
+}
+
+// TemplateFromEvent tworzy szablon o podanej nazwie na podstawie wydarzenia
+func (s *ExpenseService) TemplateFromEvent(name string, event *model.Event) *model.EventTemplate {
+	return &model.EventTemplate{
+		Name:            name,
+		Participants:    cloneParticipants(event.Participants, false),
+		Categories:      cloneCategories(event.Categories),
+		Households:      cloneHouseholds(event.Households),
+		ShareByPresence: event.ShareByPresence,
+	}
+}
+
+// ApplyTemplate uzupełnia wydarzenie danymi z szablonu. Pola podane w wydarzeniu
+// mają pierwszeństwo przed szablonem.
+func (s *ExpenseService) ApplyTemplate(template *model.EventTemplate, event *model.Event) {
+	if event.Name == "" {
+		event.Name = template.Name
+	}
+	if len(event.Participants) == 0 {
+		event.Participants = cloneParticipants(template.Participants, false)
+	}
+	if len(event.Categories) == 0 {
+		event.Categories = cloneCategories(template.Categories)
+	}
+	if len(event.Households) == 0 {
+		event.Households = cloneHouseholds(template.Households)
+	}
+	event.ShareByPresence = event.ShareByPresence || template.ShareByPresence
+}
+
+// ValidateTemplate sprawdza poprawność szablonu wydarzenia
+func (s *ExpenseService) ValidateTemplate(template *model.EventTemplate) error {
+	if strings.TrimSpace(template.Name) == "" {
+		return errors.New("template name is required")
+	}
+
+	event := &model.Event{}
+	s.ApplyTemplate(template, event)
+	return s.ValidateEvent(event)
+}
+
+// cloneParticipants kopiuje uczestników, opcjonalnie wraz z oknami obecności
+func cloneParticipants(participants []model.Participant, withPresence bool) []model.Participant {
+	cloned := make([]model.Participant, len(participants))
+	for i, p := range participants {
+		cloned[i] = p
+		cloned[i].Presence = nil
+		if withPresence {
+			cloned[i].Presence = append([]model.PresencePeriod(nil), p.Presence...)
+		}
+	}
+	return cloned
+}
+
+// cloneCategories kopiuje katalog kategorii
+func cloneCategories(categories []model.Category) []model.Category {
+	if categories == nil {
+		return nil
+	}
+	cloned := make([]model.Category, len(categories))
+	for i, c := range categories {
+		cloned[i] = c
+		cloned[i].Aliases = append([]string(nil), c.Aliases...)
+	}
+	return cloned
+}
+
+// cloneHouseholds kopiuje gospodarstwa domowe
+func cloneHouseholds(households []model.Household) []model.Household {
+	if households == nil {
+		return nil
+	}
+	cloned := make([]model.Household, len(households))
+	for i, h := range households {
+		cloned[i] = h
+		cloned[i].Members = append([]int(nil), h.Members...)
+	}
+	return cloned
+}
+
+// cloneRecurring kopiuje szablon wydatku cyklicznego pod nowym identyfikatorem,
+// bez postępu materializacji
+func cloneRecurring(rec model.RecurringExpense, id int) model.RecurringExpense {
+	cloned := rec
+	cloned.ID = id
+	cloned.Template = cloneExpense(rec.Template)
+	cloned.MaterializedUntil = model.Date{}
+	cloned.Exceptions = nil
+	for _, ex := range rec.Exceptions {
+		if ex.Override != nil {
+			override := cloneExpense(*ex.Override)
+			ex.Override = &override
+		}
+		cloned.Exceptions = append(cloned.Exceptions, ex)
+	}
+	return cloned
+}
+
+// cloneExpense kopiuje wydatek bez stanu zarządzanego przez serwer
+func cloneExpense(exp model.Expense) model.Expense {
+	cloned := exp
+	cloned.Payments = append([]model.Payment(nil), exp.Payments...)
+	cloned.SharedWith = append([]int(nil), exp.SharedWith...)
+	cloned.Items = nil
+	for _, item := range exp.Items {
+		item.Participants = append([]int(nil), item.Participants...)
+		cloned.Items = append(cloned.Items, item)
+	}
+	cloned.Attachments = nil
+	cloned.RecurringID = 0
+	cloned.PeriodID = 0
+	return cloned
+}
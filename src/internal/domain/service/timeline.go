@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Szczegółowość osi czasu bilansów
+const (
+	TimelineByDay     = "day"
+	TimelineByExpense = "expense"
+)
+
+// CalculateTimeline oblicza skumulowane bilanse uczestników po każdym dniu lub wydatku.
+// Wydatki i spłaty bez daty trafiają na początek osi czasu. Spłaty tworzą własne punkty
+// (bez ExpenseID), chyba że przypadają na dzień wydatku, więc ostatni punkt zawsze
+// odpowiada bieżącym bilansom wydarzenia.
+func (s *ExpenseService) CalculateTimeline(event *model.Event, granularity string) ([]model.TimelinePoint, error) {
+	if granularity == "" {
+		granularity = TimelineByDay
+	}
+	if granularity != TimelineByDay && granularity != TimelineByExpense {
+		return nil, fmt.Errorf("unsupported timeline granularity %q", granularity)
+	}
+
+	expenses := make([]model.Expense, len(event.Expenses))
+	copy(expenses, event.Expenses)
+	sort.SliceStable(expenses, func(i, j int) bool {
+		if !expenses[i].Date.Equal(expenses[j].Date.Time) {
+			return expenses[i].Date.Before(expenses[j].Date)
+		}
+		return expenses[i].ID < expenses[j].ID
+	})
+
+	repayments := make([]model.Repayment, len(event.Repayments))
+	copy(repayments, event.Repayments)
+	sort.SliceStable(repayments, func(i, j int) bool {
+		return repayments[i].Date.Before(repayments[j].Date)
+	})
+
+	// Wydarzenie robocze, do którego dokładane są kolejne wydatki i spłaty. Oś czasu
+	// obejmuje całą historię, więc bilanse otwarcia zamkniętych okresów są pomijane.
+	partial := *event
+	partial.OpeningBalances = nil
+
+	timeline := make([]model.TimelinePoint, 0, len(expenses)+len(repayments))
+	totalAmount := 0.0
+
+	added, repaid := 0, 0
+	for added < len(expenses) || repaid < len(repayments) {
+		// Przy tej samej dacie wydatek poprzedza spłatę
+		byExpense := repaid == len(repayments) ||
+			(added < len(expenses) && !expenses[added].Date.After(repayments[repaid].Date))
+
+		var date model.Date
+		expenseID := 0
+		if byExpense && granularity == TimelineByExpense {
+			date = expenses[added].Date
+			expenseID = expenses[added].ID
+			totalExp, _ := s.ExpenseTotal(expenses[added])
+			totalAmount = s.RoundToTwo(totalAmount + totalExp)
+			added++
+		} else {
+			// Punkt powstaje po ostatnim wydatku lub spłacie danego dnia
+			if byExpense {
+				date = expenses[added].Date
+			} else {
+				date = repayments[repaid].Date
+			}
+			for added < len(expenses) && !expenses[added].Date.After(date) {
+				totalExp, _ := s.ExpenseTotal(expenses[added])
+				totalAmount = s.RoundToTwo(totalAmount + totalExp)
+				added++
+			}
+		}
+		for repaid < len(repayments) && !repayments[repaid].Date.After(date) {
+			repaid++
+		}
+
+		partial.Expenses = expenses[:added]
+		partial.Repayments = repayments[:repaid]
+		timeline = append(timeline, model.TimelinePoint{
+			Date:        date,
+			ExpenseID:   expenseID,
+			TotalAmount: totalAmount,
+			Balances:    s.CalculateBalances(&partial),
+		})
+	}
+
+	return timeline, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// ValidateEvent sprawdza spójność danych wydarzenia przed zapisem
+func (s *ExpenseService) ValidateEvent(event *model.Event) error {
+	if err := s.ValidateCategories(event.Categories); err != nil {
+		return err
+	}
+
+	if err := s.validateParticipants(event); err != nil {
+		return err
+	}
+
+	if err := s.validatePresenceWindows(event); err != nil {
+		return err
+	}
+
+	if err := s.validateHouseholds(event); err != nil {
+		return err
+	}
+
+	if event.ReminderDays < 0 {
+		return errors.New("reminder days must not be negative")
+	}
+
+	// Walidacja dat wydarzenia
+	if !event.StartDate.IsZero() && !event.EndDate.IsZero() && event.EndDate.Before(event.StartDate) {
+		return errors.New("event end date must not be before start date")
+	}
+
+	// Załączniki, znaczniki czasu i powiadomienia o zmianach dopasowywane są po ID wydatku
+	expenseIDs := make(map[int]bool, len(event.Expenses))
+	for _, exp := range event.Expenses {
+		if exp.ID <= 0 {
+			return fmt.Errorf("expense ID must be positive, got %d", exp.ID)
+		}
+		if expenseIDs[exp.ID] {
+			return fmt.Errorf("duplicate expense ID %d", exp.ID)
+		}
+		expenseIDs[exp.ID] = true
+
+		if err := s.validateExpense(event, exp); err != nil {
+			return fmt.Errorf("expense %d: %w", exp.ID, err)
+		}
+	}
+
+	if err := s.validateRecurring(event); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PreserveServerState przenosi do nowej wersji wydarzenia pola zarządzane przez serwer
+// (stan wydatków cyklicznych, zamknięte okresy, spłaty, rozliczenie, archiwizacja i kosz).
+// Dla nowego wydarzenia (previous == nil) pola te są zerowane.
+func (s *ExpenseService) PreserveServerState(previous, event *model.Event) error {
+	s.PreserveRecurringState(previous, event)
+
+	event.SettledAt, event.ArchivedAt, event.DeletedAt = time.Time{}, time.Time{}, time.Time{}
+	event.Repayments = nil
+	if previous == nil {
+		event.Version = 0
+	} else {
+		// Brak wersji w zapytaniu oznacza nadpisanie bieżącej wersji
+		if event.Version == 0 {
+			event.Version = previous.Version
+		}
+		event.Repayments = previous.Repayments
+		event.SettledAt = previous.SettledAt
+		event.ArchivedAt = previous.ArchivedAt
+		event.DeletedAt = previous.DeletedAt
+	}
+
+	return s.PreservePeriods(previous, event)
+}
+
+// validateExpense sprawdza spójność pojedynczego wydatku
+func (s *ExpenseService) validateExpense(event *model.Event, exp model.Expense) error {
+	switch exp.Kind {
+	case "", model.ExpenseKindExpense:
+	case model.ExpenseKindRefund:
+		if err := s.validateRefund(event, exp); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown expense kind %q", exp.Kind)
+	}
+
+	if err := s.validateItems(event, exp); err != nil {
+		return err
+	}
+
+	if err := s.validateReferences(event, exp); err != nil {
+		return err
+	}
+
+	if exp.TotalAmount < 0 {
+		return errors.New("total amount must not be negative, use kind \"refund\" instead")
+	}
+	for _, payment := range exp.Payments {
+		if payment.Amount < 0 {
+			return fmt.Errorf("payment of participant %d must not be negative", payment.ParticipantID)
+		}
+	}
+
+	if exp.Date.IsZero() {
+		return nil
+	}
+	if err := s.validatePresence(event, exp); err != nil {
+		return err
+	}
+	if !event.StartDate.IsZero() && exp.Date.Before(event.StartDate) {
+		return errors.New("date is before event start date")
+	}
+	if !event.EndDate.IsZero() && exp.Date.After(event.EndDate) {
+		return errors.New("date is after event end date")
+	}
+	return nil
+}
+
+// validateParticipants sprawdza unikalność identyfikatorów uczestników
+func (s *ExpenseService) validateParticipants(event *model.Event) error {
+	seen := make(map[int]bool, len(event.Participants))
+	for _, p := range event.Participants {
+		if seen[p.ID] {
+			return fmt.Errorf("duplicate participant ID %d", p.ID)
+		}
+		seen[p.ID] = true
+
+		if err := s.validatePayoutDetails(p); err != nil {
+			return err
+		}
+		if p.Locale != "" {
+			if _, err := ParseLocale(p.Locale); err != nil {
+				return fmt.Errorf("participant %d: %w", p.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateReferences sprawdza czy płatności i podział wydatku wskazują istniejących uczestników
+func (s *ExpenseService) validateReferences(event *model.Event, exp model.Expense) error {
+	participants := make(map[int]bool, len(event.Participants))
+	for _, p := range event.Participants {
+		participants[p.ID] = true
+	}
+
+	for _, payment := range exp.Payments {
+		if !participants[payment.ParticipantID] {
+			return fmt.Errorf("payment references unknown participant %d", payment.ParticipantID)
+		}
+	}
+	for _, id := range exp.SharedWith {
+		if !participants[id] {
+			return fmt.Errorf("sharedWith references unknown participant %d", id)
+		}
+	}
+	return nil
+}
+
+// validateRefund sprawdza czy zwrot wskazuje odbiorców pieniędzy oraz osoby, między które zostanie rozdzielony
+func (s *ExpenseService) validateRefund(event *model.Event, exp model.Expense) error {
+	if len(exp.Payments) == 0 {
+		return errors.New("refund must specify who received the money")
+	}
+	if len(s.EffectiveSharedWith(event, exp)) == 0 {
+		return errors.New("refund must specify who it is shared with")
+	}
+
+	_, received := s.ExpenseTotal(exp)
+	if exp.TotalAmount != 0 && s.RoundToTwo(exp.TotalAmount+received) != 0 {
+		return errors.New("refund total amount must equal the sum of received amounts")
+	}
+	return nil
+}
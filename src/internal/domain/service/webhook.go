@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// WebhookChangeTypes to rodzaje zmian, które można subskrybować
+var WebhookChangeTypes = []string{
+	model.ChangeEventCreated,
+	model.ChangeEventUpdated,
+	model.ChangeEventDeleted,
+	model.ChangeEventRestored,
+	model.ChangeEventPurged,
+	model.ChangeEventArchived,
+	model.ChangeEventUnarchived,
+	model.ChangeEventSettled,
+	model.ChangePeriodClosed,
+	model.ChangeParticipantRemoved,
+	model.ChangeParticipantsMerged,
+	model.ChangeRepaymentsAdded,
+	model.ChangeExpenseCreated,
+	model.ChangeExpenseUpdated,
+	model.ChangeExpenseDeleted,
+}
+
+// ValidateWebhook sprawdza adres subskrypcji oraz subskrybowane rodzaje zmian.
+// Rodzaj może kończyć się znakiem "*" (np. expense.*), jeśli pasuje do znanego rodzaju.
+func (s *ExpenseService) ValidateWebhook(subscription *model.WebhookSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if subscription.EventID < 0 {
+		return errors.New("event ID must not be negative")
+	}
+
+	for _, pattern := range subscription.Types {
+		if !knownChangePattern(pattern) {
+			return fmt.Errorf("unknown change type %q", pattern)
+		}
+	}
+	return nil
+}
+
+// knownChangePattern sprawdza czy rodzaj zmiany lub wzorzec pasuje do któregoś ze znanych rodzajów
+func knownChangePattern(pattern string) bool {
+	prefix, wildcard := strings.CutSuffix(pattern, "*")
+	for _, changeType := range WebhookChangeTypes {
+		if changeType == pattern || (wildcard && strings.HasPrefix(changeType, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpenseChanges zwraca zmiany poszczególnych wydatków między dwiema wersjami wydarzenia
+// (dodane, zmienione i usunięte wydatki). Znaczniki czasu wydatków są pomijane przy porównaniu.
+func (s *ExpenseService) ExpenseChanges(previous, event *model.Event) []model.EventChange {
+	before := make(map[int]model.Expense, len(previous.Expenses))
+	for _, exp := range previous.Expenses {
+		before[exp.ID] = exp
+	}
+
+	var changes []model.EventChange
+	for _, exp := range event.Expenses {
+		old, found := before[exp.ID]
+		delete(before, exp.ID)
+
+		change := model.EventChange{Type: model.ChangeExpenseCreated, EventID: event.ID, ExpenseID: exp.ID, Expense: &exp}
+		if found {
+			if sameExpense(old, exp) {
+				continue
+			}
+			change.Type = model.ChangeExpenseUpdated
+		}
+		changes = append(changes, change)
+	}
+	for _, exp := range previous.Expenses {
+		if _, removed := before[exp.ID]; removed {
+			changes = append(changes, model.EventChange{Type: model.ChangeExpenseDeleted, EventID: event.ID, ExpenseID: exp.ID})
+		}
+	}
+
+	return changes
+}
+
+// sameExpense porównuje wydatki z pominięciem znaczników czasu. Puste listy
+// traktowane są tak samo jak brakujące.
+func sameExpense(a, b model.Expense) bool {
+	normalize := func(e model.Expense) model.Expense {
+		e.CreatedAt, e.UpdatedAt = time.Time{}, time.Time{}
+		e.Payments = nilIfEmpty(e.Payments)
+		e.SharedWith = nilIfEmpty(e.SharedWith)
+		e.Attachments = nilIfEmpty(e.Attachments)
+		e.Items = nilIfEmpty(slices.Clone(e.Items))
+		for i := range e.Items {
+			e.Items[i].Participants = nilIfEmpty(e.Items[i].Participants)
+		}
+		return e
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// nilIfEmpty zamienia pustą listę na nil
+func nilIfEmpty[T any](values []T) []T {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
+)
+
+// AttachmentHandler obsługuje zapytania HTTP związane z załącznikami do wydatków
+type AttachmentHandler struct {
+	eventRepository   repository.EventRepository
+	attachmentService *service.AttachmentService
+	expenseService    *service.ExpenseService
+	broker            pubsub.Broker
+}
+
+// NewAttachmentHandler tworzy nowy handler załączników
+func NewAttachmentHandler(
+	eventRepository repository.EventRepository,
+	attachmentService *service.AttachmentService,
+	expenseService *service.ExpenseService,
+	broker pubsub.Broker,
+) *AttachmentHandler {
+	return &AttachmentHandler{
+		eventRepository:   eventRepository,
+		attachmentService: attachmentService,
+		expenseService:    expenseService,
+		broker:            broker,
+	}
+}
+
+// UploadAttachment dodaje plik przesłany jako multipart/form-data (pole "file") do wydatku
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	eventID, expenseID, ok := parseExpenseParams(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := service.EnsureEditable(event); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	// Limit obejmuje również narzut kodowania multipart
+	r.Body = http.MaxBytesReader(w, r.Body, h.attachmentService.MaxSize()+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Attachment is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid multipart upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(r.Context(), event, expenseID, header.Filename, file)
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+
+	if err := h.eventRepository.Save(event); err != nil {
+		// Bez zapisanego wydarzenia plik nie byłby przypisany do żadnego załącznika
+		if deleteErr := h.attachmentService.DiscardUpload(r.Context(), event.ID, expenseID, attachment.ID); deleteErr != nil {
+			log.Printf("Failed to delete attachment %s after failed save: %v", attachment.ID, deleteErr)
+		}
+		writeSaveError(w, "Failed to save event", err)
+		return
+	}
+	h.publishExpenseUpdated(event, expenseID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+// GetAttachments zwraca listę załączników wydatku
+func (h *AttachmentHandler) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	eventID, expenseID, ok := parseExpenseParams(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	for _, expense := range event.Expenses {
+		if expense.ID == expenseID {
+			w.Header().Set("Content-Type", "application/json")
+			if expense.Attachments == nil {
+				w.Write([]byte("[]\n"))
+				return
+			}
+			json.NewEncoder(w).Encode(expense.Attachments)
+			return
+		}
+	}
+
+	http.Error(w, "Expense not found", http.StatusNotFound)
+}
+
+// DownloadAttachment zwraca zawartość pliku załącznika
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	eventID, expenseID, ok := parseExpenseParams(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	attachment, reader, err := h.attachmentService.Open(r.Context(), event, expenseID, mux.Vars(r)["aid"])
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+	defer reader.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := io.Copy(w, reader); err != nil {
+		log.Printf("Failed to stream attachment %s: %v", attachment.ID, err)
+	}
+}
+
+// DeleteAttachment usuwa załącznik wydatku
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	eventID, expenseID, ok := parseExpenseParams(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := service.EnsureEditable(event); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if err := h.attachmentService.Remove(r.Context(), event, expenseID, mux.Vars(r)["aid"]); err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+
+	if err := h.eventRepository.Save(event); err != nil {
+		writeSaveError(w, "Failed to save event", err)
+		return
+	}
+	h.publishExpenseUpdated(event, expenseID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// publishExpenseUpdated publikuje zmianę wydarzenia i wydatku, którego załączniki się zmieniły
+func (h *AttachmentHandler) publishExpenseUpdated(event *model.Event, expenseID int) {
+	change := model.EventChange{Type: model.ChangeExpenseUpdated, EventID: event.ID, ExpenseID: expenseID}
+	for _, exp := range event.Expenses {
+		if exp.ID == expenseID {
+			change.Expense = &exp
+			break
+		}
+	}
+
+	publishChange(h.broker, h.expenseService, model.EventChange{Type: model.ChangeEventUpdated, EventID: event.ID}, event)
+	publishChange(h.broker, h.expenseService, change, event)
+}
+
+// parseExpenseParams odczytuje ID wydarzenia i wydatku ze ścieżki, zwracając błąd 400 przy niepoprawnych wartościach
+func parseExpenseParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	eventID, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	expenseID, err := parseIDParam(r, "eid")
+	if err != nil {
+		http.Error(w, "Invalid expense ID: "+err.Error(), http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	return eventID, expenseID, true
+}
+
+// writeAttachmentError mapuje błędy usługi załączników na kody HTTP
+func writeAttachmentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrExpenseNotFound),
+		errors.Is(err, service.ErrAttachmentNotFound),
+		errors.Is(err, repository.ErrBlobNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, service.ErrUnsupportedAttachment):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	default:
+		http.Error(w, "Attachment operation failed: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/bankstatement"
+)
+
+// bankStatementResponse to wynik analizy wyciągu wraz ze spłatami zaksięgowanymi przy apply=true
+type bankStatementResponse struct {
+	*service.StatementSuggestions
+	Repayments []model.Repayment `json:"repayments"`
+}
+
+// ImportBankStatement analizuje wyciąg bankowy uczestnika (CAMT.053, MT940 lub OFX) i zwraca
+// szkice wydatków oraz dopasowane spłaty rozliczeń. Parametry: participant (wymagany), format
+// (camt053, mt940, ofx lub puste dla rozpoznania) oraz apply=true (księguje dopasowane spłaty).
+func (h *EventHandler) ImportBankStatement(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := r.URL.Query()
+	participantID, err := strconv.Atoi(params.Get("participant"))
+	if err != nil {
+		http.Error(w, "Invalid participant: expected participant ID", http.StatusBadRequest)
+		return
+	}
+
+	format := params.Get("format")
+	if format != "" && format != bankstatement.FormatCAMT053 && format != bankstatement.FormatMT940 && format != bankstatement.FormatOFX {
+		http.Error(w, "Invalid format: expected camt053, mt940 or ofx", http.StatusBadRequest)
+		return
+	}
+
+	apply := false
+	if value := params.Get("apply"); value != "" {
+		if apply, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid apply: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var file io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		part, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Invalid multipart upload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer part.Close()
+		file = part
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Bank statement is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Failed to read bank statement: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transactions, err := bankstatement.Parse(format, data)
+	if err != nil {
+		http.Error(w, "Invalid bank statement: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	suggestions, err := h.expenseService.SuggestFromStatement(event, participantID, transactions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := bankStatementResponse{StatementSuggestions: suggestions, Repayments: []model.Repayment{}}
+	if apply && len(suggestions.Matches) > 0 {
+		if err := service.EnsureEditable(event); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		response.Repayments = h.expenseService.ApplyRepayments(event, suggestions.Matches)
+		if err := h.eventRepository.Save(event); err != nil {
+			writeSaveError(w, "Failed to save event", err)
+			return
+		}
+		h.publish(model.ChangeRepaymentsAdded, id, event)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
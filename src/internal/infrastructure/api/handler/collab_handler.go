@@ -0,0 +1,457 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/websocket"
+)
+
+const (
+	// collabPingInterval to odstęp ramek ping wysyłanych do klienta
+	collabPingInterval = 30 * time.Second
+	// collabIdleTimeout to czas bez żadnej ramki od klienta, po którym połączenie jest zamykane
+	collabIdleTimeout = 75 * time.Second
+	// collabSendBuffer to liczba komunikatów oczekujących na wysłanie; klient, który
+	// nie nadąża z odbiorem, jest rozłączany
+	collabSendBuffer = 32
+	// collabMaxMessage to maksymalny rozmiar komendy od klienta
+	collabMaxMessage = 256 << 10
+)
+
+// Komendy przyjmowane w kanale współpracy
+const (
+	commandAddExpense    = "expense.add"
+	commandUpdateExpense = "expense.update"
+	commandDeleteExpense = "expense.delete"
+	commandSetPayments   = "payments.set"
+	commandPing          = "ping"
+)
+
+// Rodzaje komunikatów wysyłanych w kanale współpracy
+const (
+	messageWelcome  = "welcome"
+	messageAck      = "ack"
+	messageError    = "error"
+	messageChange   = "change"
+	messagePresence = "presence"
+	messagePong     = "pong"
+)
+
+// errInvalidCommand oznacza komendę niepoprawną składniowo lub prowadzącą do niepoprawnego wydarzenia
+var errInvalidCommand = errors.New("invalid command")
+
+// collabCommand to komenda zmiany wydarzenia wysłana przez klienta. Version to wersja
+// wydarzenia, na której klient oparł zmianę; komendy oparte na nieaktualnej wersji są odrzucane.
+type collabCommand struct {
+	ID        string          `json:"id,omitempty"`
+	Type      string          `json:"type"`
+	Version   int             `json:"version"`
+	ExpenseID int             `json:"expenseId,omitempty"`
+	Expense   *model.Expense  `json:"expense,omitempty"`
+	Payments  []model.Payment `json:"payments,omitempty"`
+}
+
+// collabMessage to komunikat wysyłany do klienta kanału współpracy
+type collabMessage struct {
+	Type      string              `json:"type"`
+	ID        string              `json:"id,omitempty"`
+	SessionID uint64              `json:"sessionId,omitempty"`
+	Version   int                 `json:"version,omitempty"`
+	ExpenseID int                 `json:"expenseId,omitempty"`
+	Code      string              `json:"code,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Event     *model.Event        `json:"event,omitempty"`
+	Summary   *model.Summary      `json:"summary,omitempty"`
+	Change    *model.EventChange  `json:"change,omitempty"`
+	Delta     *model.SummaryDelta `json:"delta,omitempty"`
+	Members   []collabMember      `json:"members,omitempty"`
+}
+
+// collabMember opisuje osobę przeglądającą wydarzenie
+type collabMember struct {
+	SessionID     uint64    `json:"sessionId"`
+	Name          string    `json:"name"`
+	ParticipantID int       `json:"participantId,omitempty"`
+	Since         time.Time `json:"since"`
+}
+
+// collabSession to pojedyncze połączenie kanału współpracy
+type collabSession struct {
+	member collabMember
+	conn   *websocket.Conn
+	send   chan collabMessage
+}
+
+// enqueue przekazuje komunikat do wysłania; przepełniona kolejka zamyka połączenie
+func (s *collabSession) enqueue(message collabMessage) {
+	select {
+	case s.send <- message:
+	default:
+		s.conn.Close()
+	}
+}
+
+// collabHub śledzi połączenia kanału współpracy poszczególnych wydarzeń i rozsyła
+// informacje o obecności
+type collabHub struct {
+	mutex    sync.Mutex
+	lastID   uint64
+	sessions map[int]map[*collabSession]struct{}
+}
+
+// newCollabHub tworzy pusty rejestr połączeń
+func newCollabHub() *collabHub {
+	return &collabHub{sessions: make(map[int]map[*collabSession]struct{})}
+}
+
+// join rejestruje połączenie, wysyła mu powitanie z listą obecnych osób
+// i powiadamia pozostałe połączenia o nowej osobie
+func (hub *collabHub) join(eventID int, session *collabSession, welcome collabMessage) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	hub.lastID++
+	session.member.SessionID = hub.lastID
+	if hub.sessions[eventID] == nil {
+		hub.sessions[eventID] = make(map[*collabSession]struct{})
+	}
+	hub.sessions[eventID][session] = struct{}{}
+
+	members := hub.membersLocked(eventID)
+	welcome.SessionID = session.member.SessionID
+	welcome.Members = members
+	session.enqueue(welcome)
+
+	for other := range hub.sessions[eventID] {
+		if other != session {
+			other.enqueue(collabMessage{Type: messagePresence, Members: members})
+		}
+	}
+}
+
+// leave wyrejestrowuje połączenie i powiadamia pozostałe połączenia
+func (hub *collabHub) leave(eventID int, session *collabSession) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	delete(hub.sessions[eventID], session)
+	if len(hub.sessions[eventID]) == 0 {
+		delete(hub.sessions, eventID)
+		return
+	}
+
+	members := hub.membersLocked(eventID)
+	for other := range hub.sessions[eventID] {
+		other.enqueue(collabMessage{Type: messagePresence, Members: members})
+	}
+}
+
+// membersLocked zwraca osoby przeglądające wydarzenie w kolejności dołączenia
+func (hub *collabHub) membersLocked(eventID int) []collabMember {
+	members := make([]collabMember, 0, len(hub.sessions[eventID]))
+	for session := range hub.sessions[eventID] {
+		members = append(members, session.member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].SessionID < members[j].SessionID })
+	return members
+}
+
+// CollaborateEvent otwiera kanał WebSocket do wspólnej edycji wydarzenia. Klient otrzymuje
+// powitanie z wydarzeniem, podsumowaniem i listą obecnych osób, może wysyłać komendy
+// zmieniające wydatki (potwierdzane komunikatem ack lub error), a każda zmiana wydarzenia
+// trafia do wszystkich połączonych osób wraz ze zmianą podsumowania.
+// Parametry name i participant opisują osobę widoczną dla pozostałych.
+func (h *EventHandler) CollaborateEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Subskrypcja przed odczytem wydarzenia, aby nie zgubić zmian zapisanych w międzyczasie
+	changes, unsubscribe := h.broker.Subscribe(id)
+	defer unsubscribe()
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	member := collabMember{Name: strings.TrimSpace(r.URL.Query().Get("name")), Since: time.Now().UTC()}
+	if value := r.URL.Query().Get("participant"); value != "" {
+		participantID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid participant: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		participant := findEventParticipant(event, participantID)
+		if participant == nil {
+			http.Error(w, fmt.Sprintf("Participant %d not found", participantID), http.StatusBadRequest)
+			return
+		}
+		member.ParticipantID = participantID
+		if member.Name == "" {
+			member.Name = participant.Name
+		}
+	}
+	if member.Name == "" {
+		http.Error(w, "Parameter name or participant is required", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.MaxMessageSize = collabMaxMessage
+	conn.IdleTimeout = collabIdleTimeout
+
+	summary := h.expenseService.CalculateSummary(event)
+	session := &collabSession{member: member, conn: conn, send: make(chan collabMessage, collabSendBuffer)}
+	h.collab.join(id, session, collabMessage{
+		Type:    messageWelcome,
+		Version: event.Version,
+		Event:   event,
+		Summary: summary,
+	})
+
+	done := make(chan struct{})
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		h.writeCollab(session, changes, summary, done)
+	}()
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+
+		var command collabCommand
+		if messageType != websocket.TextMessage {
+			session.enqueue(collabMessage{Type: messageError, Code: "invalid_command", Error: "binary messages are not supported"})
+			continue
+		}
+		if err := json.Unmarshal(data, &command); err != nil {
+			session.enqueue(collabMessage{Type: messageError, Code: "invalid_command", Error: "Invalid command: " + err.Error()})
+			continue
+		}
+		session.enqueue(h.handleCommand(id, session.member, command))
+	}
+
+	close(done)
+	h.collab.leave(id, session)
+	<-writerDone
+}
+
+// writeCollab wysyła do klienta komunikaty z kolejki połączenia, zmiany wydarzenia
+// z brokera oraz ramki ping. Zmiany zawierają różnicę względem ostatnio wysłanego podsumowania.
+func (h *EventHandler) writeCollab(session *collabSession, changes <-chan model.EventChange, summary *model.Summary, done <-chan struct{}) {
+	ping := time.NewTicker(collabPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case message := <-session.send:
+			if writeCollabMessage(session.conn, message) != nil {
+				session.conn.Close()
+				return
+			}
+		case change, ok := <-changes:
+			// Zamknięty kanał oznacza odłączenie przez broker; klient połączy się ponownie
+			if !ok {
+				session.conn.WriteClose(websocket.CloseGoingAway, "subscriber too slow")
+				session.conn.Close()
+				return
+			}
+
+			message := collabMessage{Type: messageChange, Version: change.Version}
+			if change.Summary != nil {
+				delta := h.expenseService.DiffSummaries(summary, change.Summary)
+				summary = change.Summary
+				message.Delta = &delta
+			}
+			change.Summary = nil
+			message.Change = &change
+			if writeCollabMessage(session.conn, message) != nil {
+				session.conn.Close()
+				return
+			}
+
+			if change.Type == model.ChangeEventDeleted || change.Type == model.ChangeEventPurged {
+				session.conn.WriteClose(websocket.CloseGoingAway, "event deleted")
+				session.conn.Close()
+				return
+			}
+		case <-ping.C:
+			if session.conn.WriteMessage(websocket.PingMessage, nil) != nil {
+				session.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// writeCollabMessage zapisuje komunikat jako wiadomość tekstową JSON
+func writeCollabMessage(conn *websocket.Conn, message collabMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// handleCommand wykonuje komendę klienta i zwraca potwierdzenie albo opis błędu
+func (h *EventHandler) handleCommand(eventID int, member collabMember, command collabCommand) collabMessage {
+	if command.Type == commandPing {
+		return collabMessage{Type: messagePong, ID: command.ID}
+	}
+
+	event, expenseID, err := h.applyCommand(eventID, command)
+	if err != nil {
+		message := collabMessage{Type: messageError, ID: command.ID, Code: commandErrorCode(err), Error: err.Error()}
+		if current, findErr := h.eventRepository.FindByID(eventID); findErr == nil {
+			message.Version = current.Version
+		}
+		return message
+	}
+
+	change := model.EventChange{EventID: eventID, Actor: member.Name, ActorID: member.ParticipantID, ExpenseID: expenseID}
+	switch command.Type {
+	case commandAddExpense:
+		change.Type = model.ChangeExpenseCreated
+	case commandDeleteExpense:
+		change.Type = model.ChangeExpenseDeleted
+	default:
+		change.Type = model.ChangeExpenseUpdated
+	}
+	for _, exp := range event.Expenses {
+		if exp.ID == expenseID {
+			change.Expense = &exp
+			break
+		}
+	}
+	h.publishChange(change, event)
+
+	return collabMessage{Type: messageAck, ID: command.ID, Version: event.Version, ExpenseID: expenseID}
+}
+
+// applyCommand stosuje komendę do bieżącej wersji wydarzenia i zapisuje wynik.
+// Zwraca zapisane wydarzenie oraz identyfikator zmienionego wydatku.
+func (h *EventHandler) applyCommand(eventID int, command collabCommand) (*model.Event, int, error) {
+	if command.Version <= 0 {
+		return nil, 0, fmt.Errorf("%w: version is required", errInvalidCommand)
+	}
+
+	existing, err := h.eventRepository.FindByID(eventID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := service.EnsureEditable(existing); err != nil {
+		return nil, 0, err
+	}
+	if command.Version != existing.Version {
+		return nil, 0, repository.ErrVersionConflict
+	}
+
+	// Repozytorium zwraca kopie, więc zmiany nie dotykają poprzedniej wersji
+	event, err := h.eventRepository.FindByID(eventID)
+	if err != nil {
+		return nil, 0, err
+	}
+	event.Version = command.Version
+
+	expenseID := command.ExpenseID
+	switch command.Type {
+	case commandAddExpense:
+		if command.Expense == nil {
+			return nil, 0, fmt.Errorf("%w: expense is required", errInvalidCommand)
+		}
+		expenseID = h.expenseService.AddExpense(event, *command.Expense).ID
+	case commandUpdateExpense:
+		if command.Expense == nil {
+			return nil, 0, fmt.Errorf("%w: expense is required", errInvalidCommand)
+		}
+		exp := *command.Expense
+		if exp.ID == 0 {
+			exp.ID = command.ExpenseID
+		}
+		expenseID = exp.ID
+		_, err = h.expenseService.ReplaceExpense(event, exp)
+	case commandSetPayments:
+		_, err = h.expenseService.SetPayments(event, command.ExpenseID, command.Payments)
+	case commandDeleteExpense:
+		err = h.expenseService.RemoveExpense(event, command.ExpenseID)
+	default:
+		err = fmt.Errorf("%w: unknown type %q", errInvalidCommand, command.Type)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := h.expenseService.ValidateEvent(event); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", errInvalidCommand, err)
+	}
+	h.attachmentService.PreserveAttachments(existing, event)
+	if err := h.expenseService.PreserveServerState(existing, event); err != nil {
+		return nil, 0, err
+	}
+	if err := h.eventRepository.Save(event); err != nil {
+		return nil, 0, err
+	}
+
+	// Usuwamy pliki załączników usuniętego wydatku
+	if err := h.attachmentService.DeleteOrphaned(context.Background(), existing, event); err != nil {
+		log.Printf("Failed to delete attachments of event %d: %v", eventID, err)
+	}
+
+	return event, expenseID, nil
+}
+
+// commandErrorCode zwraca kod błędu komendy przekazywany klientowi
+func commandErrorCode(err error) string {
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		return "version_conflict"
+	case errors.Is(err, service.ErrExpenseNotFound):
+		return "expense_not_found"
+	case errors.Is(err, service.ErrEventArchived):
+		return "archived"
+	case errors.Is(err, service.ErrEventSettled):
+		return "settled"
+	case errors.Is(err, service.ErrPeriodFrozen):
+		return "period_closed"
+	case errors.Is(err, errInvalidCommand):
+		return "invalid_command"
+	default:
+		return "internal"
+	}
+}
+
+// findEventParticipant zwraca uczestnika wydarzenia o podanym ID
+func findEventParticipant(event *model.Event, participantID int) *model.Participant {
+	for i := range event.Participants {
+		if event.Participants[i].ID == participantID {
+			return &event.Participants[i]
+		}
+	}
+	return nil
+}
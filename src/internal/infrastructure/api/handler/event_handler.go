@@ -2,33 +2,49 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/inflop/splitty.api/internal/domain/model"
 	"github.com/inflop/splitty.api/internal/domain/repository"
 	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
 )
 
 // EventHandler obsługuje zapytania HTTP związane z wydarzeniami
 type EventHandler struct {
-	eventRepository repository.EventRepository
-	expenseService  *service.ExpenseService
+	eventRepository    repository.EventRepository
+	templateRepository repository.TemplateRepository
+	expenseService     *service.ExpenseService
+	attachmentService  *service.AttachmentService
+	broker             pubsub.Broker
+	collab             *collabHub
 }
 
 // NewEventHandler tworzy nowy handler wydarzeń
 func NewEventHandler(
 	eventRepository repository.EventRepository,
+	templateRepository repository.TemplateRepository,
 	expenseService *service.ExpenseService,
+	attachmentService *service.AttachmentService,
+	broker pubsub.Broker,
 ) *EventHandler {
 	return &EventHandler{
-		eventRepository: eventRepository,
-		expenseService:  expenseService,
+		eventRepository:    eventRepository,
+		templateRepository: templateRepository,
+		expenseService:     expenseService,
+		attachmentService:  attachmentService,
+		broker:             broker,
+		collab:             newCollabHub(),
 	}
 }
 
-// CreateEvent tworzy nowe wydarzenie
+// CreateEvent tworzy nowe wydarzenie. Parametr template wskazuje szablon, którego
+// uczestnicy, kategorie i ustawienia uzupełniają pola pominięte w ciele zapytania.
 func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	var event model.Event
 	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
@@ -36,16 +52,43 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if value := r.URL.Query().Get("template"); value != "" {
+		templateID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid template ID: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		template, err := h.templateRepository.FindByID(templateID)
+		if err != nil {
+			http.Error(w, "Template not found: "+err.Error(), http.StatusNotFound)
+			return
+		}
+		h.expenseService.ApplyTemplate(template, &event)
+	}
+
 	// Walidacja danych
 	if event.Name == "" {
 		http.Error(w, "Event name is required", http.StatusBadRequest)
 		return
 	}
 
+	if err := h.expenseService.ValidateEvent(&event); err != nil {
+		http.Error(w, "Invalid event: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Załączniki dodawane są wyłącznie przez osobny endpoint
+	h.attachmentService.PreserveAttachments(nil, &event)
+	if err := h.expenseService.PreserveServerState(nil, &event); err != nil {
+		http.Error(w, "Invalid event: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := h.eventRepository.Save(&event); err != nil {
-		http.Error(w, "Failed to save event: "+err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, "Failed to save event", err)
 		return
 	}
+	h.publish(model.ChangeEventCreated, event.ID, &event)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -120,12 +163,17 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Sprawdzamy czy wydarzenie istnieje
-	_, err = h.eventRepository.FindByID(id)
+	existing, err := h.eventRepository.FindByID(id)
 	if err != nil {
 		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
 		return
 	}
 
+	if err := service.EnsureEditable(existing); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
 	var event model.Event
 	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
 		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
@@ -138,19 +186,40 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.expenseService.ValidateEvent(&event); err != nil {
+		http.Error(w, "Invalid event: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Ustawiamy ID z URL
 	event.ID = id
 
+	h.attachmentService.PreserveAttachments(existing, &event)
+	if err := h.expenseService.PreserveServerState(existing, &event); err != nil {
+		http.Error(w, "Invalid event: "+err.Error(), http.StatusConflict)
+		return
+	}
+
 	if err := h.eventRepository.Save(&event); err != nil {
-		http.Error(w, "Failed to update event: "+err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, "Failed to update event", err)
 		return
 	}
+	h.publish(model.ChangeEventUpdated, id, &event)
+	for _, change := range h.expenseService.ExpenseChanges(existing, &event) {
+		h.publishChange(change, &event)
+	}
+
+	// Usuwamy pliki załączników wydatków, które zostały usunięte
+	if err := h.attachmentService.DeleteOrphaned(r.Context(), existing, &event); err != nil {
+		log.Printf("Failed to delete attachments of event %d: %v", id, err)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(event)
 }
 
-// DeleteEvent usuwa wydarzenie
+// DeleteEvent przenosi wydarzenie do kosza, z którego można je przywrócić
+// do czasu trwałego usunięcia
 func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, ok := vars["id"]
@@ -165,22 +234,139 @@ func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.eventRepository.Delete(id); err != nil {
+	if err := h.eventRepository.SoftDelete(id, time.Now().UTC()); err != nil {
 		http.Error(w, "Failed to delete event: "+err.Error(), http.StatusNotFound)
 		return
 	}
+	h.publish(model.ChangeEventDeleted, id, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// GetAllEvents pobiera wszystkie wydarzenia
+// GetAllEvents pobiera wszystkie wydarzenia. Zarchiwizowane wydarzenia są pomijane, chyba
+// że parametr archived ma wartość include (wszystkie) lub only (tylko zarchiwizowane).
 func (h *EventHandler) GetAllEvents(w http.ResponseWriter, r *http.Request) {
-	events, err := h.eventRepository.FindAll()
+	archived := r.URL.Query().Get("archived")
+	if archived != "" && archived != "include" && archived != "only" {
+		http.Error(w, "Invalid archived filter: "+archived, http.StatusBadRequest)
+		return
+	}
+
+	all, err := h.eventRepository.FindAll()
 	if err != nil {
 		http.Error(w, "Failed to get events: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	events := make([]*model.Event, 0, len(all))
+	for _, event := range all {
+		isArchived := !event.ArchivedAt.IsZero()
+		if archived == "include" || isArchived == (archived == "only") {
+			events = append(events, event)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(events)
 }
+
+// GetEventCategories zwraca katalog kategorii wydarzenia wraz z kategoriami domyślnymi
+func (h *EventHandler) GetEventCategories(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.expenseService.CategoryCatalogue(event))
+}
+
+// GetEventExpenses zwraca wydatki wydarzenia z opcjonalnym filtrowaniem po dacie i sortowaniem
+func (h *EventHandler) GetEventExpenses(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var query service.ExpenseQuery
+	params := r.URL.Query()
+
+	if value := params.Get("from"); value != "" {
+		if query.From, err = model.ParseDate(value); err != nil {
+			http.Error(w, "Invalid from date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := params.Get("to"); value != "" {
+		if query.To, err = model.ParseDate(value); err != nil {
+			http.Error(w, "Invalid to date: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	query.SortBy, query.Descending, err = service.ParseExpenseSort(params.Get("sort"))
+	if err != nil {
+		http.Error(w, "Invalid sort: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.expenseService.QueryExpenses(event, query))
+}
+
+// GetEventTimeline zwraca historię skumulowanych bilansów uczestników
+func (h *EventHandler) GetEventTimeline(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	timeline, err := h.expenseService.CalculateTimeline(event, r.URL.Query().Get("granularity"))
+	if err != nil {
+		http.Error(w, "Invalid timeline request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timeline)
+}
+
+// parseIDParam odczytuje liczbowy parametr ścieżki o podanej nazwie
+func parseIDParam(r *http.Request, name string) (int, error) {
+	value, ok := mux.Vars(r)[name]
+	if !ok {
+		return 0, errors.New(name + " is required")
+	}
+	return strconv.Atoi(value)
+}
+
+// writeSaveError zapisuje odpowiedź dla błędu zapisu wydarzenia; konflikt wersji
+// (równoczesna zmiana) zwracany jest jako 409 Conflict
+func writeSaveError(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		http.Error(w, message+": "+err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/export"
+)
+
+// Typy zawartości eksportu
+const (
+	contentTypeCSV  = "text/csv; charset=utf-8"
+	contentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ExportEvent eksportuje wydarzenie do arkusza kalkulacyjnego.
+// Parametry: format (csv lub xlsx), sheet (tylko csv: expenses, balances lub settlements;
+// domyślnie expenses) oraz locale (pl lub en, domyślnie pl).
+func (h *EventHandler) ExportEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := r.URL.Query()
+	locale, err := service.ParseLocale(params.Get("locale"))
+	if err != nil {
+		http.Error(w, "Invalid locale: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := params.Get("format")
+	if format != "csv" && format != "xlsx" {
+		http.Error(w, "Invalid format: expected csv or xlsx", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	sheets := h.expenseService.ExportSheets(event, locale)
+
+	var body bytes.Buffer
+	var contentType, fileName string
+	if format == "xlsx" {
+		contentType, fileName = contentTypeXLSX, fmt.Sprintf("event-%d.xlsx", event.ID)
+		err = export.WriteXLSX(&body, sheets)
+	} else {
+		sheetID := params.Get("sheet")
+		if sheetID == "" {
+			sheetID = service.SheetExpenses
+		}
+
+		var sheet *service.ExportSheet
+		for i := range sheets {
+			if sheets[i].ID == sheetID {
+				sheet = &sheets[i]
+			}
+		}
+		if sheet == nil {
+			http.Error(w, "Invalid sheet: "+sheetID, http.StatusBadRequest)
+			return
+		}
+
+		contentType, fileName = contentTypeCSV, fmt.Sprintf("event-%d-%s.csv", event.ID, sheet.ID)
+		err = export.WriteCSV(&body, *sheet, locale)
+	}
+	if err != nil {
+		http.Error(w, "Failed to export event: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	w.Write(body.Bytes())
+}
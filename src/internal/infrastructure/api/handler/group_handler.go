@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
+)
+
+// GroupHandler obsługuje zapytania HTTP związane z grupami wydarzeń
+type GroupHandler struct {
+	groupRepository repository.GroupRepository
+	eventRepository repository.EventRepository
+	expenseService  *service.ExpenseService
+	broker          pubsub.Broker
+}
+
+// NewGroupHandler tworzy nowy handler grup
+func NewGroupHandler(
+	groupRepository repository.GroupRepository,
+	eventRepository repository.EventRepository,
+	expenseService *service.ExpenseService,
+	broker pubsub.Broker,
+) *GroupHandler {
+	return &GroupHandler{
+		groupRepository: groupRepository,
+		eventRepository: eventRepository,
+		expenseService:  expenseService,
+		broker:          broker,
+	}
+}
+
+// CreateGroup tworzy nową grupę
+func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var group model.Group
+	if !h.decodeGroup(w, r, &group) {
+		return
+	}
+
+	if err := h.groupRepository.Save(&group); err != nil {
+		http.Error(w, "Failed to save group: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(group)
+}
+
+// GetAllGroups pobiera wszystkie grupy
+func (h *GroupHandler) GetAllGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.groupRepository.FindAll()
+	if err != nil {
+		http.Error(w, "Failed to get groups: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
+
+// GetGroup pobiera grupę po ID
+func (h *GroupHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(group)
+}
+
+// UpdateGroup aktualizuje grupę
+func (h *GroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+
+	var group model.Group
+	if !h.decodeGroup(w, r, &group) {
+		return
+	}
+
+	// Ustawiamy ID z URL
+	group.ID = existing.ID
+
+	if err := h.groupRepository.Save(&group); err != nil {
+		http.Error(w, "Failed to update group: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(group)
+}
+
+// DeleteGroup usuwa grupę. Wydarzenia grupy pozostają bez zmian.
+func (h *GroupHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid group ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.groupRepository.Delete(id); err != nil {
+		http.Error(w, "Failed to delete group: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGroupSummary zwraca łączny plan rozliczeń otwartych wydarzeń grupy
+func (h *GroupHandler) GetGroupSummary(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+
+	events := h.groupEvents(group)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.expenseService.CalculateGroupSummary(group, events))
+}
+
+// SettleGroup oznacza otwarte wydarzenia grupy jako rozliczone po spłacie łącznego planu.
+// Zwraca podsumowanie, które zostało rozliczone.
+func (h *GroupHandler) SettleGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.findGroup(w, r)
+	if !ok {
+		return
+	}
+
+	events := h.groupEvents(group)
+
+	summary := h.expenseService.CalculateGroupSummary(group, events)
+	settled, err := h.expenseService.SettleGroup(group, events, time.Now().UTC())
+	if errors.Is(err, service.ErrNothingToSettle) || errors.Is(err, service.ErrEventArchived) || errors.Is(err, service.ErrEventSettled) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to settle group: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.expenseService.SaveSettledEvents(h.eventRepository, settled); err != nil {
+		if errors.Is(err, service.ErrEventArchived) || errors.Is(err, service.ErrEventSettled) {
+			http.Error(w, "Failed to save events: "+err.Error(), http.StatusConflict)
+			return
+		}
+		writeSaveError(w, "Failed to save events", err)
+		return
+	}
+	for _, event := range settled {
+		publishChange(h.broker, h.expenseService, model.EventChange{Type: model.ChangeEventSettled, EventID: event.ID}, event)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// decodeGroup odczytuje i waliduje grupę z ciała zapytania, sprawdzając istnienie jej wydarzeń
+func (h *GroupHandler) decodeGroup(w http.ResponseWriter, r *http.Request, group *model.Group) bool {
+	if err := json.NewDecoder(r.Body).Decode(group); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if err := h.expenseService.ValidateGroup(group); err != nil {
+		http.Error(w, "Invalid group: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	for _, id := range group.EventIDs {
+		if _, err := h.eventRepository.FindByID(id); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid group: event %d not found", id), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	return true
+}
+
+// findGroup pobiera grupę o ID ze ścieżki, zwracając błąd 400 lub 404
+func (h *GroupHandler) findGroup(w http.ResponseWriter, r *http.Request) (*model.Group, bool) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid group ID: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	group, err := h.groupRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Group not found: "+err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+
+	return group, true
+}
+
+// groupEvents pobiera istniejące wydarzenia grupy. Usunięte wydarzenia są pomijane.
+func (h *GroupHandler) groupEvents(group *model.Group) []*model.Event {
+	events := make([]*model.Event, 0, len(group.EventIDs))
+	for _, id := range group.EventIDs {
+		event, err := h.eventRepository.FindByID(id)
+		if err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
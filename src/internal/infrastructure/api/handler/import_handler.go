@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/infrastructure/importer"
+)
+
+// maxImportSize to maksymalny rozmiar importowanego pliku
+const maxImportSize = 10 << 20
+
+// ImportEvent tworzy wydarzenie z eksportu Splitwise lub Tricount. Plik przesyłany jest
+// jako ciało zapytania lub pole "file" formularza multipart. Parametry: format (splitwise,
+// tricount lub puste dla rozpoznania), name oraz dryRun=true (podgląd bez zapisu).
+func (h *EventHandler) ImportEvent(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	format := params.Get("format")
+	if format != "" && format != importer.FormatSplitwise && format != importer.FormatTricount {
+		http.Error(w, "Invalid format: expected splitwise or tricount", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := false
+	if value := params.Get("dryRun"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid dryRun: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var file io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		part, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Invalid multipart upload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer part.Close()
+		file = part
+	}
+
+	result, err := importer.NewImporter(h.expenseService).Import(format, params.Get("name"), file)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Import file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid import file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.expenseService.ValidateEvent(result.Event); err != nil {
+		http.Error(w, "Imported event is invalid: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	status := http.StatusOK
+	if !dryRun {
+		if err := h.eventRepository.Save(result.Event); err != nil {
+			writeSaveError(w, "Failed to save event", err)
+			return
+		}
+		h.publish(model.ChangeEventCreated, result.Event.ID, result.Event)
+		status = http.StatusCreated
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// mergeParticipantsRequest to treść żądania połączenia zduplikowanych uczestników
+type mergeParticipantsRequest struct {
+	SourceID int `json:"sourceId"`
+	TargetID int `json:"targetId"`
+}
+
+// RemoveParticipant usuwa uczestnika z wydarzenia.
+// Parametr mode: refuse (domyślnie), reassign (wymaga parametru to) lub redistribute.
+func (h *EventHandler) RemoveParticipant(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	participantID, err := parseIDParam(r, "pid")
+	if err != nil {
+		http.Error(w, "Invalid participant ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	targetID := 0
+	if value := r.URL.Query().Get("to"); value != "" {
+		if targetID, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid target participant ID: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := service.EnsureEditable(event); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if err := h.expenseService.RemoveParticipant(event, participantID, r.URL.Query().Get("mode"), targetID); err != nil {
+		writeParticipantError(w, err)
+		return
+	}
+
+	if err := h.eventRepository.Save(event); err != nil {
+		writeSaveError(w, "Failed to save event", err)
+		return
+	}
+	h.publish(model.ChangeParticipantRemoved, id, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// MergeParticipants łączy zduplikowanych uczestników wydarzenia
+func (h *EventHandler) MergeParticipants(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request mergeParticipantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := service.EnsureEditable(event); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if err := h.expenseService.MergeParticipants(event, request.SourceID, request.TargetID); err != nil {
+		writeParticipantError(w, err)
+		return
+	}
+
+	if err := h.eventRepository.Save(event); err != nil {
+		writeSaveError(w, "Failed to save event", err)
+		return
+	}
+	h.publish(model.ChangeParticipantsMerged, id, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// writeParticipantError mapuje błędy operacji na uczestnikach na kody HTTP
+func writeParticipantError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrParticipantNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrParticipantReferenced), errors.Is(err, service.ErrPeriodFrozen):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
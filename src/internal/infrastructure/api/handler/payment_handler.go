@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/qrcode"
+)
+
+// Rozmiar modułu kodu QR w pikselach (domyślny i maksymalny)
+const (
+	defaultQRScale = 8
+	maxQRScale     = 32
+)
+
+// GetSettlementPayment zwraca dane przelewu spłacającego rozliczenie od uczestnika {from}
+// do uczestnika {to}, w tym treść kodu QR EPC069-12
+func (h *EventHandler) GetSettlementPayment(w http.ResponseWriter, r *http.Request) {
+	payment, ok := h.settlementPayment(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+// GetSettlementQR zwraca kod QR EPC069-12 rozliczenia jako obraz.
+// Parametry: format (png lub svg, domyślnie png) oraz scale (rozmiar modułu w pikselach).
+func (h *EventHandler) GetSettlementQR(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	format := params.Get("format")
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "svg" {
+		http.Error(w, "Invalid format: expected png or svg", http.StatusBadRequest)
+		return
+	}
+
+	scale := defaultQRScale
+	if value := params.Get("scale"); value != "" {
+		var err error
+		if scale, err = strconv.Atoi(value); err != nil || scale < 1 || scale > maxQRScale {
+			http.Error(w, "Invalid scale: expected 1-"+strconv.Itoa(maxQRScale), http.StatusBadRequest)
+			return
+		}
+	}
+
+	payment, ok := h.settlementPayment(w, r)
+	if !ok {
+		return
+	}
+	if err := service.EnsureEPC(payment); err != nil {
+		http.Error(w, "Cannot create QR code: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// EPC069-12 wymaga poziomu korekcji błędów M
+	code, err := qrcode.Encode([]byte(payment.EPC), qrcode.LevelM)
+	if err != nil {
+		http.Error(w, "Failed to create QR code: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var body bytes.Buffer
+	contentType := "image/png"
+	if format == "svg" {
+		contentType = "image/svg+xml"
+		err = qrcode.WriteSVG(&body, code, scale)
+	} else {
+		err = qrcode.WritePNG(&body, code, scale)
+	}
+	if err != nil {
+		http.Error(w, "Failed to render QR code: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body.Bytes())
+}
+
+// settlementPayment wczytuje wydarzenie i wyznacza dane przelewu dla rozliczenia z adresu;
+// w razie błędu zapisuje odpowiedź i zwraca false
+func (h *EventHandler) settlementPayment(w http.ResponseWriter, r *http.Request) (*model.PaymentRequest, bool) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	from, err := parseIDParam(r, "from")
+	if err != nil {
+		http.Error(w, "Invalid payer ID: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	to, err := parseIDParam(r, "to")
+	if err != nil {
+		http.Error(w, "Invalid payee ID: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+
+	payment, err := h.expenseService.SettlementPayment(event, from, to)
+	switch {
+	case errors.Is(err, service.ErrSettlementNotFound), errors.Is(err, service.ErrParticipantNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return nil, false
+	}
+	return payment, true
+}
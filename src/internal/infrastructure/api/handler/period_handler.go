@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// closePeriodRequest to treść żądania zamknięcia okresu rozliczeniowego
+type closePeriodRequest struct {
+	EndDate model.Date `json:"endDate"`
+}
+
+// ClosePeriod zamyka bieżący okres rozliczeniowy wydarzenia
+func (h *EventHandler) ClosePeriod(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request closePeriodRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := service.EnsureEditable(event); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	period, err := h.expenseService.ClosePeriod(event, request.EndDate, time.Now())
+	if err != nil {
+		http.Error(w, "Cannot close period: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.eventRepository.Save(event); err != nil {
+		writeSaveError(w, "Failed to save event", err)
+		return
+	}
+	h.publish(model.ChangePeriodClosed, id, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(period)
+}
+
+// GetPeriods zwraca zamknięte okresy rozliczeniowe wydarzenia wraz z ich rozliczeniami
+func (h *EventHandler) GetPeriods(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	periods := event.Periods
+	if periods == nil {
+		periods = []model.Period{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(periods)
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// GetPersonBalances zwraca łączny bilans osoby (e-mail lub ID konta) we wszystkich nierozliczonych wydarzeniach
+func (h *EventHandler) GetPersonBalances(w http.ResponseWriter, r *http.Request) {
+	personID := mux.Vars(r)["id"]
+	if personID == "" {
+		http.Error(w, "Person ID is required", http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.eventRepository.FindAll()
+	if err != nil {
+		http.Error(w, "Failed to get events: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	balances, err := h.expenseService.CalculatePersonBalances(events, service.NormalizePersonID(personID))
+	if errors.Is(err, service.ErrPersonNotFound) {
+		http.Error(w, "Person not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to calculate balances: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
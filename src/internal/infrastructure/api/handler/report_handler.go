@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/export"
+)
+
+// contentTypePDF to typ zawartości raportu PDF
+const contentTypePDF = "application/pdf"
+
+// GetEventReport generuje raport rozliczenia wydarzenia w formacie PDF.
+// Parametry: locale (pl lub en, domyślnie pl) oraz download=true (pobranie jako załącznik).
+func (h *EventHandler) GetEventReport(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := r.URL.Query()
+	locale, err := service.ParseLocale(params.Get("locale"))
+	if err != nil {
+		http.Error(w, "Invalid locale: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := export.WritePDF(&body, h.expenseService.BuildReport(event, locale, time.Now())); err != nil {
+		http.Error(w, "Failed to generate report: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	disposition := "inline"
+	if params.Get("download") == "true" {
+		disposition = "attachment"
+	}
+	fileName := fmt.Sprintf("event-%d-report.pdf", event.ID)
+
+	w.Header().Set("Content-Type", contentTypePDF)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": fileName}))
+	w.Write(body.Bytes())
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
+)
+
+// streamHeartbeat to odstęp komentarzy podtrzymujących połączenie SSE przez serwery pośredniczące
+const streamHeartbeat = 25 * time.Second
+
+// streamRetry to czas (w milisekundach), po którym przeglądarka wznawia zerwane połączenie
+const streamRetry = 3000
+
+// StreamEvent przesyła zmiany wydarzenia jako Server-Sent Events. Pierwszy komunikat
+// (snapshot) zawiera bieżące podsumowanie, kolejne nazwane są rodzajem zmiany
+// (np. event.updated) i zawierają przeliczone podsumowanie.
+func (h *EventHandler) StreamEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Subskrypcja przed odczytem wydarzenia, aby nie zgubić zmian zapisanych w międzyczasie
+	changes, unsubscribe := h.broker.Subscribe(id)
+	defer unsubscribe()
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Strumień działa dłużej niż limit czasu zapisu serwera
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	snapshot := model.EventChange{
+		Type:    model.ChangeSnapshot,
+		EventID: id,
+		At:      time.Now().UTC(),
+		Summary: h.expenseService.CalculateSummary(event),
+	}
+	if writeServerEvent(w, snapshot) != nil || controller.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case change, ok := <-changes:
+			// Zamknięty kanał oznacza odłączenie przez broker; klient połączy się ponownie
+			if !ok || writeServerEvent(w, change) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if controller.Flush() != nil {
+			return
+		}
+	}
+}
+
+// writeServerEvent zapisuje zmianę jako komunikat SSE z identyfikatorem i nazwą zdarzenia
+func writeServerEvent(w io.Writer, change model.EventChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	if change.ID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", change.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Type, data)
+	return err
+}
+
+// publish powiadamia subskrybentów o zmianie wydarzenia wraz z przeliczonym podsumowaniem;
+// event jest pusty dla wydarzeń usuniętych
+func (h *EventHandler) publish(changeType string, eventID int, event *model.Event) {
+	h.publishChange(model.EventChange{Type: changeType, EventID: eventID}, event)
+}
+
+// publishChange uzupełnia zmianę o czas, wersję i podsumowanie wydarzenia
+// i przekazuje ją subskrybentom
+func (h *EventHandler) publishChange(change model.EventChange, event *model.Event) {
+	publishChange(h.broker, h.expenseService, change, event)
+}
+
+// publishChange uzupełnia zmianę o czas, wersję i podsumowanie wydarzenia i publikuje ją w brokerze
+func publishChange(broker pubsub.Broker, expenseService *service.ExpenseService, change model.EventChange, event *model.Event) {
+	change.At = time.Now().UTC()
+	if event != nil {
+		change.Version = event.Version
+		change.Summary = expenseService.CalculateSummary(event)
+	}
+	broker.Publish(change)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// CloneEvent tworzy kopię wydarzenia z tymi samymi uczestnikami, kategoriami, wydatkami
+// cyklicznymi i ustawieniami. Ciało zapytania jest opcjonalne: {"name": "...", "includeExpenses": true}.
+func (h *EventHandler) CloneEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var options service.CloneOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	source, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	event := h.expenseService.CloneEvent(source, options, time.Now())
+	if err := h.expenseService.ValidateEvent(event); err != nil {
+		http.Error(w, "Invalid event: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.eventRepository.Save(event); err != nil {
+		writeSaveError(w, "Failed to save event", err)
+		return
+	}
+	h.publish(model.ChangeEventCreated, event.ID, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(event)
+}
+
+// SaveEventAsTemplate zapisuje uczestników, kategorie i ustawienia wydarzenia jako nazwany szablon
+func (h *EventHandler) SaveEventAsTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	template := h.expenseService.TemplateFromEvent(request.Name, event)
+	h.saveTemplate(w, template)
+}
+
+// CreateTemplate tworzy nowy szablon wydarzenia
+func (h *EventHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var template model.EventTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template.ID = 0
+	h.saveTemplate(w, &template)
+}
+
+// GetAllTemplates pobiera wszystkie szablony wydarzeń
+func (h *EventHandler) GetAllTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.templateRepository.FindAll()
+	if err != nil {
+		http.Error(w, "Failed to get templates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// GetTemplate pobiera szablon po ID
+func (h *EventHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid template ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.templateRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Template not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+// DeleteTemplate usuwa szablon. Wydarzenia utworzone z szablonu pozostają bez zmian.
+func (h *EventHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid template ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.templateRepository.Delete(id); err != nil {
+		http.Error(w, "Failed to delete template: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// saveTemplate waliduje i zapisuje szablon, odpowiadając kodem 201
+func (h *EventHandler) saveTemplate(w http.ResponseWriter, template *model.EventTemplate) {
+	if err := h.expenseService.ValidateTemplate(template); err != nil {
+		http.Error(w, "Invalid template: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.templateRepository.Save(template); err != nil {
+		http.Error(w, "Failed to save template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
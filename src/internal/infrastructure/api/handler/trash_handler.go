@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// ArchiveEvent archiwizuje wydarzenie, ukrywając je z domyślnej listy i blokując zmiany
+func (h *EventHandler) ArchiveEvent(w http.ResponseWriter, r *http.Request) {
+	h.changeArchived(w, r, model.ChangeEventArchived, func(event *model.Event) {
+		h.expenseService.ArchiveEvent(event, time.Now())
+	})
+}
+
+// UnarchiveEvent przywraca zarchiwizowane wydarzenie do użytku
+func (h *EventHandler) UnarchiveEvent(w http.ResponseWriter, r *http.Request) {
+	h.changeArchived(w, r, model.ChangeEventUnarchived, h.expenseService.UnarchiveEvent)
+}
+
+// GetTrash zwraca wydarzenia znajdujące się w koszu, od ostatnio usuniętego
+func (h *EventHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	events, err := h.eventRepository.FindDeleted()
+	if err != nil {
+		http.Error(w, "Failed to get trash: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].DeletedAt.After(events[j].DeletedAt) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// RestoreEvent przywraca wydarzenie z kosza
+func (h *EventHandler) RestoreEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.eventRepository.Restore(id); err != nil {
+		http.Error(w, "Failed to restore event: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	h.publish(model.ChangeEventRestored, id, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// PurgeEvent trwale usuwa wydarzenie z kosza wraz z plikami załączników
+func (h *EventHandler) PurgeEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.eventRepository.FindDeleted()
+	if err != nil {
+		http.Error(w, "Failed to get trash: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, event := range deleted {
+		if event.ID != id {
+			continue
+		}
+
+		if err := h.eventRepository.Delete(id); err != nil {
+			http.Error(w, "Failed to purge event: "+err.Error(), http.StatusNotFound)
+			return
+		}
+		if err := h.attachmentService.DeleteOrphaned(r.Context(), event, nil); err != nil {
+			log.Printf("Failed to delete attachments of event %d: %v", id, err)
+		}
+		h.publish(model.ChangeEventPurged, id, nil)
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	http.Error(w, "Event not found in trash", http.StatusNotFound)
+}
+
+// changeArchived wczytuje wydarzenie, zmienia jego stan archiwizacji i zapisuje je
+func (h *EventHandler) changeArchived(w http.ResponseWriter, r *http.Request, changeType string, change func(event *model.Event)) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.eventRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Event not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	change(event)
+
+	if err := h.eventRepository.Save(event); err != nil {
+		writeSaveError(w, "Failed to save event", err)
+		return
+	}
+	h.publish(changeType, id, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// WebhookHandler obsługuje zapytania HTTP związane z subskrypcjami webhooków
+type WebhookHandler struct {
+	webhookRepository repository.WebhookRepository
+	eventRepository   repository.EventRepository
+	expenseService    *service.ExpenseService
+}
+
+// NewWebhookHandler tworzy nowy handler webhooków
+func NewWebhookHandler(
+	webhookRepository repository.WebhookRepository,
+	eventRepository repository.EventRepository,
+	expenseService *service.ExpenseService,
+) *WebhookHandler {
+	return &WebhookHandler{
+		webhookRepository: webhookRepository,
+		eventRepository:   eventRepository,
+		expenseService:    expenseService,
+	}
+}
+
+// CreateWebhook tworzy subskrypcję webhooka. Subskrypcja bez eventId obejmuje wszystkie
+// wydarzenia. Jeśli sekret nie zostanie podany, jest generowany; sekret zwracany jest
+// wyłącznie w odpowiedzi na to zapytanie.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var subscription model.WebhookSubscription
+	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	subscription.ID = 0
+
+	h.saveWebhook(w, &subscription)
+}
+
+// CreateEventWebhook tworzy subskrypcję webhooka dla wydarzenia ze ścieżki
+func (h *WebhookHandler) CreateEventWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var subscription model.WebhookSubscription
+	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	subscription.ID = 0
+	subscription.EventID = id
+
+	h.saveWebhook(w, &subscription)
+}
+
+// GetAllWebhooks zwraca subskrypcje webhooków. Parametr event ogranicza listę
+// do subskrypcji wydarzenia (event=0 zwraca subskrypcje globalne).
+func (h *WebhookHandler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	eventID := -1
+	if value := r.URL.Query().Get("event"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		eventID = id
+	}
+
+	h.writeWebhooks(w, eventID)
+}
+
+// GetEventWebhooks zwraca subskrypcje webhooków wydarzenia ze ścieżki
+func (h *WebhookHandler) GetEventWebhooks(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid event ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.writeWebhooks(w, id)
+}
+
+// GetWebhook pobiera subskrypcję webhooka po ID
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	subscription.Secret = ""
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// DeleteWebhook usuwa subskrypcję webhooka wraz z dziennikiem doręczeń
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid webhook ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.webhookRepository.Delete(id); err != nil {
+		http.Error(w, "Failed to delete webhook: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries zwraca dziennik prób doręczenia powiadomień od najnowszej
+func (h *WebhookHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookRepository.FindDeliveries(subscription.ID)
+	if err != nil {
+		http.Error(w, "Failed to get deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// saveWebhook waliduje i zapisuje nową subskrypcję, generując brakujący sekret
+func (h *WebhookHandler) saveWebhook(w http.ResponseWriter, subscription *model.WebhookSubscription) {
+	if err := h.expenseService.ValidateWebhook(subscription); err != nil {
+		http.Error(w, "Invalid webhook: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if subscription.EventID != 0 {
+		if _, err := h.eventRepository.FindByID(subscription.EventID); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid webhook: event %d not found", subscription.EventID), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 24)
+		if _, err := rand.Read(secret); err != nil {
+			http.Error(w, "Failed to generate secret: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+
+	if err := h.webhookRepository.Save(subscription); err != nil {
+		http.Error(w, "Failed to save webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// writeWebhooks zapisuje listę subskrypcji bez sekretów; eventID -1 oznacza wszystkie subskrypcje
+func (h *WebhookHandler) writeWebhooks(w http.ResponseWriter, eventID int) {
+	all, err := h.webhookRepository.FindAll()
+	if err != nil {
+		http.Error(w, "Failed to get webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	subscriptions := make([]*model.WebhookSubscription, 0, len(all))
+	for _, subscription := range all {
+		if eventID >= 0 && subscription.EventID != eventID {
+			continue
+		}
+		subscription.Secret = ""
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subscriptions)
+}
+
+// findWebhook pobiera subskrypcję o ID ze ścieżki, zwracając błąd 400 lub 404
+func (h *WebhookHandler) findWebhook(w http.ResponseWriter, r *http.Request) (*model.WebhookSubscription, bool) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid webhook ID: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	subscription, err := h.webhookRepository.FindByID(id)
+	if err != nil {
+		http.Error(w, "Webhook not found: "+err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+
+	return subscription, true
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// schemaRegistry tworzy schematy JSON Schema (w wersji używanej przez OpenAPI 3.1)
+// na podstawie typów Go i ich znaczników json. Nazwane struktury trafiają do
+// components/schemas i są wskazywane przez $ref.
+type schemaRegistry struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+// newSchemaRegistry tworzy pusty rejestr schematów
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: make(map[string]any), names: make(map[reflect.Type]string)}
+}
+
+// Typy o własnej serializacji JSON
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	dateType = reflect.TypeOf(model.Date{})
+)
+
+// schema zwraca schemat typu Go
+func (r *schemaRegistry) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case dateType:
+		return map[string]any{"type": "string", "format": "date", "examples": []string{"2025-06-01"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return r.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": r.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": r.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + r.register(t)}
+	default:
+		return map[string]any{}
+	}
+}
+
+// register dodaje nazwaną strukturę do komponentów i zwraca nazwę jej schematu
+func (r *schemaRegistry) register(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	// Typy spoza warstwy domeny (np. importer.Result) otrzymują przedrostek pakietu,
+	// podobnie jak typy o nazwie zajętej przez inny pakiet
+	name := t.Name()
+	if _, taken := r.schemas[name]; taken || !strings.Contains(t.PkgPath(), "/internal/domain/") {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	// Rejestracja przed opisem pól obsługuje typy rekurencyjne
+	r.names[t] = name
+	r.schemas[name] = nil
+	r.schemas[name] = r.object(t)
+	return name
+}
+
+// object opisuje strukturę jako obiekt z właściwościami odpowiadającymi polom JSON
+func (r *schemaRegistry) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	r.addFields(t, properties)
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+// addFields dodaje właściwości pól struktury, spłaszczając pola osadzone tak jak encoding/json
+func (r *schemaRegistry) addFields(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(embedded, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = r.schema(field.Type)
+	}
+}
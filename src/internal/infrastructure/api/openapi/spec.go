@@ -0,0 +1,507 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/bankstatement"
+	"github.com/inflop/splitty.api/internal/infrastructure/importer"
+	"github.com/inflop/splitty.api/internal/infrastructure/webhook"
+)
+
+// Wersja specyfikacji OpenAPI oraz opis dokumentowanego API
+const (
+	openAPIVersion = "3.1.0"
+	apiTitle       = "Splitty API"
+	apiVersion     = "1.0.0"
+)
+
+// Grupy operacji w dokumentacji
+const (
+	tagEvents       = "Events"
+	tagExpenses     = "Expenses"
+	tagSettlements  = "Settlements"
+	tagParticipants = "Participants"
+	tagPeriods      = "Periods"
+	tagTrash        = "Trash"
+	tagTemplates    = "Templates"
+	tagPeople       = "People"
+	tagGroups       = "Groups"
+	tagWebhooks     = "Webhooks"
+	tagAttachments  = "Attachments"
+	tagTransfer     = "Import and export"
+	tagRealtime     = "Realtime"
+	tagDocs         = "Documentation"
+)
+
+// operation opisuje jedną operację API (ścieżkę wraz z metodą HTTP)
+type operation struct {
+	Method  string
+	Path    string
+	ID      string
+	Tag     string
+	Summary string
+	// PathTypes określa typy parametrów ścieżki innych niż liczby całkowite
+	PathTypes map[string]string
+	Query     []parameter
+	Request   *content
+	Responses map[int]*content
+	// Errors to kody błędów zwracanych jako text/plain przez http.Error
+	Errors []int
+}
+
+// parameter opisuje parametr zapytania
+type parameter struct {
+	Name        string
+	Type        string
+	Description string
+	Enum        []string
+	Required    bool
+}
+
+// content opisuje treść zapytania lub odpowiedzi. Schemat pochodzi z typu Go (Type)
+// albo jest podany wprost (Schema); brak obu oznacza treść binarną.
+type content struct {
+	MediaTypes  []string
+	Type        reflect.Type
+	Schema      map[string]any
+	Description string
+}
+
+// typeOf zwraca typ Go podany jako parametr
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// jsonOf opisuje treść JSON o schemacie typu T
+func jsonOf[T any]() *content {
+	return &content{MediaTypes: []string{"application/json"}, Type: typeOf[T]()}
+}
+
+// binary opisuje treść binarną w podanych formatach
+func binary(description string, mediaTypes ...string) *content {
+	return &content{MediaTypes: mediaTypes, Description: description}
+}
+
+// noContent opisuje odpowiedź bez treści
+var noContent = &content{}
+
+// Parametry zapytania powtarzające się w wielu operacjach
+var (
+	localeParam = parameter{Name: "locale", Type: "string", Enum: []string{string(service.LocalePL), string(service.LocaleEN)},
+		Description: "Language of the generated document (default pl)"}
+	fileUpload = &content{
+		MediaTypes:  []string{"multipart/form-data", "application/octet-stream"},
+		Schema:      map[string]any{"type": "object", "properties": map[string]any{"file": map[string]any{"type": "string", "contentMediaType": "application/octet-stream"}}},
+		Description: "The file as the raw request body or as the \"file\" field of a multipart form",
+	}
+)
+
+// operations opisuje wszystkie ścieżki zarejestrowane w router.SetupRoutes.
+// Zgodność obu list sprawdza test routera.
+var operations = []operation{
+	// Wydarzenia
+	{Method: "POST", Path: "/api/events", ID: "createEvent", Tag: tagEvents, Summary: "Create an event",
+		Query: []parameter{{Name: "template", Type: "integer",
+			Description: "Template whose participants, categories and settings fill the fields missing in the body"}},
+		Request: jsonOf[model.Event](), Responses: map[int]*content{201: jsonOf[model.Event]()}, Errors: []int{400, 404, 409}},
+	{Method: "GET", Path: "/api/events", ID: "listEvents", Tag: tagEvents, Summary: "List events",
+		Query: []parameter{{Name: "archived", Type: "string", Enum: []string{"include", "only"},
+			Description: "Include archived events or return only archived events (skipped by default)"}},
+		Responses: map[int]*content{200: jsonOf[[]model.Event]()}, Errors: []int{400}},
+	{Method: "GET", Path: "/api/events/{id}", ID: "getEvent", Tag: tagEvents, Summary: "Get an event",
+		Responses: map[int]*content{200: jsonOf[model.Event]()}, Errors: []int{400, 404}},
+	{Method: "PUT", Path: "/api/events/{id}", ID: "updateEvent", Tag: tagEvents,
+		Summary: "Replace an event; a stale version is rejected with 409",
+		Request: jsonOf[model.Event](), Responses: map[int]*content{200: jsonOf[model.Event]()}, Errors: []int{400, 404, 409}},
+	{Method: "DELETE", Path: "/api/events/{id}", ID: "deleteEvent", Tag: tagEvents, Summary: "Move an event to the trash",
+		Responses: map[int]*content{204: noContent}, Errors: []int{400, 404}},
+	{Method: "GET", Path: "/api/events/{id}/summary", ID: "getEventSummary", Tag: tagEvents, Summary: "Get balances and settlements of an event",
+		Responses: map[int]*content{200: jsonOf[model.Summary]()}, Errors: []int{400, 404}},
+	{Method: "GET", Path: "/api/events/{id}/timeline", ID: "getEventTimeline", Tag: tagEvents, Summary: "Get cumulative balances over time",
+		Query: []parameter{{Name: "granularity", Type: "string", Enum: []string{service.TimelineByDay, service.TimelineByExpense},
+			Description: "One point per day (default) or per expense"}},
+		Responses: map[int]*content{200: jsonOf[[]model.TimelinePoint]()}, Errors: []int{400, 404}},
+	{Method: "GET", Path: "/api/events/{id}/categories", ID: "getEventCategories", Tag: tagEvents, Summary: "Get the category catalogue of an event",
+		Responses: map[int]*content{200: jsonOf[[]model.Category]()}, Errors: []int{400, 404}},
+	{Method: "POST", Path: "/api/events/{id}/clone", ID: "cloneEvent", Tag: tagEvents, Summary: "Clone an event",
+		Request: jsonOf[service.CloneOptions](), Responses: map[int]*content{201: jsonOf[model.Event]()}, Errors: []int{400, 404, 409}},
+	{Method: "POST", Path: "/api/events/{id}/archive", ID: "archiveEvent", Tag: tagEvents, Summary: "Archive an event (read-only)",
+		Responses: map[int]*content{200: jsonOf[model.Event]()}, Errors: []int{400, 404, 409}},
+	{Method: "POST", Path: "/api/events/{id}/unarchive", ID: "unarchiveEvent", Tag: tagEvents, Summary: "Unarchive an event",
+		Responses: map[int]*content{200: jsonOf[model.Event]()}, Errors: []int{400, 404, 409}},
+
+	// Wydatki
+	{Method: "GET", Path: "/api/events/{id}/expenses", ID: "listEventExpenses", Tag: tagExpenses, Summary: "List expenses of an event",
+		Query: []parameter{
+			{Name: "from", Type: "string", Description: "First date (YYYY-MM-DD)"},
+			{Name: "to", Type: "string", Description: "Last date (YYYY-MM-DD)"},
+			{Name: "sort", Type: "string", Description: "Sort field (id, date, amount, category or createdAt); prefix with - for descending order"},
+		},
+		Responses: map[int]*content{200: jsonOf[[]model.Expense]()}, Errors: []int{400, 404}},
+
+	// Rozliczenia
+	{Method: "GET", Path: "/api/events/{id}/settlements/{from}/{to}/payment", ID: "getSettlementPayment", Tag: tagSettlements,
+		Summary:   "Get bank transfer details settling the debt of participant {from} to participant {to}",
+		Responses: map[int]*content{200: jsonOf[model.PaymentRequest]()}, Errors: []int{400, 404, 422}},
+	{Method: "GET", Path: "/api/events/{id}/settlements/{from}/{to}/qr", ID: "getSettlementQR", Tag: tagSettlements,
+		Summary: "Get the EPC069-12 QR code of a settlement",
+		Query: []parameter{
+			{Name: "format", Type: "string", Enum: []string{"png", "svg"}, Description: "Image format (default png)"},
+			{Name: "scale", Type: "integer", Description: "Module size in pixels (1-32, default 8)"},
+		},
+		Responses: map[int]*content{200: binary("QR code image", "image/png", "image/svg+xml")}, Errors: []int{400, 404, 422}},
+	{Method: "POST", Path: "/api/events/{id}/bank-statements", ID: "importBankStatement", Tag: tagSettlements,
+		Summary: "Analyse a bank statement (CAMT.053, MT940 or OFX) of a participant",
+		Query: []parameter{
+			{Name: "participant", Type: "integer", Required: true, Description: "Owner of the bank account"},
+			{Name: "format", Type: "string", Enum: []string{bankstatement.FormatCAMT053, bankstatement.FormatMT940, bankstatement.FormatOFX},
+				Description: "Statement format (detected when omitted)"},
+			{Name: "apply", Type: "boolean", Description: "Record matched repayments"},
+		},
+		Request: fileUpload,
+		Responses: map[int]*content{200: {MediaTypes: []string{"application/json"}, Schema: map[string]any{
+			"allOf": []any{
+				map[string]any{"$ref": "#/components/schemas/StatementSuggestions"},
+				map[string]any{"type": "object", "properties": map[string]any{
+					"repayments": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/Repayment"}},
+				}},
+			},
+		}}},
+		Errors: []int{400, 404, 409, 413}},
+
+	// Uczestnicy
+	{Method: "POST", Path: "/api/events/{id}/participants/merge", ID: "mergeParticipants", Tag: tagParticipants,
+		Summary: "Merge a duplicated participant into another one",
+		Request: &content{MediaTypes: []string{"application/json"}, Schema: map[string]any{"type": "object", "properties": map[string]any{
+			"sourceId": map[string]any{"type": "integer"},
+			"targetId": map[string]any{"type": "integer"},
+		}}},
+		Responses: map[int]*content{200: jsonOf[model.Event]()}, Errors: []int{400, 404, 409}},
+	{Method: "DELETE", Path: "/api/events/{id}/participants/{pid}", ID: "removeParticipant", Tag: tagParticipants,
+		Summary: "Remove a participant",
+		Query: []parameter{
+			{Name: "mode", Type: "string", Enum: []string{service.RemovalRefuse, service.RemovalReassign, service.RemovalRedistribute},
+				Description: "How to handle expenses referencing the participant (default refuse)"},
+			{Name: "to", Type: "integer", Description: "Participant receiving payments and shares in reassign mode"},
+		},
+		Responses: map[int]*content{200: jsonOf[model.Event]()}, Errors: []int{400, 404, 409}},
+
+	// Okresy rozliczeniowe
+	{Method: "GET", Path: "/api/events/{id}/periods", ID: "listPeriods", Tag: tagPeriods, Summary: "List closed settlement periods",
+		Responses: map[int]*content{200: jsonOf[[]model.Period]()}, Errors: []int{400, 404}},
+	{Method: "POST", Path: "/api/events/{id}/periods/close", ID: "closePeriod", Tag: tagPeriods, Summary: "Close the current settlement period",
+		Request: &content{MediaTypes: []string{"application/json"}, Schema: map[string]any{"type": "object", "properties": map[string]any{
+			"endDate": map[string]any{"type": "string", "format": "date"},
+		}}},
+		Responses: map[int]*content{201: jsonOf[model.Period]()}, Errors: []int{400, 404, 409}},
+
+	// Kosz
+	{Method: "GET", Path: "/api/trash", ID: "listTrash", Tag: tagTrash, Summary: "List deleted events",
+		Responses: map[int]*content{200: jsonOf[[]model.Event]()}},
+	{Method: "POST", Path: "/api/trash/{id}/restore", ID: "restoreEvent", Tag: tagTrash, Summary: "Restore a deleted event",
+		Responses: map[int]*content{200: jsonOf[model.Event]()}, Errors: []int{400, 404, 409}},
+	{Method: "DELETE", Path: "/api/trash/{id}", ID: "purgeEvent", Tag: tagTrash, Summary: "Permanently delete an event from the trash",
+		Responses: map[int]*content{204: noContent}, Errors: []int{400, 404}},
+
+	// Szablony
+	{Method: "POST", Path: "/api/events/{id}/template", ID: "saveEventAsTemplate", Tag: tagTemplates, Summary: "Save an event as a template",
+		Request: &content{MediaTypes: []string{"application/json"}, Schema: map[string]any{"type": "object", "properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+		}}},
+		Responses: map[int]*content{201: jsonOf[model.EventTemplate]()}, Errors: []int{400, 404}},
+	{Method: "POST", Path: "/api/templates", ID: "createTemplate", Tag: tagTemplates, Summary: "Create a template",
+		Request: jsonOf[model.EventTemplate](), Responses: map[int]*content{201: jsonOf[model.EventTemplate]()}, Errors: []int{400}},
+	{Method: "GET", Path: "/api/templates", ID: "listTemplates", Tag: tagTemplates, Summary: "List templates",
+		Responses: map[int]*content{200: jsonOf[[]model.EventTemplate]()}},
+	{Method: "GET", Path: "/api/templates/{id}", ID: "getTemplate", Tag: tagTemplates, Summary: "Get a template",
+		Responses: map[int]*content{200: jsonOf[model.EventTemplate]()}, Errors: []int{400, 404}},
+	{Method: "DELETE", Path: "/api/templates/{id}", ID: "deleteTemplate", Tag: tagTemplates, Summary: "Delete a template",
+		Responses: map[int]*content{204: noContent}, Errors: []int{400, 404}},
+
+	// Osoby
+	{Method: "GET", Path: "/api/people/{id}/balances", ID: "getPersonBalances", Tag: tagPeople,
+		Summary: "Get balances of a person (user ID or e-mail) across all unsettled events", PathTypes: map[string]string{"id": "string"},
+		Responses: map[int]*content{200: jsonOf[model.PersonBalances]()}, Errors: []int{400, 404}},
+
+	// Grupy
+	{Method: "POST", Path: "/api/groups", ID: "createGroup", Tag: tagGroups, Summary: "Create a group of events settled together",
+		Request: jsonOf[model.Group](), Responses: map[int]*content{201: jsonOf[model.Group]()}, Errors: []int{400}},
+	{Method: "GET", Path: "/api/groups", ID: "listGroups", Tag: tagGroups, Summary: "List groups",
+		Responses: map[int]*content{200: jsonOf[[]model.Group]()}},
+	{Method: "GET", Path: "/api/groups/{id}", ID: "getGroup", Tag: tagGroups, Summary: "Get a group",
+		Responses: map[int]*content{200: jsonOf[model.Group]()}, Errors: []int{400, 404}},
+	{Method: "PUT", Path: "/api/groups/{id}", ID: "updateGroup", Tag: tagGroups, Summary: "Replace a group",
+		Request: jsonOf[model.Group](), Responses: map[int]*content{200: jsonOf[model.Group]()}, Errors: []int{400, 404}},
+	{Method: "DELETE", Path: "/api/groups/{id}", ID: "deleteGroup", Tag: tagGroups, Summary: "Delete a group",
+		Responses: map[int]*content{204: noContent}, Errors: []int{400, 404}},
+	{Method: "GET", Path: "/api/groups/{id}/summary", ID: "getGroupSummary", Tag: tagGroups, Summary: "Get the combined settlement plan of a group",
+		Responses: map[int]*content{200: jsonOf[model.GroupSummary]()}, Errors: []int{400, 404}},
+	{Method: "POST", Path: "/api/groups/{id}/settle", ID: "settleGroup", Tag: tagGroups, Summary: "Mark open events of a group as settled",
+		Responses: map[int]*content{200: jsonOf[model.GroupSummary]()}, Errors: []int{400, 404, 409}},
+
+	// Webhooki
+	{Method: "POST", Path: "/api/webhooks", ID: "createWebhook", Tag: tagWebhooks,
+		Summary: "Subscribe to changes of all events (or of eventId); the secret is returned only here",
+		Request: jsonOf[model.WebhookSubscription](), Responses: map[int]*content{201: jsonOf[model.WebhookSubscription]()}, Errors: []int{400}},
+	{Method: "GET", Path: "/api/webhooks", ID: "listWebhooks", Tag: tagWebhooks, Summary: "List webhook subscriptions",
+		Query:     []parameter{{Name: "event", Type: "integer", Description: "Only subscriptions of this event (0 for global subscriptions)"}},
+		Responses: map[int]*content{200: jsonOf[[]model.WebhookSubscription]()}, Errors: []int{400}},
+	{Method: "GET", Path: "/api/webhooks/{id}", ID: "getWebhook", Tag: tagWebhooks, Summary: "Get a webhook subscription",
+		Responses: map[int]*content{200: jsonOf[model.WebhookSubscription]()}, Errors: []int{400, 404}},
+	{Method: "DELETE", Path: "/api/webhooks/{id}", ID: "deleteWebhook", Tag: tagWebhooks, Summary: "Delete a webhook subscription",
+		Responses: map[int]*content{204: noContent}, Errors: []int{400, 404}},
+	{Method: "GET", Path: "/api/webhooks/{id}/deliveries", ID: "listWebhookDeliveries", Tag: tagWebhooks,
+		Summary:   "Get the delivery log of a subscription, newest first",
+		Responses: map[int]*content{200: jsonOf[[]model.WebhookDelivery]()}, Errors: []int{400, 404}},
+	{Method: "POST", Path: "/api/events/{id}/webhooks", ID: "createEventWebhook", Tag: tagWebhooks, Summary: "Subscribe to changes of an event",
+		Request: jsonOf[model.WebhookSubscription](), Responses: map[int]*content{201: jsonOf[model.WebhookSubscription]()}, Errors: []int{400}},
+	{Method: "GET", Path: "/api/events/{id}/webhooks", ID: "listEventWebhooks", Tag: tagWebhooks, Summary: "List webhook subscriptions of an event",
+		Responses: map[int]*content{200: jsonOf[[]model.WebhookSubscription]()}, Errors: []int{400}},
+
+	// Załączniki
+	{Method: "POST", Path: "/api/events/{id}/expenses/{eid}/attachments", ID: "uploadAttachment", Tag: tagAttachments,
+		Summary: "Attach a file to an expense",
+		Request: &content{MediaTypes: []string{"multipart/form-data"}, Schema: map[string]any{"type": "object", "properties": map[string]any{
+			"file": map[string]any{"type": "string", "contentMediaType": "application/octet-stream"},
+		}}},
+		Responses: map[int]*content{201: jsonOf[model.Attachment]()}, Errors: []int{400, 404, 409, 413, 415}},
+	{Method: "GET", Path: "/api/events/{id}/expenses/{eid}/attachments", ID: "listAttachments", Tag: tagAttachments,
+		Summary: "List attachments of an expense", Responses: map[int]*content{200: jsonOf[[]model.Attachment]()}, Errors: []int{400, 404}},
+	{Method: "GET", Path: "/api/events/{id}/expenses/{eid}/attachments/{aid}", ID: "downloadAttachment", Tag: tagAttachments,
+		Summary: "Download an attachment", PathTypes: map[string]string{"aid": "string"},
+		Responses: map[int]*content{200: binary("Attachment content", "application/octet-stream")}, Errors: []int{400, 404}},
+	{Method: "DELETE", Path: "/api/events/{id}/expenses/{eid}/attachments/{aid}", ID: "deleteAttachment", Tag: tagAttachments,
+		Summary: "Delete an attachment", PathTypes: map[string]string{"aid": "string"},
+		Responses: map[int]*content{204: noContent}, Errors: []int{400, 404, 409}},
+
+	// Import i eksport
+	{Method: "POST", Path: "/api/import", ID: "importEvent", Tag: tagTransfer, Summary: "Create an event from a Splitwise or Tricount export",
+		Query: []parameter{
+			{Name: "format", Type: "string", Enum: []string{importer.FormatSplitwise, importer.FormatTricount}, Description: "Source application (detected when omitted)"},
+			{Name: "name", Type: "string", Description: "Name of the created event"},
+			{Name: "dryRun", Type: "boolean", Description: "Preview the result without saving the event"},
+		},
+		Request:   fileUpload,
+		Responses: map[int]*content{200: jsonOf[importer.Result](), 201: jsonOf[importer.Result]()}, Errors: []int{400, 409, 413, 422}},
+	{Method: "GET", Path: "/api/events/{id}/export", ID: "exportEvent", Tag: tagTransfer, Summary: "Export an event to a spreadsheet",
+		Query: []parameter{
+			{Name: "format", Type: "string", Enum: []string{"csv", "xlsx"}, Required: true},
+			{Name: "sheet", Type: "string", Enum: []string{service.SheetExpenses, service.SheetBalances, service.SheetSettlements},
+				Description: "Sheet exported as CSV (default expenses)"},
+			localeParam,
+		},
+		Responses: map[int]*content{200: binary("Spreadsheet file", "text/csv", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")},
+		Errors:    []int{400, 404}},
+	{Method: "GET", Path: "/api/events/{id}/report.pdf", ID: "getEventReport", Tag: tagTransfer, Summary: "Get a PDF settlement report",
+		Query:     []parameter{localeParam, {Name: "download", Type: "boolean", Description: "Serve the report as an attachment"}},
+		Responses: map[int]*content{200: binary("PDF report", "application/pdf")}, Errors: []int{400, 404}},
+
+	// Zmiany w czasie rzeczywistym
+	{Method: "GET", Path: "/api/events/{id}/stream", ID: "streamEvent", Tag: tagRealtime,
+		Summary: "Stream changes of an event as Server-Sent Events; each message carries an EventChange",
+		Responses: map[int]*content{200: {MediaTypes: []string{"text/event-stream"}, Schema: map[string]any{"type": "string"},
+			Description: "A snapshot message followed by one message per change, named after the change type"}},
+		Errors: []int{400, 404}},
+	{Method: "GET", Path: "/api/events/{id}/ws", ID: "collaborateEvent", Tag: tagRealtime,
+		Summary: "Open a WebSocket channel for collaborative editing of expenses",
+		Query: []parameter{
+			{Name: "name", Type: "string", Description: "Name shown to other editors"},
+			{Name: "participant", Type: "integer", Description: "Participant the editor represents"},
+		},
+		Responses: map[int]*content{101: {Description: "WebSocket connection; commands expense.add, expense.update, expense.delete, payments.set and ping"}},
+		Errors:    []int{400, 404}},
+
+	// Dokumentacja
+	{Method: "GET", Path: "/api/openapi.json", ID: "getOpenAPI", Tag: tagDocs, Summary: "Get this OpenAPI document",
+		Responses: map[int]*content{200: {MediaTypes: []string{"application/json"}, Schema: map[string]any{"type": "object"}}}},
+	{Method: "GET", Path: "/api/docs", ID: "getDocs", Tag: tagDocs, Summary: "Browse the API documentation (Swagger UI)",
+		Responses: map[int]*content{200: binary("HTML page", "text/html")}},
+}
+
+// pathParamPattern wyszukuje parametry ścieżki, np. {id}
+var pathParamPattern = regexp.MustCompile(`\{([a-z]+)\}`)
+
+var (
+	documentOnce sync.Once
+	documentJSON []byte
+)
+
+// Document zwraca dokument OpenAPI w postaci JSON
+func Document() []byte {
+	documentOnce.Do(func() {
+		var err error
+		if documentJSON, err = json.Marshal(buildDocument()); err != nil {
+			panic(err)
+		}
+	})
+	return documentJSON
+}
+
+// ServeSpec zwraca dokument OpenAPI
+func ServeSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(Document())
+}
+
+// buildDocument tworzy dokument OpenAPI z listy operacji i typów modelu
+func buildDocument() map[string]any {
+	registry := newSchemaRegistry()
+
+	// Komunikaty strumieni i webhooków oraz typy wskazywane w schematach podanych wprost
+	// nie wynikają z typów treści operacji, więc są rejestrowane osobno
+	for _, t := range []reflect.Type{
+		typeOf[model.EventChange](),
+		typeOf[model.SummaryDelta](),
+		typeOf[service.StatementSuggestions](),
+		typeOf[model.Repayment](),
+	} {
+		registry.schema(t)
+	}
+
+	paths := make(map[string]any)
+	for _, op := range operations {
+		item, ok := paths[op.Path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = buildOperation(registry, op)
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       apiTitle,
+			"version":     apiVersion,
+			"description": "API for splitting shared expenses between participants of events.",
+		},
+		"paths":    paths,
+		"webhooks": buildWebhooks(),
+		"components": map[string]any{
+			"schemas": registry.schemas,
+		},
+	}
+}
+
+// buildOperation opisuje pojedynczą operację
+func buildOperation(registry *schemaRegistry, op operation) map[string]any {
+	var parameters []any
+	for _, match := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+		paramType := "integer"
+		if t, ok := op.PathTypes[match[1]]; ok {
+			paramType = t
+		}
+		parameters = append(parameters, map[string]any{
+			"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": paramType},
+		})
+	}
+	for _, param := range op.Query {
+		schema := map[string]any{"type": param.Type}
+		if len(param.Enum) > 0 {
+			schema["enum"] = param.Enum
+		}
+		parameter := map[string]any{"name": param.Name, "in": "query", "schema": schema}
+		if param.Description != "" {
+			parameter["description"] = param.Description
+		}
+		if param.Required {
+			parameter["required"] = true
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	responses := make(map[string]any)
+	for status, body := range op.Responses {
+		responses[strconv.Itoa(status)] = buildBody(registry, body, http.StatusText(status))
+	}
+	for _, status := range op.Errors {
+		responses[strconv.Itoa(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content":     map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}},
+		}
+	}
+
+	result := map[string]any{
+		"operationId": op.ID,
+		"summary":     op.Summary,
+		"tags":        []string{op.Tag},
+		"responses":   responses,
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+	if op.Request != nil {
+		request := buildBody(registry, op.Request, "")
+		request["required"] = true
+		result["requestBody"] = request
+	}
+	return result
+}
+
+// buildBody opisuje treść zapytania lub odpowiedzi
+func buildBody(registry *schemaRegistry, body *content, defaultDescription string) map[string]any {
+	result := make(map[string]any)
+	if description := body.Description; description != "" {
+		result["description"] = description
+	} else if defaultDescription != "" {
+		result["description"] = defaultDescription
+	}
+
+	if len(body.MediaTypes) == 0 {
+		return result
+	}
+
+	schema := body.Schema
+	if body.Type != nil {
+		schema = registry.schema(body.Type)
+	}
+
+	mediaTypes := make(map[string]any, len(body.MediaTypes))
+	for _, mediaType := range body.MediaTypes {
+		if schema == nil {
+			mediaTypes[mediaType] = map[string]any{"schema": map[string]any{"type": "string", "contentMediaType": mediaType}}
+			continue
+		}
+		mediaTypes[mediaType] = map[string]any{"schema": schema}
+	}
+	result["content"] = mediaTypes
+	return result
+}
+
+// buildWebhooks opisuje powiadomienia wysyłane do subskrybentów webhooków
+func buildWebhooks() map[string]any {
+	header := func(name, description string) map[string]any {
+		return map[string]any{"name": name, "in": "header", "required": true, "description": description, "schema": map[string]any{"type": "string"}}
+	}
+
+	return map[string]any{
+		"eventChange": map[string]any{
+			"post": map[string]any{
+				"summary": "Change of an event, signed with the subscription secret",
+				"parameters": []any{
+					header(webhook.HeaderEvent, "Change type"),
+					header(webhook.HeaderDelivery, "Delivery identifier, the same for all attempts"),
+					header(webhook.HeaderTimestamp, "Unix time of the attempt"),
+					header(webhook.HeaderSignature, `"sha256=" followed by the hex HMAC-SHA256 of "{timestamp}.{body}"`),
+				},
+				"requestBody": map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/EventChange"}},
+					},
+				},
+				"responses": map[string]any{
+					"2XX": map[string]any{"description": "Delivery accepted; other responses are retried with backoff"},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package openapi
+
+import "net/http"
+
+// swaggerUIVersion to wersja Swagger UI ładowanej z CDN
+const swaggerUIVersion = "5.17.14"
+
+// uiPage to strona Swagger UI prezentująca dokument z /api/openapi.json
+const uiPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Splitty API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+// ServeUI zwraca stronę Swagger UI
+func ServeUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(uiPage))
+}
@@ -3,19 +3,86 @@ package router
 import (
 	"github.com/gorilla/mux"
 	"github.com/inflop/splitty.api/internal/infrastructure/api/handler"
+	"github.com/inflop/splitty.api/internal/infrastructure/api/openapi"
 )
 
 // SetupRoutes konfiguruje ścieżki API
-func SetupRoutes(eventHandler *handler.EventHandler) *mux.Router {
+func SetupRoutes(
+	eventHandler *handler.EventHandler,
+	attachmentHandler *handler.AttachmentHandler,
+	groupHandler *handler.GroupHandler,
+	webhookHandler *handler.WebhookHandler,
+) *mux.Router {
 	router := mux.NewRouter()
 
 	// Definiowanie endpointów API
 	router.HandleFunc("/api/events", eventHandler.CreateEvent).Methods("POST")
 	router.HandleFunc("/api/events", eventHandler.GetAllEvents).Methods("GET")
+	router.HandleFunc("/api/import", eventHandler.ImportEvent).Methods("POST")
 	router.HandleFunc("/api/events/{id}", eventHandler.GetEvent).Methods("GET")
 	router.HandleFunc("/api/events/{id}", eventHandler.UpdateEvent).Methods("PUT")
 	router.HandleFunc("/api/events/{id}", eventHandler.DeleteEvent).Methods("DELETE")
 	router.HandleFunc("/api/events/{id}/summary", eventHandler.GetEventSummary).Methods("GET")
+	router.HandleFunc("/api/events/{id}/stream", eventHandler.StreamEvent).Methods("GET")
+	router.HandleFunc("/api/events/{id}/ws", eventHandler.CollaborateEvent).Methods("GET")
+	router.HandleFunc("/api/events/{id}/expenses", eventHandler.GetEventExpenses).Methods("GET")
+	router.HandleFunc("/api/events/{id}/timeline", eventHandler.GetEventTimeline).Methods("GET")
+	router.HandleFunc("/api/events/{id}/categories", eventHandler.GetEventCategories).Methods("GET")
+	router.HandleFunc("/api/events/{id}/export", eventHandler.ExportEvent).Methods("GET")
+	router.HandleFunc("/api/events/{id}/report.pdf", eventHandler.GetEventReport).Methods("GET")
+	router.HandleFunc("/api/events/{id}/settlements/{from}/{to}/payment", eventHandler.GetSettlementPayment).Methods("GET")
+	router.HandleFunc("/api/events/{id}/settlements/{from}/{to}/qr", eventHandler.GetSettlementQR).Methods("GET")
+	router.HandleFunc("/api/events/{id}/bank-statements", eventHandler.ImportBankStatement).Methods("POST")
+	router.HandleFunc("/api/events/{id}/participants/merge", eventHandler.MergeParticipants).Methods("POST")
+	router.HandleFunc("/api/events/{id}/participants/{pid}", eventHandler.RemoveParticipant).Methods("DELETE")
+	router.HandleFunc("/api/events/{id}/periods", eventHandler.GetPeriods).Methods("GET")
+	router.HandleFunc("/api/events/{id}/periods/close", eventHandler.ClosePeriod).Methods("POST")
+	router.HandleFunc("/api/events/{id}/clone", eventHandler.CloneEvent).Methods("POST")
+	router.HandleFunc("/api/events/{id}/archive", eventHandler.ArchiveEvent).Methods("POST")
+	router.HandleFunc("/api/events/{id}/unarchive", eventHandler.UnarchiveEvent).Methods("POST")
+	router.HandleFunc("/api/events/{id}/template", eventHandler.SaveEventAsTemplate).Methods("POST")
+	router.HandleFunc("/api/events/{id}/webhooks", webhookHandler.CreateEventWebhook).Methods("POST")
+	router.HandleFunc("/api/events/{id}/webhooks", webhookHandler.GetEventWebhooks).Methods("GET")
+
+	// Kosz z usuniętymi wydarzeniami
+	router.HandleFunc("/api/trash", eventHandler.GetTrash).Methods("GET")
+	router.HandleFunc("/api/trash/{id}/restore", eventHandler.RestoreEvent).Methods("POST")
+	router.HandleFunc("/api/trash/{id}", eventHandler.PurgeEvent).Methods("DELETE")
+
+	// Szablony wydarzeń
+	router.HandleFunc("/api/templates", eventHandler.CreateTemplate).Methods("POST")
+	router.HandleFunc("/api/templates", eventHandler.GetAllTemplates).Methods("GET")
+	router.HandleFunc("/api/templates/{id}", eventHandler.GetTemplate).Methods("GET")
+	router.HandleFunc("/api/templates/{id}", eventHandler.DeleteTemplate).Methods("DELETE")
+
+	// Bilanse osób we wszystkich wydarzeniach
+	router.HandleFunc("/api/people/{id}/balances", eventHandler.GetPersonBalances).Methods("GET")
+
+	// Grupy wydarzeń rozliczanych łącznie
+	router.HandleFunc("/api/groups", groupHandler.CreateGroup).Methods("POST")
+	router.HandleFunc("/api/groups", groupHandler.GetAllGroups).Methods("GET")
+	router.HandleFunc("/api/groups/{id}", groupHandler.GetGroup).Methods("GET")
+	router.HandleFunc("/api/groups/{id}", groupHandler.UpdateGroup).Methods("PUT")
+	router.HandleFunc("/api/groups/{id}", groupHandler.DeleteGroup).Methods("DELETE")
+	router.HandleFunc("/api/groups/{id}/summary", groupHandler.GetGroupSummary).Methods("GET")
+	router.HandleFunc("/api/groups/{id}/settle", groupHandler.SettleGroup).Methods("POST")
+
+	// Subskrypcje powiadomień o zmianach wydarzeń
+	router.HandleFunc("/api/webhooks", webhookHandler.CreateWebhook).Methods("POST")
+	router.HandleFunc("/api/webhooks", webhookHandler.GetAllWebhooks).Methods("GET")
+	router.HandleFunc("/api/webhooks/{id}", webhookHandler.GetWebhook).Methods("GET")
+	router.HandleFunc("/api/webhooks/{id}", webhookHandler.DeleteWebhook).Methods("DELETE")
+	router.HandleFunc("/api/webhooks/{id}/deliveries", webhookHandler.GetWebhookDeliveries).Methods("GET")
+
+	// Załączniki do wydatków
+	router.HandleFunc("/api/events/{id}/expenses/{eid}/attachments", attachmentHandler.UploadAttachment).Methods("POST")
+	router.HandleFunc("/api/events/{id}/expenses/{eid}/attachments", attachmentHandler.GetAttachments).Methods("GET")
+	router.HandleFunc("/api/events/{id}/expenses/{eid}/attachments/{aid}", attachmentHandler.DownloadAttachment).Methods("GET")
+	router.HandleFunc("/api/events/{id}/expenses/{eid}/attachments/{aid}", attachmentHandler.DeleteAttachment).Methods("DELETE")
+
+	// Dokumentacja API
+	router.HandleFunc("/api/openapi.json", openapi.ServeSpec).Methods("GET")
+	router.HandleFunc("/api/docs", openapi.ServeUI).Methods("GET")
 
 	return router
 }
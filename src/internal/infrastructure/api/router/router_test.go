@@ -0,0 +1,108 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/inflop/splitty.api/internal/infrastructure/api/router"
+)
+
+// openAPIDocument to fragment dokumentu OpenAPI potrzebny do porównania z routerem
+type openAPIDocument struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]json.RawMessage `json:"schemas"`
+	} `json:"components"`
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	r := router.SetupRoutes(nil, nil, nil, nil)
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", response.Code)
+	}
+
+	var document openAPIDocument
+	if err := json.Unmarshal(response.Body.Bytes(), &document); err != nil {
+		t.Fatalf("Failed to decode OpenAPI document: %v", err)
+	}
+	if !strings.HasPrefix(document.OpenAPI, "3.1") {
+		t.Errorf("Expected OpenAPI 3.1 document, got %q", document.OpenAPI)
+	}
+
+	routes := make(map[string]bool)
+	err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			routes[method+" "+path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk routes: %v", err)
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range document.Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for _, route := range sortedKeys(routes) {
+		if !documented[route] {
+			t.Errorf("Route %s is missing from the OpenAPI document", route)
+		}
+	}
+	for _, route := range sortedKeys(documented) {
+		if !routes[route] {
+			t.Errorf("OpenAPI document describes %s, which is not routed", route)
+		}
+	}
+
+	// Każde odwołanie do schematu musi wskazywać istniejący komponent
+	for _, ref := range strings.Split(response.Body.String(), `"$ref":"#/components/schemas/`)[1:] {
+		name, _, _ := strings.Cut(ref, `"`)
+		if _, ok := document.Components.Schemas[name]; !ok {
+			t.Errorf("Schema %s is referenced but not defined", name)
+		}
+	}
+	for _, name := range []string{"Event", "Expense", "Summary", "EventChange", "WebhookSubscription"} {
+		if _, ok := document.Components.Schemas[name]; !ok {
+			t.Errorf("Expected model type %s to be described", name)
+		}
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	response := httptest.NewRecorder()
+	router.SetupRoutes(nil, nil, nil, nil).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `url: "/api/openapi.json"`) {
+		t.Errorf("Expected Swagger UI page pointing to the OpenAPI document, got %d", response.Code)
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,113 @@
+package bankstatement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// camtDocument to fragment komunikatu ISO 20022 camt.053 (wyciąg z rachunku).
+// Elementy dopasowywane są bez przestrzeni nazw, więc obsługiwane są różne wersje schematu.
+type camtDocument struct {
+	Statements []struct {
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+// camtEntry to pojedyncza pozycja wyciągu
+type camtEntry struct {
+	Amount struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	Indicator string `xml:"CdtDbtInd"`
+	Status    struct {
+		Value string `xml:",chardata"`
+		Code  string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookingDate     string `xml:"BookgDt>Dt"`
+	BookingDateTime string `xml:"BookgDt>DtTm"`
+	Reference       string `xml:"AcctSvcrRef"`
+	AdditionalInfo  string `xml:"AddtlNtryInf"`
+	Details         []struct {
+		EndToEndID    string   `xml:"Refs>EndToEndId"`
+		Debtor        string   `xml:"RltdPties>Dbtr>Nm"`
+		DebtorParty   string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+		Creditor      string   `xml:"RltdPties>Cdtr>Nm"`
+		CreditorParty string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+		Unstructured  []string `xml:"RmtInf>Ustrd"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// parseCAMT053 odczytuje zaksięgowane pozycje wyciągu camt.053
+func parseCAMT053(data []byte) ([]model.BankTransaction, error) {
+	var document camtDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid CAMT.053 document: %w", err)
+	}
+
+	var transactions []model.BankTransaction
+	for _, statement := range document.Statements {
+		for i, entry := range statement.Entries {
+			// Status to kod tekstowy (camt.053.001.02) lub element Cd (nowsze wersje)
+			status := firstNonEmpty(entry.Status.Code, entry.Status.Value)
+			if status == "PDNG" || status == "INFO" {
+				continue
+			}
+
+			amount, err := parseAmount(entry.Amount.Value)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			debit := entry.Indicator == "DBIT"
+			if debit {
+				amount = -amount
+			}
+
+			booking := entry.BookingDate
+			if booking == "" {
+				booking = entry.BookingDateTime
+			}
+			date, err := parseDate("2006-01-02", booking)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+
+			tx := model.BankTransaction{
+				Date:        date,
+				Amount:      amount,
+				Currency:    entry.Amount.Currency,
+				Description: strings.TrimSpace(entry.AdditionalInfo),
+				Reference:   entry.Reference,
+			}
+			if len(entry.Details) > 0 {
+				details := entry.Details[0]
+				// Kontrahentem przelewu przychodzącego jest dłużnik, a wychodzącego wierzyciel
+				tx.Counterparty = firstNonEmpty(details.Debtor, details.DebtorParty)
+				if debit {
+					tx.Counterparty = firstNonEmpty(details.Creditor, details.CreditorParty)
+				}
+				if remittance := strings.TrimSpace(strings.Join(details.Unstructured, " ")); remittance != "" {
+					tx.Description = remittance
+				}
+				if tx.Reference == "" && details.EndToEndID != "NOTPROVIDED" {
+					tx.Reference = details.EndToEndID
+				}
+			}
+			transactions = append(transactions, tx)
+		}
+	}
+	return transactions, nil
+}
+
+// firstNonEmpty zwraca pierwszą niepustą wartość
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package bankstatement
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// mt940Line to wzorzec pola :61: (data, opcjonalna data księgowania, znak, kod środków, kwota, reszta)
+var mt940Line = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z])?(\d+,\d*)(.*)$`)
+
+// mt940Subfield to wzorzec podpól strukturyzowanego pola :86: (np. ?20 lub ~20)
+var mt940Subfield = regexp.MustCompile(`[?~](\d{2})`)
+
+// parseMT940 odczytuje transakcje z komunikatu SWIFT MT940
+func parseMT940(data []byte) ([]model.BankTransaction, error) {
+	var transactions []model.BankTransaction
+	currency := ""
+
+	for i, field := range mt940Fields(string(data)) {
+		switch field.tag {
+		case "60F", "60M":
+			// Saldo otwarcia: znak, data (6 znaków), waluta (3 znaki), kwota
+			if len(field.value) >= 10 {
+				currency = field.value[7:10]
+			}
+		case "61":
+			tx, err := parseMT940Line(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("field %d: %w", i+1, err)
+			}
+			tx.Currency = currency
+			transactions = append(transactions, tx)
+		case "86":
+			if len(transactions) > 0 {
+				last := &transactions[len(transactions)-1]
+				last.Counterparty, last.Description = parseMT940Details(field.value)
+			}
+		}
+	}
+
+	if transactions == nil {
+		return nil, fmt.Errorf("MT940 statement has no transactions")
+	}
+	return transactions, nil
+}
+
+// mt940Field to pole komunikatu MT940 (tag i wartość z liniami kontynuacji)
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// mt940Fields dzieli komunikat na pola, łącząc linie kontynuacji z poprzednim polem
+func mt940Fields(data string) []mt940Field {
+	var fields []mt940Field
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, ":") {
+			if tag, value, ok := strings.Cut(line[1:], ":"); ok {
+				fields = append(fields, mt940Field{tag: tag, value: value})
+				continue
+			}
+		}
+		if len(fields) > 0 && line != "-" && line != "" {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+	return fields
+}
+
+// parseMT940Line parsuje pole :61: z datą, znakiem i kwotą transakcji
+func parseMT940Line(value string) (model.BankTransaction, error) {
+	firstLine, extra, _ := strings.Cut(value, "\n")
+	match := mt940Line.FindStringSubmatch(firstLine)
+	if match == nil {
+		return model.BankTransaction{}, fmt.Errorf("invalid statement line %q", firstLine)
+	}
+
+	date, err := parseDate("060102", match[1])
+	if err != nil {
+		return model.BankTransaction{}, err
+	}
+	amount, err := parseAmount(match[5])
+	if err != nil {
+		return model.BankTransaction{}, err
+	}
+	// Obciążenia (D) i storna uznań (RC) zmniejszają saldo
+	if match[3] == "D" || match[3] == "RC" {
+		amount = -amount
+	}
+
+	// Reszta linii: kod transakcji (4 znaki), referencja klienta i po "//" referencja banku
+	reference := match[6]
+	if len(reference) > 4 {
+		reference = reference[4:]
+	}
+	if customer, bank, ok := strings.Cut(reference, "//"); ok {
+		reference = bank
+		if bank == "" {
+			reference = customer
+		}
+	}
+	if reference == "NONREF" {
+		reference = ""
+	}
+
+	return model.BankTransaction{
+		Date:        date,
+		Amount:      amount,
+		Reference:   strings.TrimSpace(reference),
+		Description: strings.TrimSpace(extra),
+	}, nil
+}
+
+// parseMT940Details odczytuje kontrahenta i opis z pola :86:. Pole strukturyzowane
+// (podpola ?20-?29 lub ~20-~29 z opisem oraz ?32-?33 z nazwą) jest rozbijane na części.
+func parseMT940Details(value string) (string, string) {
+	value = strings.ReplaceAll(value, "\n", "")
+	indexes := mt940Subfield.FindAllStringSubmatchIndex(value, -1)
+	if len(indexes) == 0 {
+		return "", strings.TrimSpace(value)
+	}
+
+	var counterparty, description []string
+	for i, index := range indexes {
+		end := len(value)
+		if i+1 < len(indexes) {
+			end = indexes[i+1][0]
+		}
+		code, content := value[index[2]:index[3]], strings.TrimSpace(value[index[1]:end])
+		if content == "" {
+			continue
+		}
+		switch {
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			description = append(description, content)
+		case code == "32" || code == "33":
+			counterparty = append(counterparty, content)
+		}
+	}
+	return strings.Join(counterparty, " "), strings.Join(description, " ")
+}
@@ -0,0 +1,65 @@
+package bankstatement
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// ofxTransaction to wzorzec bloku transakcji; działa dla OFX 1.x (SGML) i 2.x (XML)
+var ofxTransaction = regexp.MustCompile(`(?s)<STMTTRN>(.*?)</STMTTRN>`)
+
+// ofxElement to wzorzec elementu z wartością; w SGML znaczniki zamykające są opcjonalne
+var ofxElement = regexp.MustCompile(`<([A-Z0-9.]+)>([^<\r\n]*)`)
+
+// parseOFX odczytuje transakcje z wyciągu OFX
+func parseOFX(data []byte) ([]model.BankTransaction, error) {
+	content := string(data)
+	currency := ofxElements(content)["CURDEF"]
+
+	var transactions []model.BankTransaction
+	for i, block := range ofxTransaction.FindAllStringSubmatch(content, -1) {
+		elements := ofxElements(block[1])
+
+		amount, err := parseAmount(elements["TRNAMT"])
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		date, err := parseDate("20060102", elements["DTPOSTED"])
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+
+		tx := model.BankTransaction{
+			Date:         date,
+			Amount:       amount,
+			Currency:     currency,
+			Counterparty: elements["NAME"],
+			Description:  elements["MEMO"],
+			Reference:    elements["FITID"],
+		}
+		if value := elements["CURRENCY"]; value != "" {
+			tx.Currency = value
+		}
+		transactions = append(transactions, tx)
+	}
+
+	if transactions == nil {
+		return nil, fmt.Errorf("OFX statement has no transactions")
+	}
+	return transactions, nil
+}
+
+// ofxElements zwraca wartości elementów (pierwsze wystąpienie każdego znacznika)
+func ofxElements(content string) map[string]string {
+	elements := make(map[string]string)
+	for _, match := range ofxElement.FindAllStringSubmatch(content, -1) {
+		if _, exists := elements[match[1]]; !exists {
+			elements[match[1]] = html.UnescapeString(strings.TrimSpace(match[2]))
+		}
+	}
+	return elements
+}
@@ -0,0 +1,78 @@
+// Package bankstatement parsuje wyciągi bankowe w formatach CAMT.053, MT940 i OFX.
+package bankstatement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Obsługiwane formaty wyciągów
+const (
+	FormatCAMT053 = "camt053"
+	FormatMT940   = "mt940"
+	FormatOFX     = "ofx"
+)
+
+// ErrUnknownFormat zwracany jest gdy nie udało się rozpoznać formatu wyciągu
+var ErrUnknownFormat = errors.New("unknown bank statement format")
+
+// Parse odczytuje transakcje z wyciągu. Pusty format oznacza automatyczne rozpoznanie.
+func Parse(format string, data []byte) ([]model.BankTransaction, error) {
+	if format == "" {
+		format = Detect(data)
+	}
+
+	switch format {
+	case FormatCAMT053:
+		return parseCAMT053(data)
+	case FormatMT940:
+		return parseMT940(data)
+	case FormatOFX:
+		return parseOFX(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// Detect rozpoznaje format wyciągu na podstawie jego zawartości
+func Detect(data []byte) string {
+	switch {
+	case bytes.Contains(data, []byte("<BkToCstmrStmt")):
+		return FormatCAMT053
+	case bytes.Contains(data, []byte("OFXHEADER")) || bytes.Contains(data, []byte("<OFX>")):
+		return FormatOFX
+	case bytes.Contains(data, []byte(":61:")):
+		return FormatMT940
+	default:
+		return ""
+	}
+}
+
+// parseAmount parsuje kwotę z kropką lub przecinkiem dziesiętnym
+func parseAmount(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// parseDate parsuje datę w podanym formacie, ignorując część czasu
+func parseDate(layout, value string) (model.Date, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < len(layout) {
+		return model.Date{}, fmt.Errorf("invalid date %q", value)
+	}
+	t, err := time.Parse(layout, value[:len(layout)])
+	if err != nil {
+		return model.Date{}, fmt.Errorf("invalid date %q", value)
+	}
+	return model.NewDate(t.Year(), t.Month(), t.Day()), nil
+}
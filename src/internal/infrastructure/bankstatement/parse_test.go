@@ -0,0 +1,122 @@
+package bankstatement_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/infrastructure/bankstatement"
+)
+
+const camtStatement = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Ntry>
+        <Amt Ccy="PLN">45.50</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2024-07-02</Dt></BookgDt>
+        <AcctSvcrRef>TX1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <RltdPties><Cdtr><Nm>Pizzeria Roma</Nm></Cdtr></RltdPties>
+          <RmtInf><Ustrd>Pizza</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="PLN">30.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><DtTm>2024-07-03T10:00:00</DtTm></BookgDt>
+        <AcctSvcrRef>TX2</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <RltdPties><Dbtr><Nm>Bob</Nm></Dbtr></RltdPties>
+          <RmtInf><Ustrd>Zwrot za pizze</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="PLN">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <BookgDt><Dt>2024-07-04</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+const mt940Statement = `:20:STMT
+:25:PL61109010140000071219812874
+:28C:1/1
+:60F:C240701PLN1000,00
+:61:2407020702D45,50NTRFNONREF//TX1
+:86:?20Pizza?32Pizzeria Roma
+:61:2407030703C30,00NTRFNONREF//TX2
+:86:?20Zwrot za
+?21pizze?32Bob
+:62F:C240703PLN984,50
+-`
+
+const ofxStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>PLN
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240702120000[+2:CEST]
+<TRNAMT>-45.50
+<FITID>TX1
+<NAME>Pizzeria Roma
+<MEMO>Pizza
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240703
+<TRNAMT>30.00
+<FITID>TX2
+<NAME>Bob
+<MEMO>Zwrot za pizze
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+func TestParse(t *testing.T) {
+	expected := []model.BankTransaction{
+		{Date: model.NewDate(2024, 7, 2), Amount: -45.5, Currency: "PLN", Counterparty: "Pizzeria Roma", Description: "Pizza", Reference: "TX1"},
+		{Date: model.NewDate(2024, 7, 3), Amount: 30, Currency: "PLN", Counterparty: "Bob", Description: "Zwrot za pizze", Reference: "TX2"},
+	}
+
+	statements := map[string]string{
+		bankstatement.FormatCAMT053: camtStatement,
+		bankstatement.FormatMT940:   mt940Statement,
+		bankstatement.FormatOFX:     ofxStatement,
+	}
+	for format, statement := range statements {
+		if detected := bankstatement.Detect([]byte(statement)); detected != format {
+			t.Errorf("Expected %s to be detected, got %q", format, detected)
+		}
+
+		transactions, err := bankstatement.Parse("", []byte(statement))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if len(transactions) != len(expected) {
+			t.Fatalf("%s: expected %d transactions, got %+v", format, len(expected), transactions)
+		}
+		for i, tx := range transactions {
+			if tx != expected[i] {
+				t.Errorf("%s: transaction %d = %+v, expected %+v", format, i, tx, expected[i])
+			}
+		}
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := bankstatement.Parse("", []byte("Date,Amount\n")); !errors.Is(err, bankstatement.ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
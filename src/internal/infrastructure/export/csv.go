@@ -0,0 +1,76 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// utf8BOM pozwala arkuszom kalkulacyjnym poprawnie rozpoznać polskie znaki w pliku CSV
+const utf8BOM = "\xef\xbb\xbf"
+
+// WriteCSV zapisuje arkusz w formacie CSV. Dla języka polskiego separatorem pól jest
+// średnik, a kwoty mają przecinek dziesiętny, zgodnie z domyślnymi ustawieniami arkuszy.
+func WriteCSV(w io.Writer, sheet service.ExportSheet, locale service.Locale) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if locale == service.LocalePL {
+		writer.Comma = ';'
+	}
+
+	header := make([]string, len(sheet.Header))
+	for i, value := range sheet.Header {
+		header[i] = escapeFormula(value)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range sheet.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = csvValue(cell, locale)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvValue formatuje komórkę arkusza jako tekst pola CSV
+func csvValue(cell any, locale service.Locale) string {
+	switch value := cell.(type) {
+	case float64:
+		return service.FormatDecimal(value, locale)
+	case int:
+		return strconv.Itoa(value)
+	case model.Date:
+		if value.IsZero() {
+			return ""
+		}
+		return value.String()
+	case string:
+		return escapeFormula(value)
+	default:
+		return ""
+	}
+}
+
+// escapeFormula zapobiega interpretowaniu tekstu jako formuły przez arkusz kalkulacyjny
+// (CSV injection), poprzedzając niebezpieczne wartości apostrofem
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,288 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/export"
+)
+
+var testSheet = service.ExportSheet{
+	ID:     service.SheetExpenses,
+	Name:   "Wydatki",
+	Header: []string{"ID", "Data", "Opis", "Kwota"},
+	Rows: [][]any{
+		{1, model.NewDate(2024, time.July, 1), `Pizza; "Roma" <&>`, 1234.5},
+		{2, model.Date{}, "=HYPERLINK(\"x\")", -20.0},
+	},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteCSV(&buf, testSheet, service.LocalePL); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimPrefix(buf.String(), "\xef\xbb\xbf"), "\n")
+	if lines[1] != `1;2024-07-01;"Pizza; ""Roma"" <&>";1234,50` {
+		t.Errorf("Unexpected Polish CSV row: %s", lines[1])
+	}
+	// Formuły są neutralizowane, liczby ujemne nie
+	if lines[2] != `2;;"'=HYPERLINK(""x"")";-20,00` {
+		t.Errorf("Unexpected escaped CSV row: %s", lines[2])
+	}
+
+	buf.Reset()
+	export.WriteCSV(&buf, testSheet, service.LocaleEN)
+	if !strings.Contains(buf.String(), `1,2024-07-01,"Pizza; ""Roma"" <&>",1234.50`) {
+		t.Errorf("Unexpected English CSV: %s", buf.String())
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	sheets := []service.ExportSheet{testSheet, {ID: service.SheetSettlements, Name: "Rozliczenia", Header: []string{"Od"}}}
+	if err := export.WriteXLSX(&buf, sheets); err != nil {
+		t.Fatalf("Failed to write XLSX: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Expected valid ZIP archive: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, _ := file.Open()
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		files[file.Name] = string(content)
+
+		// Każda część skoroszytu musi być poprawnym XML
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Invalid XML in %s: %v", file.Name, err)
+			}
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s in XLSX archive", name)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="D2" s="1"><v>1234.5</v></c>`) {
+		t.Error("Expected amount stored as number")
+	}
+	if !strings.Contains(sheet, `<c r="B2" s="2"><v>45474</v></c>`) {
+		t.Error("Expected date stored as serial number")
+	}
+	if !strings.Contains(sheet, `Pizza; &#34;Roma&#34; &lt;&amp;&gt;`) {
+		t.Error("Expected text to be XML-escaped")
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	report := &service.Report{
+		Title:        "Rozliczenie: Żółta łódź",
+		Details:      []service.ReportDetail{{Label: "Uczestnicy", Value: "2"}, {Label: "Alfabet", Value: "ĄĆĘŁŃÓŚŹŻ ąćęłńóśźż"}},
+		Participants: service.ReportTable{Title: "Uczestnicy", Header: []string{"Uczestnik", "Bilans"}, Numeric: []bool{false, true}},
+		Expenses:     service.ReportTable{Title: "Wydatki", Header: []string{"Opis", "Kwota"}, Numeric: []bool{false, true}},
+		Categories:   service.ReportChart{Title: "Kategorie", Bars: []service.ReportBar{{Label: "Jedzenie", Value: "90,00", Fraction: 1, Color: "#E67E22"}}},
+		Settlements:  service.ReportList{Title: "Jak się rozliczyć", Items: []string{"Bob przekazuje Alice: 30,00"}},
+		Footer:       "Wygenerowano 01.07.2024",
+	}
+	report.Participants.Rows = [][]string{{"Alice", "30,00"}, {"Bob", "-30,00"}}
+	for i := range 80 {
+		report.Expenses.Rows = append(report.Expenses.Rows, []string{strings.Repeat("Zakupy ", i%10+1), "1,00"})
+	}
+
+	var buf bytes.Buffer
+	if err := export.WritePDF(&buf, report); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	pdf := buf.String()
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("Expected PDF header and trailer")
+	}
+	if !strings.Contains(pdf, "/Count 3 ") {
+		t.Errorf("Expected 80 expense rows to span 3 pages")
+	}
+
+	// Dokument jest odczytywany przez niezależny od generatora parser: obiekty wskazane
+	// przez xref, drzewo stron, kodowanie fontów i strumienie treści
+	pages := parsePDF(t, buf.Bytes())
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %d", len(pages))
+	}
+	if !strings.Contains(pages[0], "Rozliczenie: Żółta łódź") {
+		t.Errorf("Expected Polish title on the first page, got %q", pages[0][:min(len(pages[0]), 80)])
+	}
+	if !strings.Contains(pages[0], "ĄĆĘŁŃÓŚŹŻ ąćęłńóśźż") {
+		t.Error("Expected all Polish letters to map to their glyphs")
+	}
+	if !strings.Contains(pages[2], "Jak się rozliczyć") || !strings.Contains(pages[2], "3 / 3") {
+		t.Errorf("Expected settlements and page number on the last page, got %q", pages[2])
+	}
+}
+
+// aglPolish to nazwy glifów polskich liter według Adobe Glyph List
+var aglPolish = map[string]rune{
+	"Aogonek": 0x0104, "aogonek": 0x0105, "Cacute": 0x0106, "cacute": 0x0107,
+	"Eogonek": 0x0118, "eogonek": 0x0119, "Lslash": 0x0141, "lslash": 0x0142,
+	"Nacute": 0x0143, "nacute": 0x0144, "Sacute": 0x015A, "sacute": 0x015B,
+	"Zacute": 0x0179, "zacute": 0x017A, "Zdotaccent": 0x017B, "zdotaccent": 0x017C,
+}
+
+var (
+	pdfRefPattern    = regexp.MustCompile(`/(\w+) (\d+) 0 R`)
+	pdfKidsPattern   = regexp.MustCompile(`/Kids \[([^\]]*)\]`)
+	pdfDiffsPattern  = regexp.MustCompile(`/Differences \[([^\]]*)\]`)
+	pdfLengthPattern = regexp.MustCompile(`/Length (\d+)`)
+	pdfTextPattern   = regexp.MustCompile(`/(\w+) [\d.]+ Tf [\d. ]+ Td <([0-9A-F]*)> Tj`)
+)
+
+// parsePDF odczytuje dokument przez tabelę xref i zwraca tekst kolejnych stron,
+// dekodując znaki zgodnie z tabelami Differences fontów
+func parsePDF(t *testing.T, data []byte) []string {
+	t.Helper()
+	pdf := string(data)
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("Expected PDF header and trailer")
+	}
+
+	var xref int
+	fmt.Sscanf(pdf[strings.LastIndex(pdf, "startxref")+len("startxref\n"):], "%d", &xref)
+	if !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Fatalf("startxref %d does not point to the xref table", xref)
+	}
+	var first, count int
+	fmt.Sscanf(pdf[xref+len("xref\n"):], "%d %d", &first, &count)
+	entries := strings.Split(pdf[xref:strings.Index(pdf, "trailer")], "\n")[2:]
+	trailer := pdf[strings.Index(pdf, "trailer"):]
+	if !strings.Contains(trailer, fmt.Sprintf("/Size %d ", count)) {
+		t.Errorf("Expected trailer /Size %d", count)
+	}
+
+	// Każdy wpis xref (rozmiar 20 bajtów) wskazuje początek swojego obiektu
+	objects := make(map[string]string, count)
+	for id := 1; id < count; id++ {
+		entry := entries[id]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " n ") {
+			t.Fatalf("Malformed xref entry %d: %q", id, entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		header := fmt.Sprintf("%d 0 obj\n", id)
+		if !strings.HasPrefix(pdf[offset:], header) {
+			t.Fatalf("xref entry %d points to %q", id, pdf[offset:min(offset+10, len(pdf))])
+		}
+		body := pdf[offset+len(header):]
+		objects[strconv.Itoa(id)] = body[:strings.Index(body, "\nendobj\n")]
+	}
+
+	// stream zwraca rozpakowany strumień obiektu, sprawdzając zgodność /Length
+	stream := func(id string) string {
+		object := objects[id]
+		length, _ := strconv.Atoi(pdfLengthPattern.FindStringSubmatch(object)[1])
+		start := strings.Index(object, "stream\n") + len("stream\n")
+		if object[start+length:] != "\nendstream" {
+			t.Fatalf("Stream %s does not end after /Length %d bytes", id, length)
+		}
+		reader, err := zlib.NewReader(strings.NewReader(object[start : start+length]))
+		if err != nil {
+			t.Fatalf("Failed to decompress stream %s: %v", id, err)
+		}
+		content, _ := io.ReadAll(reader)
+		return string(content)
+	}
+
+	refs := func(object string) map[string]string {
+		found := make(map[string]string)
+		for _, match := range pdfRefPattern.FindAllStringSubmatch(object, -1) {
+			found[match[1]] = match[2]
+		}
+		return found
+	}
+
+	// encoding zamienia kod znaku na literę według WinAnsiEncoding (dla użytych kodów
+	// zgodnego z Latin-1) nadpisanego tabelą Differences fontu
+	encoding := func(id string) map[byte]rune {
+		codes := make(map[byte]rune)
+		differences := pdfDiffsPattern.FindStringSubmatch(objects[id])
+		if differences == nil {
+			t.Fatalf("Expected /Differences in font %s", id)
+		}
+		code := 0
+		for _, token := range strings.Fields(differences[1]) {
+			if number, err := strconv.Atoi(token); err == nil {
+				code = number
+				continue
+			}
+			r, ok := aglPolish[strings.TrimPrefix(token, "/")]
+			if !ok {
+				t.Fatalf("Unexpected glyph %s in font %s", token, id)
+			}
+			codes[byte(code)] = r
+			code++
+		}
+		return codes
+	}
+
+	catalog := objects[refs(trailer)["Root"]]
+	kids := pdfKidsPattern.FindStringSubmatch(objects[refs(catalog)["Pages"]])
+	if kids == nil {
+		t.Fatal("Expected /Kids in the page tree")
+	}
+
+	var pages []string
+	for _, kid := range strings.Split(kids[1], " 0 R") {
+		kid = strings.TrimSpace(kid)
+		if kid == "" {
+			continue
+		}
+		pageRefs := refs(objects[kid])
+		fonts := make(map[string]map[byte]rune)
+		for name, id := range pageRefs {
+			if strings.HasPrefix(name, "F") {
+				fonts[name] = encoding(id)
+			}
+		}
+
+		var text strings.Builder
+		for _, match := range pdfTextPattern.FindAllStringSubmatch(stream(pageRefs["Contents"]), -1) {
+			codes, ok := fonts[match[1]]
+			if !ok {
+				t.Fatalf("Content of page %s uses unknown font %s", kid, match[1])
+			}
+			raw, _ := hex.DecodeString(match[2])
+			for _, code := range raw {
+				if r, ok := codes[code]; ok {
+					text.WriteRune(r)
+				} else {
+					text.WriteRune(rune(code))
+				}
+			}
+			text.WriteString("\n")
+		}
+		pages = append(pages, text.String())
+	}
+	return pages
+}
@@ -0,0 +1,404 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// Wymiary strony A4 i marginesy w punktach PDF
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+	pdfFooter     = 30.0
+)
+
+// Fonty raportu (standardowe fonty PDF, niewymagające osadzania)
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// pdfEncoding to kodowanie fontów: WinAnsiEncoding z polskimi znakami w miejscu kodów 128-143.
+// Glify polskich liter należą do zestawu standardowych fontów PDF.
+const pdfEncoding = "<< /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [128 " +
+	"/Aogonek /Cacute /Eogonek /Lslash /Nacute /Sacute /Zacute /Zdotaccent " +
+	"/aogonek /cacute /eogonek /lslash /nacute /sacute /zacute /zdotaccent] >>"
+
+// pdfPolish mapuje polskie litery na kody z pdfEncoding wraz z literą bazową (dla szerokości)
+var pdfPolish = map[rune]struct {
+	code byte
+	base byte
+}{
+	'Ą': {0x80, 'A'}, 'Ć': {0x81, 'C'}, 'Ę': {0x82, 'E'}, 'Ł': {0x83, 'L'},
+	'Ń': {0x84, 'N'}, 'Ś': {0x85, 'S'}, 'Ź': {0x86, 'Z'}, 'Ż': {0x87, 'Z'},
+	'ą': {0x88, 'a'}, 'ć': {0x89, 'c'}, 'ę': {0x8A, 'e'}, 'ł': {0x8B, 'l'},
+	'ń': {0x8C, 'n'}, 'ś': {0x8D, 's'}, 'ź': {0x8E, 'z'}, 'ż': {0x8F, 'z'},
+}
+
+// pdfWinAnsi mapuje znaki spoza Latin-1 dostępne w WinAnsiEncoding (kody 145-159)
+var pdfWinAnsi = map[rune]byte{
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfReplacements zastępuje znaki nieobecne w kodowaniu ich odpowiednikami
+var pdfReplacements = strings.NewReplacer("€", "EUR", "…", "...", "→", "->")
+
+// Szerokości znaków ASCII 32-126 fontów Helvetica i Helvetica-Bold (w 1/1000 rozmiaru)
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// pdfPalette to kolory słupków kategorii bez własnego koloru
+var pdfPalette = []string{"#2980B9", "#E67E22", "#27AE60", "#8E44AD", "#F1C40F", "#C0392B", "#16A085", "#95A5A6"}
+
+// pdfDocument układa treść raportu na kolejnych stronach
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// WritePDF zapisuje raport rozliczenia jako dokument PDF (A4) korzystający ze
+// standardowych fontów Helvetica, więc nie wymaga osadzania plików fontów
+func WritePDF(w io.Writer, report *service.Report) error {
+	doc := &pdfDocument{}
+	doc.newPage()
+
+	doc.text(pdfMargin, doc.y-18, fontBold, 18, report.Title)
+	doc.y -= 34
+	for _, detail := range report.Details {
+		doc.ensure(14)
+		doc.text(pdfMargin, doc.y-10, fontBold, 10, detail.Label+":")
+		doc.text(pdfMargin+130, doc.y-10, fontRegular, 10, detail.Value)
+		doc.y -= 14
+	}
+
+	doc.table(report.Participants)
+	doc.table(report.Expenses)
+	doc.chart(report.Categories)
+
+	doc.heading(report.Settlements.Title)
+	for _, item := range report.Settlements.Items {
+		doc.ensure(16)
+		doc.fillRect(pdfMargin+4, doc.y-9, 3, 3, "#333333")
+		doc.text(pdfMargin+14, doc.y-11, fontRegular, 11, item)
+		doc.y -= 16
+	}
+
+	// Stopka z numerem strony (liczba stron znana jest dopiero po ułożeniu treści)
+	for i, page := range doc.pages {
+		fmt.Fprintf(page, "0.5 g\n")
+		writePDFText(page, pdfMargin, pdfFooter, fontRegular, 8, report.Footer)
+		number := fmt.Sprintf("%d / %d", i+1, len(doc.pages))
+		writePDFText(page, pdfPageWidth-pdfMargin-textWidth(number, fontRegular, 8), pdfFooter, fontRegular, 8, number)
+	}
+
+	return doc.write(w, report.Title)
+}
+
+// newPage rozpoczyna nową stronę
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensure przechodzi na nową stronę, gdy na bieżącej brakuje miejsca
+func (d *pdfDocument) ensure(height float64) {
+	if d.y-height < pdfMargin+pdfFooter {
+		d.newPage()
+	}
+}
+
+// page zwraca strumień treści bieżącej strony
+func (d *pdfDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// text wypisuje tekst w kolorze czarnym
+func (d *pdfDocument) text(x, y float64, font string, size float64, value string) {
+	fmt.Fprintf(d.page(), "0 g\n")
+	writePDFText(d.page(), x, y, font, size, value)
+}
+
+// fillRect rysuje wypełniony prostokąt w kolorze #RRGGBB
+func (d *pdfDocument) fillRect(x, y, width, height float64, color string) {
+	r, g, b := parseColor(color)
+	fmt.Fprintf(d.page(), "%s %s %s rg %s %s %s %s re f\n",
+		pdfNumber(r), pdfNumber(g), pdfNumber(b), pdfNumber(x), pdfNumber(y), pdfNumber(width), pdfNumber(height))
+}
+
+// heading wypisuje tytuł sekcji; sekcja nie zaczyna się na samym dole strony
+func (d *pdfDocument) heading(title string) {
+	d.y -= 12
+	d.ensure(60)
+	d.text(pdfMargin, d.y-14, fontBold, 14, title)
+	d.y -= 22
+}
+
+// table rysuje tabelę z nagłówkiem powtarzanym na każdej stronie
+func (d *pdfDocument) table(table service.ReportTable) {
+	if len(table.Rows) == 0 {
+		return
+	}
+	d.heading(table.Title)
+
+	const size, rowHeight, padding = 9.0, 16.0, 4.0
+	widths := columnWidths(table, size, padding)
+
+	header := func() {
+		d.fillRect(pdfMargin, d.y-rowHeight, pdfPageWidth-2*pdfMargin, rowHeight, "#E5E8EC")
+		d.row(table.Header, table.Numeric, widths, fontBold, size, padding)
+		d.y -= rowHeight
+	}
+
+	header()
+	for i, row := range table.Rows {
+		if d.y-rowHeight < pdfMargin+pdfFooter {
+			d.newPage()
+			header()
+		}
+		if i%2 == 1 {
+			d.fillRect(pdfMargin, d.y-rowHeight, pdfPageWidth-2*pdfMargin, rowHeight, "#F6F7F9")
+		}
+		d.row(row, table.Numeric, widths, fontRegular, size, padding)
+		d.y -= rowHeight
+	}
+}
+
+// row wypisuje komórki wiersza tabeli, przycinając zbyt długie teksty
+func (d *pdfDocument) row(cells []string, numeric []bool, widths []float64, font string, size, padding float64) {
+	x := pdfMargin
+	for i, cell := range cells {
+		if i >= len(widths) {
+			break
+		}
+		cell = truncateText(cell, font, size, widths[i]-2*padding)
+		cellX := x + padding
+		if i < len(numeric) && numeric[i] {
+			cellX = x + widths[i] - padding - textWidth(cell, font, size)
+		}
+		d.text(cellX, d.y-11, font, size, cell)
+		x += widths[i]
+	}
+}
+
+// chart rysuje poziomy wykres słupkowy udziałów kategorii
+func (d *pdfDocument) chart(chart service.ReportChart) {
+	if len(chart.Bars) == 0 {
+		return
+	}
+	d.heading(chart.Title)
+
+	const labelWidth, valueWidth, barHeight, rowHeight = 120.0, 120.0, 10.0, 18.0
+	maxWidth := pdfPageWidth - 2*pdfMargin - labelWidth - valueWidth
+	largest := 0.0
+	for _, bar := range chart.Bars {
+		largest = max(largest, bar.Fraction)
+	}
+
+	for i, bar := range chart.Bars {
+		d.ensure(rowHeight)
+		d.text(pdfMargin, d.y-12, fontRegular, 9, truncateText(bar.Label, fontRegular, 9, labelWidth-8))
+
+		color := bar.Color
+		if color == "" {
+			color = pdfPalette[i%len(pdfPalette)]
+		}
+		width := 0.0
+		if largest > 0 {
+			width = max(bar.Fraction/largest*maxWidth, 1)
+		}
+		d.fillRect(pdfMargin+labelWidth, d.y-13, width, barHeight, color)
+		d.text(pdfMargin+labelWidth+width+6, d.y-12, fontRegular, 9, bar.Value)
+		d.y -= rowHeight
+	}
+}
+
+// write zapisuje strony jako dokument PDF 1.4 z kompresowanymi strumieniami treści
+func (d *pdfDocument) write(w io.Writer, title string) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Obiekty 1-5: katalog, drzewo stron, dwa fonty i metadane; dalej pary strona-treść
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding " + pdfEncoding + " >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding " + pdfEncoding + " >>")
+	object("<< /Title " + pdfTextString(title) + " /Producer (splitty) >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pdfNumber(pdfPageWidth), pdfNumber(pdfPageHeight), fontRegular, fontBold, firstPage+2*i+1))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// writePDFText zapisuje operatory wypisania tekstu w podanym miejscu
+func writePDFText(page *bytes.Buffer, x, y float64, font string, size float64, value string) {
+	fmt.Fprintf(page, "BT /%s %s Tf %s %s Td <%X> Tj ET\n",
+		font, pdfNumber(size), pdfNumber(x), pdfNumber(y), encodePDFText(value))
+}
+
+// encodePDFText koduje tekst zgodnie z pdfEncoding; znaki spoza kodowania zastępowane są "?"
+func encodePDFText(value string) []byte {
+	value = pdfReplacements.Replace(value)
+	encoded := make([]byte, 0, len(value))
+	for _, r := range value {
+		switch {
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			encoded = append(encoded, byte(r))
+		case pdfPolish[r].code != 0:
+			encoded = append(encoded, pdfPolish[r].code)
+		case pdfWinAnsi[r] != 0:
+			encoded = append(encoded, pdfWinAnsi[r])
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// textWidth zwraca szerokość tekstu w punktach
+func textWidth(value, font string, size float64) float64 {
+	widths := &helveticaWidths
+	if font == fontBold {
+		widths = &helveticaBoldWidths
+	}
+
+	total := 0
+	for _, code := range encodePDFText(value) {
+		for _, polish := range pdfPolish {
+			if polish.code == code {
+				code = polish.base
+				break
+			}
+		}
+		if code >= 32 && code <= 126 {
+			total += widths[code-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// truncateText skraca tekst z wielokropkiem, aby zmieścił się w podanej szerokości
+func truncateText(value, font string, size, width float64) string {
+	if textWidth(value, font, size) <= width {
+		return value
+	}
+	runes := []rune(value)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if candidate := string(runes) + "..."; textWidth(candidate, font, size) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// columnWidths dzieli szerokość strony między kolumny proporcjonalnie do ich treści,
+// ograniczając najszersze kolumny tak, aby tabela zmieściła się na stronie
+func columnWidths(table service.ReportTable, size, padding float64) []float64 {
+	available := pdfPageWidth - 2*pdfMargin
+	widths := make([]float64, len(table.Header))
+	for i, title := range table.Header {
+		widths[i] = textWidth(title, fontBold, size) + 2*padding
+		for _, row := range table.Rows {
+			if i < len(row) {
+				widths[i] = max(widths[i], textWidth(row[i], fontRegular, size)+2*padding)
+			}
+		}
+	}
+
+	total := 0.0
+	for _, width := range widths {
+		total += width
+	}
+	for i := range widths {
+		widths[i] = widths[i] * available / total
+	}
+	return widths
+}
+
+// parseColor zamienia kolor #RRGGBB na składowe w zakresie 0-1 (domyślnie szary)
+func parseColor(color string) (float64, float64, float64) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil || len(color) != 7 {
+		return 0.6, 0.6, 0.6
+	}
+	return float64(value>>16&0xFF) / 255, float64(value>>8&0xFF) / 255, float64(value&0xFF) / 255
+}
+
+// pdfNumber formatuje liczbę z dokładnością do 0,001 bez zbędnych zer
+// (PDF nie akceptuje notacji wykładniczej)
+func pdfNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+}
+
+// pdfTextString koduje tekst metadanych jako UTF-16BE z BOM w zapisie szesnastkowym
+func pdfTextString(value string) string {
+	var builder strings.Builder
+	builder.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(value)) {
+		fmt.Fprintf(&builder, "%04X", unit)
+	}
+	builder.WriteString(">")
+	return builder.String()
+}
@@ -0,0 +1,172 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// Style komórek zdefiniowane w xlsxStyles
+const (
+	styleDefault = 0
+	styleAmount  = 1
+	styleDate    = 2
+	styleHeader  = 3
+)
+
+// excelEpoch to dzień zerowy numeracji dat w arkuszach (z uwzględnieniem błędu roku 1900)
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+const xlsxContentTypesStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>
+`
+
+// xlsxStyles definiuje style: domyślny, kwota (#,##0.00), data i pogrubiony nagłówek.
+// Separatory liczb wyświetlane są przez arkusz zgodnie z ustawieniami regionalnymi użytkownika.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="#,##0.00"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+</cellXfs>
+</styleSheet>
+`
+
+// WriteXLSX zapisuje arkusze jako skoroszyt Office Open XML (XLSX). Kwoty i daty
+// zapisywane są jako liczby, więc arkusz formatuje je zgodnie z ustawieniami regionalnymi.
+func WriteXLSX(w io.Writer, sheets []service.ExportSheet) error {
+	archive := zip.NewWriter(w)
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(xlsxContentTypesStart)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+`)
+
+	for i, sheet := range sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`+"\n", n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.Name), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`+"\n", n, n)
+
+		if err := writeZipFile(archive, fmt.Sprintf("xl/worksheets/sheet%d.xml", n), worksheetXML(sheet)); err != nil {
+			return err
+		}
+	}
+
+	contentTypes.WriteString("</Types>\n")
+	workbook.WriteString("</sheets></workbook>\n")
+	// Arkusz stylów ma identyfikator za ostatnim arkuszem
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`+"\n", len(sheets)+1)
+	workbookRels.WriteString("</Relationships>\n")
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, file := range files {
+		if err := writeZipFile(archive, file.name, file.content); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// worksheetXML buduje zawartość arkusza z pogrubionym wierszem nagłówka
+func worksheetXML(sheet service.ExportSheet) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(sheet.Header))
+	for i, value := range sheet.Header {
+		header[i] = value
+	}
+	writeRow(&b, 1, header, styleHeader)
+	for i, row := range sheet.Rows {
+		writeRow(&b, i+2, row, styleDefault)
+	}
+
+	b.WriteString("</sheetData></worksheet>\n")
+	return b.String()
+}
+
+// writeRow zapisuje wiersz arkusza; tekst zapisywany jest jako inline string
+func writeRow(b *strings.Builder, number int, cells []any, textStyle int) {
+	fmt.Fprintf(b, `<row r="%d">`, number)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(number)
+		switch value := cell.(type) {
+		case float64:
+			fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleAmount, strconv.FormatFloat(value, 'f', -1, 64))
+		case int:
+			fmt.Fprintf(b, `<c r="%s"><v>%d</v></c>`, ref, value)
+		case model.Date:
+			if value.IsZero() {
+				continue
+			}
+			days := int(value.Sub(excelEpoch).Hours() / 24)
+			fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, styleDate, days)
+		case string:
+			fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, textStyle, xmlEscape(value))
+		}
+	}
+	b.WriteString("</row>")
+}
+
+// columnName zamienia numer kolumny (od 0) na oznaczenie literowe (A, B, ..., AA)
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xmlEscape zabezpiecza tekst do umieszczenia w dokumencie XML
+func xmlEscape(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// writeZipFile dodaje plik do archiwum ZIP
+func writeZipFile(archive *zip.Writer, name, content string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(file, content)
+	return err
+}
@@ -0,0 +1,245 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+)
+
+// Obsługiwane formaty importu
+const (
+	FormatSplitwise = "splitwise"
+	FormatTricount  = "tricount"
+)
+
+// ErrUnknownFormat zwracany jest gdy nie udało się rozpoznać formatu pliku
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Issue opisuje wiersz pliku, który nie został zaimportowany lub wymaga uwagi.
+// Numer wiersza liczony jest od 1 razem z nagłówkiem.
+type Issue struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// Result to wynik importu: utworzone wydarzenie oraz wiersze pominięte i ostrzeżenia
+type Result struct {
+	Event    *model.Event `json:"event"`
+	Skipped  []Issue      `json:"skipped"`
+	Warnings []Issue      `json:"warnings"`
+}
+
+// Importer zamienia eksporty innych aplikacji na wydarzenia
+type Importer struct {
+	expenseService *service.ExpenseService
+}
+
+// NewImporter tworzy nowy importer
+func NewImporter(expenseService *service.ExpenseService) *Importer {
+	return &Importer{expenseService: expenseService}
+}
+
+// Import parsuje plik CSV w podanym formacie. Pusty format oznacza automatyczne rozpoznanie
+// na podstawie nagłówka. Nazwa wydarzenia jest opcjonalna.
+func (i *Importer) Import(format, name string, r io.Reader) (*Result, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	if format == "" {
+		format = detectFormat(records[0])
+	}
+
+	b := &eventBuilder{
+		expenseService: i.expenseService,
+		participants:   make(map[string]int),
+		result: &Result{
+			Event:    &model.Event{Name: name, Participants: []model.Participant{}, Expenses: []model.Expense{}},
+			Skipped:  []Issue{},
+			Warnings: []Issue{},
+		},
+	}
+
+	switch format {
+	case FormatSplitwise:
+		err = b.splitwise(records)
+	case FormatTricount:
+		err = b.tricount(records)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if b.result.Event.Name == "" {
+		b.result.Event.Name = "Import " + format
+	}
+	return b.result, nil
+}
+
+// detectFormat rozpoznaje format pliku na podstawie nagłówka
+func detectFormat(header []string) string {
+	if len(header) >= 5 && strings.EqualFold(header[0], "Date") && strings.EqualFold(header[3], "Cost") &&
+		strings.EqualFold(header[4], "Currency") {
+		return FormatSplitwise
+	}
+	for _, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), "Paid by") {
+			return FormatTricount
+		}
+	}
+	return ""
+}
+
+// readCSV odczytuje plik CSV rozdzielany przecinkami lub średnikami
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	return records, nil
+}
+
+// eventBuilder buduje wydarzenie z kolejnych wierszy importu
+type eventBuilder struct {
+	expenseService *service.ExpenseService
+	participants   map[string]int
+	result         *Result
+}
+
+// participant zwraca ID uczestnika o podanej nazwie, dodając go przy pierwszym wystąpieniu
+func (b *eventBuilder) participant(name string) int {
+	name = strings.TrimSpace(name)
+	key := strings.ToLower(name)
+	if id, exists := b.participants[key]; exists {
+		return id
+	}
+
+	id := len(b.result.Event.Participants) + 1
+	b.participants[key] = id
+	b.result.Event.Participants = append(b.result.Event.Participants, model.Participant{ID: id, Name: name})
+	return id
+}
+
+// skip zapisuje wiersz pominięty w imporcie
+func (b *eventBuilder) skip(row int, format string, args ...any) {
+	b.result.Skipped = append(b.result.Skipped, Issue{Row: row, Reason: fmt.Sprintf(format, args...)})
+}
+
+// warn zapisuje ostrzeżenie dotyczące zaimportowanego wiersza
+func (b *eventBuilder) warn(row int, format string, args ...any) {
+	b.result.Warnings = append(b.result.Warnings, Issue{Row: row, Reason: fmt.Sprintf(format, args...)})
+}
+
+// acceptCurrency sprawdza czy wiersz jest w walucie wydarzenia; pierwsza waluta staje się walutą wydarzenia.
+// Kwoty w innych walutach nie są przeliczane, więc takie wiersze są pomijane.
+func (b *eventBuilder) acceptCurrency(row int, currency string) bool {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	event := b.result.Event
+	if event.Currency == "" {
+		event.Currency = currency
+	}
+	if currency != "" && currency != event.Currency {
+		b.skip(row, "currency %s differs from event currency %s and cannot be converted", currency, event.Currency)
+		return false
+	}
+	return true
+}
+
+// addExpense dodaje wydatek z płatnościami i udziałami uczestników. Równe udziały zapisywane
+// są jako sharedWith, a nierówne jako pozycje rachunku przypisane do poszczególnych osób.
+func (b *eventBuilder) addExpense(expense model.Expense, payments []model.Payment, shareIDs []int, shares map[int]float64) {
+	s := b.expenseService
+	expense.ID = len(b.result.Event.Expenses) + 1
+	expense.Payments = payments
+	expense.SharedWith = shareIDs
+
+	equal := s.RoundToTwo(expense.TotalAmount / float64(len(shareIDs)))
+	for _, id := range shareIDs {
+		if s.RoundToTwo(shares[id]-equal) != 0 {
+			for _, id := range shareIDs {
+				expense.Items = append(expense.Items, model.ExpenseItem{
+					Description:  expense.Description,
+					Amount:       shares[id],
+					Participants: []int{id},
+				})
+			}
+			break
+		}
+	}
+
+	b.result.Event.Expenses = append(b.result.Event.Expenses, expense)
+}
+
+// parseAmount parsuje kwotę zapisaną z kropką lub przecinkiem dziesiętnym
+// i opcjonalnym separatorem tysięcy (np. "1,234.50", "1 234,50")
+func parseAmount(value string) (float64, error) {
+	value = strings.NewReplacer(" ", "", "\u00a0", "").Replace(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+
+	lastComma, lastDot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+	if lastComma > lastDot {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// dateLayouts to formaty dat spotykane w eksportach
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05Z07:00",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+// parseDate parsuje datę w jednym z formatów eksportów
+func parseDate(value string) (model.Date, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return model.NewDate(t.Year(), t.Month(), t.Day()), nil
+		}
+	}
+	return model.Date{}, fmt.Errorf("invalid date %q", value)
+}
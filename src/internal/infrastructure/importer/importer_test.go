@@ -0,0 +1,97 @@
+package importer_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/importer"
+)
+
+const splitwiseExport = `Date,Description,Category,Cost,Currency,Alice,Bob,Carol
+2024-07-01,Dinner,Dining out,90.00,PLN,60.00,-30.00,-30.00
+2024-07-02,Taxi,Taxi,10.00,PLN,-3.33,6.67,-3.34
+2024-07-03,Museum,Entertainment,20.00,EUR,10.00,-10.00,0.00
+2024-07-04,Payment,Payment,30.00,PLN,0.00,-30.00,30.00
+2024-07-05,Broken,General,abc,PLN,1.00,-1.00,0.00
+
+,Total balance,,,PLN,56.67,-53.33,-3.34
+`
+
+const tricountExport = `Title;Amount;Currency;Exchange rate;Amount in default currency;Date & time;Paid by;Type;Category;Paid for Ala;Paid for Ola
+Hotel;200,00;EUR;4,30;860,00;2024-07-01 10:00;Ala;Normal;Accommodation;100,00;100,00
+Zwrot za bilety;40,00;PLN;1;40,00;2024-07-02 12:00;Ola;Income;;20,00;20,00
+`
+
+func balancesByName(t *testing.T, result *importer.Result) map[string]float64 {
+	t.Helper()
+
+	expenseService := service.NewExpenseService()
+	if err := expenseService.ValidateEvent(result.Event); err != nil {
+		t.Fatalf("Expected imported event to be valid, got %v", err)
+	}
+
+	balances := make(map[string]float64)
+	for _, b := range expenseService.CalculateBalances(result.Event) {
+		balances[b.Name] = b.Balance
+	}
+	return balances
+}
+
+func TestImportSplitwise(t *testing.T) {
+	result, err := importer.NewImporter(service.NewExpenseService()).Import("", "Wakacje", strings.NewReader(splitwiseExport))
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	if result.Event.Name != "Wakacje" || result.Event.Currency != "PLN" || len(result.Event.Expenses) != 3 {
+		t.Errorf("Unexpected event: %+v", result.Event)
+	}
+
+	// Wiersz w innej walucie i wiersz z błędną kwotą są raportowane
+	if len(result.Skipped) != 2 || result.Skipped[0].Row != 4 || result.Skipped[1].Row != 6 {
+		t.Errorf("Expected rows 4 and 6 to be skipped, got %+v", result.Skipped)
+	}
+
+	// Bilanse bez pominiętego wiersza EUR zgadzają się z Total balance
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no balance warnings, got %+v", result.Warnings)
+	}
+	balances := balancesByName(t, result)
+	if balances["Alice"] != 56.67 || balances["Bob"] != -53.33 || balances["Carol"] != -3.34 {
+		t.Errorf("Unexpected balances: %v", balances)
+	}
+
+	// Nierówny podział zapisany jest jako pozycje rachunku
+	if taxi := result.Event.Expenses[1]; len(taxi.Items) != 3 || taxi.Items[2].Amount != 3.34 {
+		t.Errorf("Expected uneven split as items, got %+v", taxi)
+	}
+}
+
+func TestImportTricount(t *testing.T) {
+	result, err := importer.NewImporter(service.NewExpenseService()).Import(importer.FormatTricount, "", strings.NewReader(tricountExport))
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	if result.Event.Currency != "PLN" || len(result.Event.Expenses) != 2 || len(result.Skipped) != 0 {
+		t.Fatalf("Unexpected import result: %+v", result)
+	}
+
+	// Udziały w EUR przeliczone na walutę domyślną
+	if len(result.Warnings) != 1 || result.Warnings[0].Row != 2 {
+		t.Errorf("Expected scaling warning for row 2, got %+v", result.Warnings)
+	}
+
+	balances := balancesByName(t, result)
+	if balances["Ala"] != 450 || balances["Ola"] != -450 {
+		t.Errorf("Unexpected balances: %v", balances)
+	}
+}
+
+func TestImportUnknownFormat(t *testing.T) {
+	if _, err := importer.NewImporter(service.NewExpenseService()).Import("", "", strings.NewReader("a,b\n1,2\n")); !errors.Is(err, importer.ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// splitwiseFixedColumns to liczba kolumn eksportu Splitwise przed kolumnami uczestników
+// (Date, Description, Category, Cost, Currency)
+const splitwiseFixedColumns = 5
+
+// splitwise importuje eksport CSV Splitwise. Kolumny uczestników zawierają wpływ wiersza
+// na bilans osoby: dodatni dla płacącego, ujemny dla obciążonych. Ostatni wiersz
+// "Total balance" służy do sprawdzenia, czy bilanse zgadzają się z aplikacją źródłową.
+func (b *eventBuilder) splitwise(records [][]string) error {
+	header := records[0]
+	if len(header) <= splitwiseFixedColumns {
+		return errors.New("splitwise export has no participant columns")
+	}
+
+	names := header[splitwiseFixedColumns:]
+	ids := make([]int, len(names))
+	for i, name := range names {
+		ids[i] = b.participant(name)
+	}
+
+	var totals []string
+	totalsRow := 0
+	for index, record := range records[1:] {
+		row := index + 2
+		if isBlank(record) {
+			continue
+		}
+		if len(record) < len(header) {
+			b.skip(row, "expected %d columns, got %d", len(header), len(record))
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(record[1]), "Total balance") {
+			totals, totalsRow = record, row
+			continue
+		}
+
+		date, err := parseDate(record[0])
+		if err != nil {
+			b.skip(row, "%v", err)
+			continue
+		}
+		cost, err := parseAmount(record[3])
+		if err != nil || cost <= 0 {
+			b.skip(row, "invalid cost %q", record[3])
+			continue
+		}
+		if !b.acceptCurrency(row, record[4]) {
+			continue
+		}
+
+		nets := make([]float64, len(names))
+		sum := 0.0
+		valid := true
+		for i := range names {
+			if nets[i], err = parseAmount(record[splitwiseFixedColumns+i]); err != nil {
+				b.skip(row, "participant %s: %v", names[i], err)
+				valid = false
+				break
+			}
+			sum += nets[i]
+		}
+		if !valid {
+			continue
+		}
+		if math.Abs(sum) > 0.005*float64(len(names)) {
+			b.skip(row, "participant balances do not add up to zero")
+			continue
+		}
+
+		b.splitwiseExpense(row, model.Expense{
+			Date:        date,
+			Description: strings.TrimSpace(record[1]),
+			Category:    strings.TrimSpace(record[2]),
+			TotalAmount: cost,
+		}, ids, nets)
+	}
+
+	if totals != nil {
+		b.verifyTotals(totalsRow, ids, names, totals[splitwiseFixedColumns:])
+	}
+	return nil
+}
+
+// splitwiseExpense odtwarza płatności i udziały wydatku z wpływu na bilanse. Przy jednym
+// płacącym odtwarzany jest pełny wydatek; przy kilku płacących zapisywane są tylko różnice,
+// co zachowuje bilanse, ale nie kwotę wydatku.
+func (b *eventBuilder) splitwiseExpense(row int, expense model.Expense, ids []int, nets []float64) {
+	s := b.expenseService
+
+	var payers []int
+	for i, net := range nets {
+		if net > 0 {
+			payers = append(payers, i)
+		}
+	}
+	if len(payers) == 0 {
+		b.skip(row, "expense has no payer")
+		return
+	}
+
+	shares := make(map[int]float64)
+	var shareIDs []int
+	var payments []model.Payment
+
+	if len(payers) == 1 {
+		payer := payers[0]
+		payments = []model.Payment{{ParticipantID: ids[payer], Amount: expense.TotalAmount}}
+		for i, net := range nets {
+			share := -net
+			if i == payer {
+				share = s.RoundToTwo(expense.TotalAmount - net)
+			}
+			if share > 0 {
+				shares[ids[i]] = share
+				shareIDs = append(shareIDs, ids[i])
+			}
+		}
+	} else {
+		b.warn(row, "expense has %d payers; imported as net amounts", len(payers))
+		expense.TotalAmount = 0
+		for i, net := range nets {
+			switch {
+			case net > 0:
+				payments = append(payments, model.Payment{ParticipantID: ids[i], Amount: net})
+				expense.TotalAmount = s.RoundToTwo(expense.TotalAmount + net)
+			case net < 0:
+				shares[ids[i]] = -net
+				shareIDs = append(shareIDs, ids[i])
+			}
+		}
+	}
+
+	if len(shareIDs) == 0 {
+		b.skip(row, "expense is not shared with anyone")
+		return
+	}
+	b.addExpense(expense, payments, shareIDs, shares)
+}
+
+// verifyTotals porównuje wyliczone bilanse z bilansami końcowymi aplikacji źródłowej
+func (b *eventBuilder) verifyTotals(row int, ids []int, names []string, totals []string) {
+	balances := make(map[int]float64)
+	for _, balance := range b.expenseService.CalculateBalances(b.result.Event) {
+		balances[balance.ID] = balance.Balance
+	}
+
+	for i, value := range totals {
+		expected, err := parseAmount(value)
+		if err != nil {
+			continue
+		}
+		if actual := balances[ids[i]]; b.expenseService.RoundToTwo(actual-expected) != 0 {
+			b.warn(row, "balance of %s is %.2f, source app reports %.2f", names[i], actual, expected)
+		}
+	}
+}
+
+// isBlank sprawdza czy wiersz nie zawiera żadnych wartości
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
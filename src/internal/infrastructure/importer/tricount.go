@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// tricountColumns to indeksy rozpoznanych kolumn eksportu Tricount (-1 gdy brak)
+type tricountColumns struct {
+	title, amount, converted, currency, date, paidBy, kind, category int
+	// shares mapuje indeks kolumny "Paid for <osoba>" na nazwę osoby
+	shares map[int]string
+}
+
+// tricountSharePrefixes to prefiksy nagłówków kolumn z udziałami uczestników
+var tricountSharePrefixes = []string{"paid for ", "impacted to ", "for "}
+
+// tricount importuje eksport CSV Tricount. Kolumny rozpoznawane są po nazwach nagłówków
+// (Title, Amount, Currency, Amount in default currency, Date & time, Paid by, Type,
+// Category oraz "Paid for <osoba>"). Kwoty w walucie domyślnej mają pierwszeństwo przed
+// kwotami oryginalnymi, a wpływy (Income) importowane są jako zwroty.
+func (b *eventBuilder) tricount(records [][]string) error {
+	columns := tricountColumns{title: -1, amount: -1, converted: -1, currency: -1, date: -1, paidBy: -1, kind: -1, category: -1, shares: make(map[int]string)}
+	var shareOrder []int
+	for i, name := range records[0] {
+		lower := strings.ToLower(strings.TrimSpace(name))
+		switch lower {
+		case "title", "what", "description":
+			columns.title = i
+		case "amount":
+			columns.amount = i
+		case "amount in default currency":
+			columns.converted = i
+		case "currency":
+			columns.currency = i
+		case "date & time", "date", "when":
+			columns.date = i
+		case "paid by", "who paid":
+			columns.paidBy = i
+		case "type", "transaction type":
+			columns.kind = i
+		case "category":
+			columns.category = i
+		default:
+			for _, prefix := range tricountSharePrefixes {
+				if strings.HasPrefix(lower, prefix) {
+					columns.shares[i] = strings.TrimSpace(name[len(prefix):])
+					shareOrder = append(shareOrder, i)
+					break
+				}
+			}
+		}
+	}
+	if columns.amount == -1 && columns.converted == -1 || columns.paidBy == -1 || len(columns.shares) == 0 {
+		return errors.New("unrecognized Tricount export: amount, paid by and participant columns are required")
+	}
+
+	for _, i := range shareOrder {
+		b.participant(columns.shares[i])
+	}
+
+	for index, record := range records[1:] {
+		row := index + 2
+		if isBlank(record) {
+			continue
+		}
+		if len(record) < len(records[0]) {
+			b.skip(row, "expected %d columns, got %d", len(records[0]), len(record))
+			continue
+		}
+		b.tricountRow(row, record, columns, shareOrder)
+	}
+	return nil
+}
+
+// tricountRow importuje pojedynczy wiersz eksportu Tricount
+func (b *eventBuilder) tricountRow(row int, record []string, columns tricountColumns, shareOrder []int) {
+	s := b.expenseService
+	field := func(i int) string {
+		if i < 0 {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	// Kwota w walucie domyślnej nie wymaga przeliczania
+	amountColumn := columns.converted
+	if amountColumn == -1 || field(amountColumn) == "" {
+		amountColumn = columns.amount
+		if !b.acceptCurrency(row, field(columns.currency)) {
+			return
+		}
+	} else if b.result.Event.Currency == "" && field(columns.amount) == field(columns.converted) {
+		b.result.Event.Currency = strings.ToUpper(field(columns.currency))
+	}
+
+	amount, err := parseAmount(field(amountColumn))
+	if err != nil || amount == 0 {
+		b.skip(row, "invalid amount %q", field(amountColumn))
+		return
+	}
+	amount = math.Abs(amount)
+
+	if field(columns.paidBy) == "" {
+		b.skip(row, "payer is missing")
+		return
+	}
+
+	expense := model.Expense{
+		Description: field(columns.title),
+		Category:    field(columns.category),
+		TotalAmount: amount,
+	}
+	if value := field(columns.date); value != "" {
+		if expense.Date, err = parseDate(value); err != nil {
+			b.skip(row, "%v", err)
+			return
+		}
+	}
+	switch strings.ToLower(field(columns.kind)) {
+	case "income":
+		expense.Kind = model.ExpenseKindRefund
+	case "", "normal", "expense", "money transfer", "transfer", "balance":
+	default:
+		b.warn(row, "unknown transaction type %q imported as expense", field(columns.kind))
+	}
+
+	shares := make(map[int]float64)
+	var shareIDs []int
+	sum := 0.0
+	for _, i := range shareOrder {
+		share, err := parseAmount(field(i))
+		if err != nil {
+			b.skip(row, "participant %s: %v", columns.shares[i], err)
+			return
+		}
+		if share == 0 {
+			continue
+		}
+		id := b.participant(columns.shares[i])
+		shares[id] = math.Abs(share)
+		shareIDs = append(shareIDs, id)
+		sum = s.RoundToTwo(sum + math.Abs(share))
+	}
+	if len(shareIDs) == 0 {
+		b.skip(row, "expense is not shared with anyone")
+		return
+	}
+
+	// Udziały w walucie oryginalnej przeliczane są proporcjonalnie do kwoty wydatku,
+	// a różnica zaokrągleń trafia do ostatniego uczestnika
+	if s.RoundToTwo(sum-amount) != 0 {
+		b.warn(row, "shares %.2f scaled to amount %.2f", sum, amount)
+		rest := amount
+		for i, id := range shareIDs {
+			if i == len(shareIDs)-1 {
+				shares[id] = s.RoundToTwo(rest)
+				break
+			}
+			shares[id] = s.RoundToTwo(shares[id] * amount / sum)
+			rest -= shares[id]
+		}
+	}
+
+	payments := []model.Payment{{ParticipantID: b.participant(field(columns.paidBy)), Amount: amount}}
+	b.addExpense(expense, payments, shareIDs, shares)
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"context"
+	"log"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// Sprawdzenie czy implementacja spełnia interfejs
+var _ repository.Mailer = (*LogMailer)(nil)
+
+// LogMailer zapisuje wiadomości w logu zamiast je wysyłać. Używany, gdy serwer SMTP
+// nie jest skonfigurowany (np. podczas lokalnego uruchomienia).
+type LogMailer struct {
+	logger *log.Logger
+}
+
+// NewLogMailer tworzy nadawcę zapisującego wiadomości w logu
+func NewLogMailer(logger *log.Logger) *LogMailer {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogMailer{logger: logger}
+}
+
+// Send zapisuje wiadomość w logu
+func (m *LogMailer) Send(_ context.Context, message model.EmailMessage) error {
+	m.logger.Printf("Mail to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
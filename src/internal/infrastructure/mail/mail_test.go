@@ -0,0 +1,175 @@
+package mail_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/infrastructure/mail"
+)
+
+// smtpStub to minimalny serwer SMTP do testów, na wzór lokalnych narzędzi typu MailHog
+type smtpStub struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	auth     string
+	from     string
+	to       []string
+	data     string
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	stub := &smtpStub{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+	return stub
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP stub")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		s.mutex.Lock()
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-localhost")
+			reply("250-8BITMIME")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH PLAIN "):
+			s.auth = line[len("AUTH PLAIN "):]
+			reply("235 Authenticated")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from, _, _ = strings.Cut(strings.TrimPrefix(line[len("MAIL FROM:"):], "<"), ">")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					s.mutex.Unlock()
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			s.data = data.String()
+			reply("250 Queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			s.mutex.Unlock()
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+		s.mutex.Unlock()
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	stub := newSMTPStub(t)
+	port := stub.listener.Addr().(*net.TCPAddr).Port
+
+	mailer, err := mail.NewSMTPMailer(mail.SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "user",
+		Password: "secret",
+		From:     "Splitty <splitty@example.com>",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create mailer: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), model.EmailMessage{
+		To:      "bartek@example.com",
+		ToName:  "Bartek Żółć",
+		Subject: "Nowy wydatek w „Wyjazd”",
+		Body:    "Cześć Bartek,\nTwój udział: 30,00 PLN\n.\nKoniec",
+	})
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+
+	if auth, _ := base64.StdEncoding.DecodeString(stub.auth); string(auth) != "\x00user\x00secret" {
+		t.Errorf("Expected PLAIN credentials, got %q", auth)
+	}
+	if stub.from != "splitty@example.com" || len(stub.to) != 1 || stub.to[0] != "bartek@example.com" {
+		t.Errorf("Unexpected envelope: from %q to %v", stub.from, stub.to)
+	}
+
+	message, err := netmail.ReadMessage(strings.NewReader(stub.data))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != "Nowy wydatek w „Wyjazd”" {
+		t.Errorf("Unexpected subject %q (%v)", subject, err)
+	}
+	to, err := message.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Name != "Bartek Żółć" {
+		t.Errorf("Unexpected recipient header %q (%v)", message.Header.Get("To"), err)
+	}
+	if message.Header.Get("Message-ID") == "" || message.Header.Get("Date") == "" {
+		t.Error("Expected Message-ID and Date headers")
+	}
+
+	body, err := io.ReadAll(quotedprintable.NewReader(message.Body))
+	if err != nil || strings.TrimRight(string(body), "\r\n") != "Cześć Bartek,\r\nTwój udział: 30,00 PLN\r\n.\r\nKoniec" {
+		t.Errorf("Unexpected body %q (%v)", body, err)
+	}
+}
+
+func TestSMTPMailerRejectsInvalidAddresses(t *testing.T) {
+	if _, err := mail.NewSMTPMailer(mail.SMTPConfig{Host: "localhost", From: "not an address"}); err == nil {
+		t.Error("Expected invalid sender address to be rejected")
+	}
+
+	mailer, err := mail.NewSMTPMailer(mail.SMTPConfig{Host: "localhost", From: "splitty@example.com"})
+	if err != nil {
+		t.Fatalf("Failed to create mailer: %v", err)
+	}
+	if err := mailer.Send(context.Background(), model.EmailMessage{To: "nobody"}); err == nil {
+		t.Error("Expected invalid recipient address to be rejected")
+	}
+}
@@ -0,0 +1,201 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// Sprawdzenie czy implementacja spełnia interfejs
+var _ repository.Mailer = (*SMTPMailer)(nil)
+
+// DefaultTimeout to domyślny limit czasu wysyłki jednej wiadomości
+const DefaultTimeout = 30 * time.Second
+
+// SMTPConfig zawiera konfigurację serwera SMTP
+type SMTPConfig struct {
+	Host     string
+	Port     int // domyślnie 587 (lub 465 przy ImplicitTLS)
+	Username string
+	Password string
+	From     string // np. "Splitty <splitty@example.com>"
+	// ImplicitTLS włącza połączenie TLS od początku sesji (SMTPS); w przeciwnym razie
+	// używane jest STARTTLS, jeśli serwer je oferuje
+	ImplicitTLS bool
+	Timeout     time.Duration
+}
+
+// SMTPMailer wysyła wiadomości przez serwer SMTP
+type SMTPMailer struct {
+	config SMTPConfig
+	from   *mail.Address
+	now    func() time.Time
+}
+
+// NewSMTPMailer tworzy nadawcę wiadomości SMTP
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", config.From, err)
+	}
+	if config.Port == 0 {
+		config.Port = 587
+		if config.ImplicitTLS {
+			config.Port = 465
+		}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	return &SMTPMailer{config: config, from: from, now: time.Now}, nil
+}
+
+// Send wysyła wiadomość do jednego odbiorcy
+func (m *SMTPMailer) Send(ctx context.Context, message model.EmailMessage) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address %q: %w", message.To, err)
+	}
+	to.Name = message.ToName
+
+	data, err := m.buildMessage(to, message)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	client, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := m.deliver(client, to.Address, data); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// dial nawiązuje połączenie z serwerem, włącza szyfrowanie i uwierzytelnia sesję
+func (m *SMTPMailer) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	tlsConfig := &tls.Config{ServerName: m.config.Host}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("smtp dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if m.config.ImplicitTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake: %w", err)
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !m.config.ImplicitTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if m.config.Username != "" {
+		// PlainAuth odmawia wysłania hasła bez TLS, chyba że serwer działa lokalnie
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// deliver przekazuje kopertę i treść wiadomości
+func (m *SMTPMailer) deliver(client *smtp.Client, to string, data []byte) error {
+	if err := client.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return nil
+}
+
+// buildMessage tworzy wiadomość MIME z treścią tekstową w UTF-8
+func (m *SMTPMailer) buildMessage(to *mail.Address, message model.EmailMessage) ([]byte, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	var builder strings.Builder
+	header := func(name, value string) {
+		builder.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", m.from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", m.now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+hex.EncodeToString(id)+"@"+m.senderDomain()+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	builder.WriteString("\r\n")
+
+	writer := quotedprintable.NewWriter(&builder)
+	body := strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return []byte(builder.String()), nil
+}
+
+// senderDomain zwraca domenę adresu nadawcy (do identyfikatorów wiadomości)
+func (m *SMTPMailer) senderDomain() string {
+	if _, domain, ok := strings.Cut(m.from.Address, "@"); ok {
+		return domain
+	}
+	return m.config.Host
+}
@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"context"
+	"log"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
+)
+
+// Notifier wysyła uczestnikom powiadomienia e-mail o zmianach wydarzeń: nowych wydatkach
+// i zamknięciu (archiwizacji) wydarzenia, a na żądanie także przypomnienia o rozliczeniach
+type Notifier struct {
+	eventRepository repository.EventRepository
+	expenseService  *service.ExpenseService
+	mailer          repository.Mailer
+	locale          service.Locale
+	logger          *log.Logger
+}
+
+// NewNotifier tworzy usługę powiadomień; locale to język uczestników, którzy go nie wybrali
+func NewNotifier(
+	eventRepository repository.EventRepository,
+	expenseService *service.ExpenseService,
+	mailer repository.Mailer,
+	locale service.Locale,
+	logger *log.Logger,
+) *Notifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Notifier{
+		eventRepository: eventRepository,
+		expenseService:  expenseService,
+		mailer:          mailer,
+		locale:          locale,
+		logger:          logger,
+	}
+}
+
+// Run subskrybuje zmiany wszystkich wydarzeń i wysyła powiadomienia do czasu anulowania
+// kontekstu. Po odłączeniu przez broker subskrypcja jest odnawiana.
+func (n *Notifier) Run(ctx context.Context, broker pubsub.Broker) {
+	for ctx.Err() == nil {
+		changes, unsubscribe := broker.Subscribe(pubsub.AllEvents)
+		n.consume(ctx, changes)
+		unsubscribe()
+
+		if ctx.Err() == nil {
+			n.logger.Printf("Notifications: subscription dropped by the broker, resubscribing")
+		}
+	}
+}
+
+// consume obsługuje zmiany z kanału do jego zamknięcia lub anulowania kontekstu
+func (n *Notifier) consume(ctx context.Context, changes <-chan model.EventChange) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			n.Notify(ctx, change)
+		}
+	}
+}
+
+// Notify wysyła powiadomienia wynikające ze zmiany; pozostałe rodzaje zmian są pomijane
+func (n *Notifier) Notify(ctx context.Context, change model.EventChange) {
+	if change.Type != model.ChangeExpenseCreated && change.Type != model.ChangeEventArchived {
+		return
+	}
+
+	event, err := n.eventRepository.FindByID(change.EventID)
+	if err != nil {
+		n.logger.Printf("Notifications: event %d: %v", change.EventID, err)
+		return
+	}
+
+	var messages []model.EmailMessage
+	switch change.Type {
+	case model.ChangeExpenseCreated:
+		exp, ok := changedExpense(event, change)
+		if !ok {
+			return
+		}
+		messages = n.expenseService.ExpenseNotifications(event, exp, change.Actor, change.ActorID, n.locale)
+	case model.ChangeEventArchived:
+		messages = n.expenseService.SettlementNotifications(event, n.locale)
+	}
+
+	n.send(ctx, event.ID, messages)
+}
+
+// Remind wysyła przypomnienia o niespłaconych rozliczeniach wydarzenia i zwraca
+// liczbę wysłanych wiadomości
+func (n *Notifier) Remind(ctx context.Context, event *model.Event) int {
+	return n.send(ctx, event.ID, n.expenseService.ReminderNotifications(event, n.locale))
+}
+
+// send wysyła wiadomości, zapisując błędy w logu, i zwraca liczbę wysłanych wiadomości
+func (n *Notifier) send(ctx context.Context, eventID int, messages []model.EmailMessage) int {
+	sent := 0
+	for _, message := range messages {
+		if err := n.mailer.Send(ctx, message); err != nil {
+			n.logger.Printf("Notifications: event %d: failed to send %q to %s: %v", eventID, message.Subject, message.To, err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// changedExpense zwraca wydatek opisany zmianą; jeśli zmiana go nie zawiera, szuka go w wydarzeniu
+func changedExpense(event *model.Event, change model.EventChange) (model.Expense, bool) {
+	if change.Expense != nil {
+		return *change.Expense, true
+	}
+	for _, exp := range event.Expenses {
+		if exp.ID == change.ExpenseID {
+			return exp, true
+		}
+	}
+	return model.Expense{}, false
+}
@@ -0,0 +1,66 @@
+package notification_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/service"
+	"github.com/inflop/splitty.api/internal/infrastructure/notification"
+	"github.com/inflop/splitty.api/internal/infrastructure/repository"
+)
+
+// recordingMailer zapamiętuje wysłane wiadomości
+type recordingMailer struct {
+	mutex    sync.Mutex
+	messages []model.EmailMessage
+}
+
+func (m *recordingMailer) Send(_ context.Context, message model.EmailMessage) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	events := repository.NewInMemoryEventRepository()
+	event := &model.Event{
+		Name: "Trip",
+		Participants: []model.Participant{
+			{ID: 1, Name: "Anna", Email: "anna@example.com"},
+			{ID: 2, Name: "Bartek", Email: "bartek@example.com", Locale: "en"},
+		},
+		Expenses: []model.Expense{
+			{ID: 1, Category: "Food", Description: "Dinner", TotalAmount: 40, SharedWith: []int{1, 2}, Payments: []model.Payment{{ParticipantID: 1, Amount: 40}}},
+		},
+	}
+	if err := events.Save(event); err != nil {
+		t.Fatalf("Failed to save event: %v", err)
+	}
+
+	mailer := &recordingMailer{}
+	notifier := notification.NewNotifier(events, service.NewExpenseService(), mailer, service.LocalePL, log.New(io.Discard, "", 0))
+
+	notifier.Notify(context.Background(), model.EventChange{Type: model.ChangeExpenseCreated, EventID: event.ID, ExpenseID: 1, ActorID: 1})
+	if len(mailer.messages) != 1 || mailer.messages[0].To != "bartek@example.com" || mailer.messages[0].Subject != `New expense in "Trip": Dinner` {
+		t.Fatalf("Expected an English expense notification for Bartek, got %+v", mailer.messages)
+	}
+
+	notifier.Notify(context.Background(), model.EventChange{Type: model.ChangeExpenseUpdated, EventID: event.ID, ExpenseID: 1})
+	if len(mailer.messages) != 1 {
+		t.Fatalf("Expected updates not to be notified, got %+v", mailer.messages)
+	}
+
+	notifier.Notify(context.Background(), model.EventChange{Type: model.ChangeEventArchived, EventID: event.ID})
+	if len(mailer.messages) != 3 || mailer.messages[1].Subject != "Podsumowanie rozliczenia: Trip" {
+		t.Fatalf("Expected settlement summaries for both participants, got %+v", mailer.messages)
+	}
+
+	if sent := notifier.Remind(context.Background(), event); sent != 1 || mailer.messages[3].To != "bartek@example.com" {
+		t.Errorf("Expected a single reminder for Bartek, got %d: %+v", sent, mailer.messages)
+	}
+}
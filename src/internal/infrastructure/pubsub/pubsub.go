@@ -0,0 +1,94 @@
+// Package pubsub rozsyła powiadomienia o zmianach wydarzeń do subskrybentów.
+package pubsub
+
+import (
+	"sync"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// AllEvents to identyfikator subskrypcji zmian wszystkich wydarzeń
+const AllEvents = 0
+
+// subscriberBuffer to liczba zmian buforowanych dla subskrybenta
+const subscriberBuffer = 64
+
+// Broker publikuje zmiany wydarzeń i zarządza subskrypcjami. Implementację w pamięci
+// procesu można zastąpić zewnętrznym brokerem bez zmian w kodzie publikującym.
+type Broker interface {
+	// Publish nadaje zmianie kolejny numer i przekazuje ją subskrybentom wydarzenia
+	// oraz subskrybentom wszystkich wydarzeń
+	Publish(change model.EventChange)
+	// Subscribe zwraca kanał zmian wydarzenia (AllEvents oznacza wszystkie wydarzenia)
+	// i funkcję kończącą subskrypcję. Kanał jest zamykany po zakończeniu subskrypcji.
+	Subscribe(eventID int) (<-chan model.EventChange, func())
+}
+
+// InMemoryBroker to implementacja Broker działająca w obrębie jednego procesu
+type InMemoryBroker struct {
+	mutex       sync.Mutex
+	sequence    uint64
+	subscribers map[int]map[chan model.EventChange]struct{}
+}
+
+// NewInMemoryBroker tworzy nowy broker w pamięci
+func NewInMemoryBroker() *InMemoryBroker {
+	return &InMemoryBroker{subscribers: make(map[int]map[chan model.EventChange]struct{})}
+}
+
+// Publish przekazuje zmianę subskrybentom bez blokowania. Subskrybent, którego bufor
+// jest pełny, jest odłączany (kanał zostaje zamknięty), aby mógł połączyć się ponownie
+// i pobrać aktualny stan zamiast otrzymywać niekompletną sekwencję zmian.
+func (b *InMemoryBroker) Publish(change model.EventChange) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.sequence++
+	change.ID = b.sequence
+
+	for _, topic := range []int{change.EventID, AllEvents} {
+		for ch := range b.subscribers[topic] {
+			select {
+			case ch <- change:
+			default:
+				b.remove(topic, ch)
+			}
+		}
+		if change.EventID == AllEvents {
+			break
+		}
+	}
+}
+
+// Subscribe rejestruje nowego subskrybenta zmian wydarzenia
+func (b *InMemoryBroker) Subscribe(eventID int) (<-chan model.EventChange, func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	ch := make(chan model.EventChange, subscriberBuffer)
+	if b.subscribers[eventID] == nil {
+		b.subscribers[eventID] = make(map[chan model.EventChange]struct{})
+	}
+	b.subscribers[eventID][ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			b.remove(eventID, ch)
+		})
+	}
+}
+
+// remove wyrejestrowuje subskrybenta i zamyka jego kanał (wywoływane pod blokadą)
+func (b *InMemoryBroker) remove(eventID int, ch chan model.EventChange) {
+	if _, exists := b.subscribers[eventID][ch]; !exists {
+		return
+	}
+	delete(b.subscribers[eventID], ch)
+	if len(b.subscribers[eventID]) == 0 {
+		delete(b.subscribers, eventID)
+	}
+	close(ch)
+}
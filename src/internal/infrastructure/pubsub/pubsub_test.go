@@ -0,0 +1,53 @@
+package pubsub_test
+
+import (
+	"testing"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
+)
+
+func TestInMemoryBroker(t *testing.T) {
+	broker := pubsub.NewInMemoryBroker()
+
+	event1, unsubscribe1 := broker.Subscribe(1)
+	all, unsubscribeAll := broker.Subscribe(pubsub.AllEvents)
+	defer unsubscribeAll()
+
+	broker.Publish(model.EventChange{Type: model.ChangeEventUpdated, EventID: 1})
+	broker.Publish(model.EventChange{Type: model.ChangeEventCreated, EventID: 2})
+
+	if change := <-event1; change.ID != 1 || change.Type != model.ChangeEventUpdated {
+		t.Errorf("Unexpected change for event 1: %+v", change)
+	}
+	if len(event1) != 0 {
+		t.Error("Expected subscriber of event 1 not to receive changes of event 2")
+	}
+	if first, second := <-all, <-all; first.ID != 1 || second.ID != 2 || second.EventID != 2 {
+		t.Errorf("Expected global subscriber to receive both changes, got %+v, %+v", first, second)
+	}
+
+	unsubscribe1()
+	unsubscribe1()
+	if _, ok := <-event1; ok {
+		t.Error("Expected channel to be closed after unsubscribe")
+	}
+}
+
+func TestInMemoryBrokerDropsSlowSubscriber(t *testing.T) {
+	broker := pubsub.NewInMemoryBroker()
+	changes, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+
+	for range 100 {
+		broker.Publish(model.EventChange{Type: model.ChangeEventUpdated, EventID: 1})
+	}
+
+	received := 0
+	for range changes {
+		received++
+	}
+	if received == 0 || received >= 100 {
+		t.Errorf("Expected slow subscriber to be disconnected after a full buffer, received %d", received)
+	}
+}
@@ -0,0 +1,496 @@
+// Package qrcode koduje dane binarne jako kod QR (ISO/IEC 18004, tryb bajtowy)
+// i zapisuje go jako obraz PNG lub SVG.
+package qrcode
+
+import (
+	"errors"
+)
+
+// Level to poziom korekcji błędów kodu QR
+type Level int
+
+// Poziomy korekcji błędów (odtwarzalne ok. 7%, 15%, 25% i 30% kodu)
+const (
+	LevelL Level = iota
+	LevelM
+	LevelQ
+	LevelH
+)
+
+// ErrTooLong zwracany jest, gdy dane nie mieszczą się w kodzie QR w wersji 40
+var ErrTooLong = errors.New("data too long for a QR code")
+
+// formatBits to kody poziomów korekcji zapisywane w informacji o formacie
+var formatBits = [4]int{LevelL: 1, LevelM: 0, LevelQ: 3, LevelH: 2}
+
+// eccCodewordsPerBlock to liczba słów korekcyjnych w bloku dla poziomu i wersji (indeks 0 nieużywany)
+var eccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// errorCorrectionBlocks to liczba bloków korekcji błędów dla poziomu i wersji (indeks 0 nieużywany)
+var errorCorrectionBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// Code to zakodowany kod QR: kwadratowa macierz modułów (true oznacza moduł ciemny)
+type Code struct {
+	Version int
+	Size    int
+	modules [][]bool
+	// function oznacza moduły wzorców funkcyjnych, które nie są maskowane
+	function [][]bool
+}
+
+// Encode koduje dane w trybie bajtowym w najmniejszej wersji kodu mieszczącej dane
+// przy podanym poziomie korekcji błędów; maska wybierana jest według reguł kar normy
+func Encode(data []byte, level Level) (*Code, error) {
+	version := 1
+	for ; version <= 40; version++ {
+		if 4+countBits(version)+8*len(data) <= dataCodewords(version, level)*8 {
+			break
+		}
+	}
+	if version > 40 {
+		return nil, ErrTooLong
+	}
+
+	// Tryb bajtowy, długość, dane, terminator i wyrównanie do pełnych bajtów
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := dataCodewords(version, level) * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := bits.bytes()
+	for pad := byte(0xEC); len(codewords) < capacity/8; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+
+	code := newCode(version)
+	code.drawFunctionPatterns(level)
+	code.drawCodewords(addErrorCorrection(codewords, version, level))
+
+	// Wybór maski o najmniejszej karze
+	bestMask, bestPenalty := 0, -1
+	for mask := range 8 {
+		code.applyMask(mask)
+		code.drawFormatBits(level, mask)
+		if penalty := code.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		code.applyMask(mask)
+	}
+	code.applyMask(bestMask)
+	code.drawFormatBits(level, bestMask)
+
+	return code, nil
+}
+
+// Dark sprawdza, czy moduł w kolumnie x i wierszu y jest ciemny
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// newCode tworzy pustą macierz kodu w podanej wersji
+func newCode(version int) *Code {
+	size := version*4 + 17
+	code := &Code{Version: version, Size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for i := range size {
+		code.modules[i] = make([]bool, size)
+		code.function[i] = make([]bool, size)
+	}
+	return code
+}
+
+// setFunction ustawia moduł wzorca funkcyjnego
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawFunctionPatterns rysuje wzorce wyszukiwania, synchronizacji i wyrównania oraz
+// rezerwuje miejsca informacji o formacie i wersji
+func (c *Code) drawFunctionPatterns(level Level) {
+	for i := range c.Size {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	positions := alignmentPositions(c.Version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Pomijane są narożniki zajęte przez wzorce wyszukiwania
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	c.drawFormatBits(level, 0)
+	c.drawVersion()
+}
+
+// drawFinder rysuje wzorzec wyszukiwania wraz z jasnym separatorem wokół środka (x, y)
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx >= 0 && xx < c.Size && yy >= 0 && yy < c.Size {
+				distance := max(abs(dx), abs(dy))
+				c.setFunction(xx, yy, distance != 2 && distance != 4)
+			}
+		}
+	}
+}
+
+// drawFormatBits zapisuje obie kopie informacji o formacie (poziom korekcji i maska)
+func (c *Code) drawFormatBits(level Level, mask int) {
+	data := formatBits[level]<<3 | mask
+	remainder := data
+	for range 10 {
+		remainder = remainder<<1 ^ (remainder>>9)*0x537
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(i))
+	}
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		c.setFunction(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(i))
+	}
+	c.setFunction(8, c.Size-8, true)
+}
+
+// drawVersion zapisuje informację o wersji (dla wersji 7 i wyższych)
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	remainder := c.Version
+	for range 12 {
+		remainder = remainder<<1 ^ (remainder>>11)*0x1F25
+	}
+	bits := c.Version<<12 | remainder
+
+	for i := range 18 {
+		dark := bits>>i&1 != 0
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, dark)
+		c.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords umieszcza słowa kodowe zygzakiem w parach kolumn, od prawego dolnego rogu
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := range c.Size {
+			for j := range 2 {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vertical
+				}
+				if !c.function[y][x] && i < len(codewords)*8 {
+					c.modules[y][x] = codewords[i>>3]>>(7-i&7)&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask odwraca moduły danych według wzorca maski (ponowne użycie cofa maskę)
+func (c *Code) applyMask(mask int) {
+	for y := range c.Size {
+		for x := range c.Size {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.function[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty oblicza karę maski: serie modułów, bloki 2x2, wzorce podobne do wzorca
+// wyszukiwania i odchylenie proporcji modułów ciemnych od 50%
+func (c *Code) penalty() int {
+	penalty := 0
+	finderLike := []bool{true, false, true, true, true, false, true}
+
+	line := make([]bool, c.Size)
+	for _, vertical := range []bool{false, true} {
+		for i := range c.Size {
+			for j := range c.Size {
+				if vertical {
+					line[j] = c.modules[j][i]
+				} else {
+					line[j] = c.modules[i][j]
+				}
+			}
+
+			run := 1
+			for j := 1; j <= c.Size; j++ {
+				if j < c.Size && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += 3 + run - 5
+				}
+				run = 1
+			}
+
+			for j := 0; j+7 <= c.Size; j++ {
+				if !matches(line[j:j+7], finderLike) {
+					continue
+				}
+				if lightRun(line, j-4, j) || lightRun(line, j+7, j+11) {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := range c.Size {
+		for x := range c.Size {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				color := c.modules[y][x]
+				if c.modules[y][x+1] == color && c.modules[y+1][x] == color && c.modules[y+1][x+1] == color {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return penalty + max(k, 0)*10
+}
+
+// matches porównuje fragment linii z wzorcem
+func matches(line, pattern []bool) bool {
+	for i := range pattern {
+		if line[i] != pattern[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lightRun sprawdza, czy moduły w zakresie [from, to) są jasne; moduły poza kodem
+// (strefa ciszy) traktowane są jako jasne
+func lightRun(line []bool, from, to int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// alignmentPositions zwraca współrzędne środków wzorców wyrównania dla wersji
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	count := version/7 + 2
+	step := (version*8 + count*3 + 5) / (count*4 - 4) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, position := count-1, version*4+10; i >= 1; i, position = i-1, position-step {
+		positions[i] = position
+	}
+	return positions
+}
+
+// rawDataModules zwraca liczbę modułów dostępnych dla danych i korekcji błędów w wersji
+func rawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		count := version/7 + 2
+		result -= (25*count-10)*count - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// dataCodewords zwraca liczbę słów kodowych danych (bez korekcji błędów)
+func dataCodewords(version int, level Level) int {
+	return rawDataModules(version)/8 - eccCodewordsPerBlock[level][version]*errorCorrectionBlocks[level][version]
+}
+
+// countBits zwraca liczbę bitów pola długości danych w trybie bajtowym
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// addErrorCorrection dzieli dane na bloki, dodaje do nich słowa korekcyjne Reeda-Solomona
+// i przeplata bloki w kolejności zapisu w kodzie
+func addErrorCorrection(data []byte, version int, level Level) []byte {
+	blockCount := errorCorrectionBlocks[level][version]
+	eccLength := eccCodewordsPerBlock[level][version]
+	rawCodewords := rawDataModules(version) / 8
+	shortBlocks := blockCount - rawCodewords%blockCount
+	shortLength := rawCodewords / blockCount
+
+	divisor := reedSolomonDivisor(eccLength)
+	blocks := make([][]byte, blockCount)
+	offset := 0
+	for i := range blockCount {
+		length := shortLength - eccLength
+		if i >= shortBlocks {
+			length++
+		}
+		block := append([]byte(nil), data[offset:offset+length]...)
+		offset += length
+		ecc := reedSolomonRemainder(block, divisor)
+		if i < shortBlocks {
+			// Miejsce wyrównujące krótkie bloki do długości długich, pomijane przy przeplocie
+			block = append(block, 0)
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortLength-eccLength || j >= shortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor zwraca współczynniki wielomianu generującego podanego stopnia
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder zwraca słowa korekcyjne (resztę z dzielenia danych przez dzielnik)
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply mnoży elementy ciała GF(2^8) z wielomianem redukcyjnym 0x11D
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// abs zwraca wartość bezwzględną liczby całkowitej
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// bitBuffer to bufor bitów zapisywanych od najstarszego
+type bitBuffer []bool
+
+// append dopisuje length najmłodszych bitów wartości
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 != 0)
+	}
+}
+
+// bytes zamienia bufor (o długości podzielnej przez 8) na bajty
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
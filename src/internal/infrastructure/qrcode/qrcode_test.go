@@ -0,0 +1,198 @@
+package qrcode_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/inflop/splitty.api/internal/infrastructure/qrcode"
+)
+
+const epcPayload = "BCD\n002\n1\nSCT\nBPOTBEB1\nRed Cross of Belgium\nBE72000000001616\nEUR1.00\n\n\nUrgency fund"
+
+func TestEncode(t *testing.T) {
+	code, err := qrcode.Encode([]byte(epcPayload), qrcode.LevelM)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	// 83 bajty przy poziomie M wymagają wersji 5 (86 słów danych)
+	if code.Version != 5 || code.Size != 37 {
+		t.Errorf("Expected version 5 (37x37), got %d (%dx%d)", code.Version, code.Size, code.Size)
+	}
+
+	// Wzorce wyszukiwania w trzech narożnikach: ciemna ramka, jasny pierścień, ciemny środek
+	for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+		x, y := corner[0], corner[1]
+		if !code.Dark(x, y) || !code.Dark(x+6, y+6) || code.Dark(x+1, y+1) || !code.Dark(x+3, y+3) {
+			t.Errorf("Missing finder pattern at %v", corner)
+		}
+	}
+	if code.Dark(-1, 0) || code.Dark(0, code.Size) {
+		t.Error("Modules outside the code must be light")
+	}
+
+	if _, err := qrcode.Encode(bytes.Repeat([]byte{'x'}, 3000), qrcode.LevelH); !errors.Is(err, qrcode.ErrTooLong) {
+		t.Errorf("Expected ErrTooLong, got %v", err)
+	}
+}
+
+// knownCodes to kody wygenerowane niezależnymi koderami (rsc.io/qr i github.com/skip2/go-qrcode,
+// oba dają identyczne macierze); obejmują wersję z informacją o wersji i bloki różnej długości
+var knownCodes = []struct {
+	data    string
+	level   qrcode.Level
+	version int
+	matrix  string
+}{
+	{"splitty", qrcode.LevelM, 1, `
+#######.#.....#######
+#.....#.####..#.....#
+#.###.#.##....#.###.#
+#.###.#...##..#.###.#
+#.###.#.#..##.#.###.#
+#.....#..#....#.....#
+#######.#.#.#.#######
+..........#..........
+#..######..#.#..#.###
+.####...##.###..#.##.
+.#..###.#.###..#.####
+#.##....###.#####.###
+.#.####.#.######.#.##
+........#.#.######...
+#######.####....#.#..
+#.....#.#.#...#.####.
+#.###.#.#....##..#.##
+#.###.#.#.#.####.#...
+#.###.#....###..#.###
+#.....#..####....####
+#######.#.#.##..##...`},
+	{"hello, world", qrcode.LevelL, 1, `
+#######...#.#.#######
+#.....#.#.#.#.#.....#
+#.###.#.#.##..#.###.#
+#.###.#.....#.#.###.#
+#.###.#.#####.#.###.#
+#.....#.###...#.....#
+#######.#.#.#.#######
+........#............
+##.#..##..###.###.##.
+#.##.#.###.#....#..##
+#..#..#..###...#.##.#
+#.##.#.#.#..#.##.#.##
+...##.#.#.##....#....
+........#..#.###..#.#
+#######.#.#####.####.
+#.....#....#...#...#.
+#.###.#...###..##....
+#.###.#.#...#########
+#.###.#..####...#.#.#
+#.....#.#..#.#.......
+#######.#.#...##.#.#.`},
+	{"the quick brown fox jumps over the lazy dog, zażółć gęślą jaźń; splitty", qrcode.LevelQ, 7, `
+#######.#...##...##.##.###..###..#..#.#######
+#.....#.##.#...#.#..#.#.#..#....##.#..#.....#
+#.###.#...##.....##..##..#####.....#..#.###.#
+#.###.#..###.#...#...##.....####...##.#.###.#
+#.###.#.....##.#..#.#######.###.#.###.#.###.#
+#.....#..##.####...##...#..#.#.###....#.....#
+#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######
+.........#.##.#####.#...##..#.#.##.##........
+.#....###........#..#####....###.#...#.....##
+####...#####.#.#...##..####.###....##.#####..
+###..#######.####..#..###.##.#..##..#.#..#.#.
+.#.#.#.##.##.##.....####....###..#...#..#.#.#
+#.#.#.##...#........##..###.#.#..###.#..##..#
+#.##.....###...#..###....#.....###..##...##.#
+.###..#.##..##.#..###..##.##..#...#.#.##..##.
+..#.##.##....#..####.###.##...#..##...###..#.
+.#.#.#####.####.#.#.#...#....###...#...#####.
+.##.#.....##.....#...###....##...#.###.#..###
+####.###.....#..#...###....####..#..#...###.#
+#.#..#...#.##..###...#.#...#..#.#...#.#####..
+...######.#..#.##..#######.#.###.#.#######...
+###.#...###.#.##.####...#.###.#######...###..
+#...#.#.#...#.##.#.##.#.##.#.###.####.#.#.#..
+.#..#...#..##...#..##...##....#.##..#...#####
+###.#####.#.###.#.#.#######..##.#.#.######.#.
+.#.##...####.##.#..##...##..#####...#.##.#...
+#.########.#....###.##...####....#####.#...##
+....##.##.##.#.#...#..##..#.#.#.##......#.##.
+...#..#.####..#.##.#..##...##.#..#.##.###..#.
+.###.....#..##...###...#.#...###.##..##..##.#
+..#.###.##..#..#..#......#..#..#.##.#.##..#.#
+..###..####..#.#....##...##.#...##....#..####
+.###..##...#...#..#....#.###...#...#.#####.##
+#.#.##...#####...###...#.#.####.#.#.#.####.#.
+....#.#.#.#####..#......#.####...##.##.#..##.
+.####...##.#..##..#.#.#.#...#.####.#..#...###
+#..##.######.##.#...#####.####.#.#########...
+........#.#..#..#.###...#.####.###..#...#..##
+#######.#...#.###...#.#.#..#..#...#.#.#.####.
+#.....#..#.#.##....##...##.#.##....##...##.#.
+#.###.#.....##.###.######.#..###....#####.###
+#.###.#..#..#####..#........#....#.#....#.##.
+#.###.#..#.##....#.#.##.##.##..###..###.#...#
+#.....#.#.###.#.#..##.#...#..#.##.##.#...##..
+#######..###.#..#.###..####.#..#.###.#####.#.`},
+}
+
+func TestEncodeKnownAnswers(t *testing.T) {
+	for _, known := range knownCodes {
+		code, err := qrcode.Encode([]byte(known.data), known.level)
+		if err != nil {
+			t.Fatalf("Failed to encode %q: %v", known.data, err)
+		}
+		if code.Version != known.version {
+			t.Errorf("Expected version %d for %q, got %d", known.version, known.data, code.Version)
+			continue
+		}
+
+		var matrix strings.Builder
+		for y := range code.Size {
+			matrix.WriteByte('\n')
+			for x := range code.Size {
+				if code.Dark(x, y) {
+					matrix.WriteByte('#')
+				} else {
+					matrix.WriteByte('.')
+				}
+			}
+		}
+		if matrix.String() != known.matrix {
+			t.Errorf("Unexpected matrix for %q:%s", known.data, matrix.String())
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	code, err := qrcode.Encode([]byte("splitty"), qrcode.LevelM)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := qrcode.WritePNG(&buf, code, 4); err != nil {
+		t.Fatalf("Failed to write PNG: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	size := (code.Size + 2*qrcode.QuietZone) * 4
+	if bounds := img.Bounds(); bounds.Dx() != size || bounds.Dy() != size {
+		t.Errorf("Expected %dx%d image, got %v", size, size, bounds)
+	}
+	if r, _, _, _ := img.At(qrcode.QuietZone*4, qrcode.QuietZone*4).RGBA(); r != 0 {
+		t.Error("Expected dark top-left finder module")
+	}
+
+	buf.Reset()
+	if err := qrcode.WriteSVG(&buf, code, 4); err != nil {
+		t.Fatalf("Failed to write SVG: %v", err)
+	}
+	if svg := buf.String(); !strings.Contains(svg, `viewBox="0 0 29 29"`) || !strings.Contains(svg, "M4,4h1v1h-1z") {
+		t.Errorf("Unexpected SVG: %.200s", svg)
+	}
+}
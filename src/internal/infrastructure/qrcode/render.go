@@ -0,0 +1,61 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// QuietZone to szerokość jasnego marginesu wokół kodu (w modułach) wymagana przez normę
+const QuietZone = 4
+
+// WritePNG zapisuje kod jako obraz PNG, w którym moduł ma scale pikseli
+func WritePNG(w io.Writer, code *Code, scale int) error {
+	scale = max(scale, 1)
+	size := (code.Size + 2*QuietZone) * scale
+	palette := color.Palette{color.White, color.Black}
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+
+	for y := range code.Size {
+		for x := range code.Size {
+			if !code.Dark(x, y) {
+				continue
+			}
+			left, top := (x+QuietZone)*scale, (y+QuietZone)*scale
+			for dy := range scale {
+				for dx := range scale {
+					img.SetColorIndex(left+dx, top+dy, 1)
+				}
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// WriteSVG zapisuje kod jako grafikę SVG z jedną ścieżką ciemnych modułów;
+// scale określa rozmiar modułu w pikselach w atrybutach width i height
+func WriteSVG(w io.Writer, code *Code, scale int) error {
+	scale = max(scale, 1)
+	size := code.Size + 2*QuietZone
+
+	var path strings.Builder
+	for y := range code.Size {
+		for x := range code.Size {
+			if code.Dark(x, y) {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+QuietZone, y+QuietZone)
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" version="1.1" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">
+<rect width="100%%" height="100%%" fill="#FFFFFF"/>
+<path d="%s" fill="#000000"/>
+</svg>
+`, size, size, size*scale, size*scale, path.String())
+	return err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// Sprawdzenie czy implementacja spełnia interfejs
+var _ repository.GroupRepository = (*InMemoryGroupRepository)(nil)
+
+// InMemoryGroupRepository implementacja repozytorium grup w pamięci
+type InMemoryGroupRepository struct {
+	groups map[int]*model.Group
+	nextID int
+	mutex  sync.RWMutex
+}
+
+// NewInMemoryGroupRepository tworzy nowe repozytorium grup w pamięci
+func NewInMemoryGroupRepository() *InMemoryGroupRepository {
+	return &InMemoryGroupRepository{
+		groups: make(map[int]*model.Group),
+		nextID: 1,
+	}
+}
+
+// Save zapisuje grupę
+func (r *InMemoryGroupRepository) Save(group *model.Group) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if group.ID == 0 {
+		group.ID = r.nextID
+		r.nextID++
+	}
+
+	// Znaczniki czasu są zarządzane przez serwer
+	now := time.Now().UTC()
+	group.CreatedAt = now
+	if previous, exists := r.groups[group.ID]; exists {
+		group.CreatedAt = previous.CreatedAt
+	}
+	group.UpdatedAt = now
+
+	r.groups[group.ID] = copyGroup(group)
+	return nil
+}
+
+// FindByID znajduje grupę po ID
+func (r *InMemoryGroupRepository) FindByID(id int) (*model.Group, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	group, exists := r.groups[id]
+	if !exists {
+		return nil, errors.New("group not found")
+	}
+
+	return copyGroup(group), nil
+}
+
+// Delete usuwa grupę
+func (r *InMemoryGroupRepository) Delete(id int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.groups[id]; !exists {
+		return errors.New("group not found")
+	}
+
+	delete(r.groups, id)
+	return nil
+}
+
+// FindAll zwraca wszystkie grupy
+func (r *InMemoryGroupRepository) FindAll() ([]*model.Group, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	groups := make([]*model.Group, 0, len(r.groups))
+	for _, group := range r.groups {
+		groups = append(groups, copyGroup(group))
+	}
+
+	return groups, nil
+}
+
+// Funkcja pomocnicza do głębokiego kopiowania obiektów Group
+func copyGroup(group *model.Group) *model.Group {
+	if group == nil {
+		return nil
+	}
+
+	newGroup := *group
+	newGroup.Members = append([]model.GroupMember(nil), group.Members...)
+	newGroup.EventIDs = append([]int(nil), group.EventIDs...)
+	return &newGroup
+}
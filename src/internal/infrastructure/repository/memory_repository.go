@@ -2,7 +2,9 @@ package repository
 
 import (
 	"errors"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/inflop/splitty.api/internal/domain/model"
 	"github.com/inflop/splitty.api/internal/domain/repository"
@@ -36,6 +38,25 @@ func (r *InMemoryEventRepository) Save(event *model.Event) error {
 		r.nextID++
 	}
 
+	// Zapis oparty na nieaktualnej wersji nadpisałby równoczesne zmiany
+	version := 0
+	if previous, exists := r.events[event.ID]; exists {
+		if event.Version != previous.Version {
+			return repository.ErrVersionConflict
+		}
+		version = previous.Version
+	}
+	event.Version = version + 1
+
+	// Znaczniki czasu są zarządzane przez serwer
+	stampEvent(r.events[event.ID], event, time.Now().UTC())
+
+	// Stan kosza zmieniany jest wyłącznie przez SoftDelete i Restore
+	event.DeletedAt = time.Time{}
+	if previous, exists := r.events[event.ID]; exists {
+		event.DeletedAt = previous.DeletedAt
+	}
+
 	// Głębokie kopiowanie obiektu aby uniknąć problemów z współdzieleniem referencji
 	eventCopy := copyEvent(event)
 	r.events[event.ID] = eventCopy
@@ -52,7 +73,7 @@ func (r *InMemoryEventRepository) FindByID(id int) (*model.Event, error) {
 	defer r.mutex.RUnlock()
 
 	event, exists := r.events[id]
-	if !exists {
+	if !exists || !event.DeletedAt.IsZero() {
 		return nil, errors.New("event not found")
 	}
 
@@ -60,7 +81,7 @@ func (r *InMemoryEventRepository) FindByID(id int) (*model.Event, error) {
 	return copyEvent(event), nil
 }
 
-// Delete usuwa wydarzenie
+// Delete trwale usuwa wydarzenie, także znajdujące się w koszu
 func (r *InMemoryEventRepository) Delete(id int) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -80,12 +101,91 @@ func (r *InMemoryEventRepository) FindAll() ([]*model.Event, error) {
 
 	events := make([]*model.Event, 0, len(r.events))
 	for _, event := range r.events {
-		events = append(events, copyEvent(event))
+		if event.DeletedAt.IsZero() {
+			events = append(events, copyEvent(event))
+		}
+	}
+
+	return events, nil
+}
+
+// SoftDelete przenosi wydarzenie do kosza
+func (r *InMemoryEventRepository) SoftDelete(id int, at time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	event, exists := r.events[id]
+	if !exists || !event.DeletedAt.IsZero() {
+		return errors.New("event not found")
+	}
+
+	event.DeletedAt = at
+	return nil
+}
+
+// Restore przywraca wydarzenie z kosza
+func (r *InMemoryEventRepository) Restore(id int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	event, exists := r.events[id]
+	if !exists || event.DeletedAt.IsZero() {
+		return errors.New("event not found in trash")
+	}
+
+	event.DeletedAt = time.Time{}
+	return nil
+}
+
+// FindDeleted zwraca wydarzenia znajdujące się w koszu
+func (r *InMemoryEventRepository) FindDeleted() ([]*model.Event, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	events := make([]*model.Event, 0)
+	for _, event := range r.events {
+		if !event.DeletedAt.IsZero() {
+			events = append(events, copyEvent(event))
+		}
 	}
 
 	return events, nil
 }
 
+// Funkcja pomocnicza ustawiająca znaczniki czasu wydarzenia i jego wydatków.
+// Zachowuje czas utworzenia z poprzedniej wersji, a czas modyfikacji wydatku
+// zmienia tylko wtedy, gdy wydatek faktycznie się zmienił.
+func stampEvent(previous, event *model.Event, now time.Time) {
+	event.CreatedAt = now
+	if previous != nil {
+		event.CreatedAt = previous.CreatedAt
+	}
+	event.UpdatedAt = now
+
+	previousExpenses := make(map[int]model.Expense)
+	if previous != nil {
+		for _, e := range previous.Expenses {
+			previousExpenses[e.ID] = e
+		}
+	}
+
+	for i := range event.Expenses {
+		expense := &event.Expenses[i]
+		old, exists := previousExpenses[expense.ID]
+		if !exists {
+			expense.CreatedAt = now
+			expense.UpdatedAt = now
+			continue
+		}
+
+		expense.CreatedAt = old.CreatedAt
+		expense.UpdatedAt = old.UpdatedAt
+		if !sameExpense(old, *expense) {
+			expense.UpdatedAt = now
+		}
+	}
+}
+
 // Funkcja pomocnicza do głębokiego kopiowania obiektów Event
 func copyEvent(event *model.Event) *model.Event {
 	if event == nil {
@@ -93,8 +193,19 @@ func copyEvent(event *model.Event) *model.Event {
 	}
 
 	newEvent := &model.Event{
-		ID:   event.ID,
-		Name: event.Name,
+		ID:              event.ID,
+		Name:            event.Name,
+		Currency:        event.Currency,
+		StartDate:       event.StartDate,
+		ShareByPresence: event.ShareByPresence,
+		ReminderDays:    event.ReminderDays,
+		EndDate:         event.EndDate,
+		Version:         event.Version,
+		CreatedAt:       event.CreatedAt,
+		UpdatedAt:       event.UpdatedAt,
+		SettledAt:       event.SettledAt,
+		ArchivedAt:      event.ArchivedAt,
+		DeletedAt:       event.DeletedAt,
 	}
 
 	// Kopiowanie uczestników
@@ -102,9 +213,19 @@ func copyEvent(event *model.Event) *model.Event {
 		newEvent.Participants = make([]model.Participant, len(event.Participants))
 		for i, p := range event.Participants {
 			newEvent.Participants[i] = model.Participant{
-				ID:    p.ID,
-				Name:  p.Name,
-				Email: p.Email,
+				ID:            p.ID,
+				Name:          p.Name,
+				Email:         p.Email,
+				UserID:        p.UserID,
+				IBAN:          p.IBAN,
+				BIC:           p.BIC,
+				AccountHolder: p.AccountHolder,
+				Phone:         p.Phone,
+				Locale:        p.Locale,
+			}
+			if len(p.Presence) > 0 {
+				newEvent.Participants[i].Presence = make([]model.PresencePeriod, len(p.Presence))
+				copy(newEvent.Participants[i].Presence, p.Presence)
 			}
 		}
 	}
@@ -113,32 +234,168 @@ func copyEvent(event *model.Event) *model.Event {
 	if len(event.Expenses) > 0 {
 		newEvent.Expenses = make([]model.Expense, len(event.Expenses))
 		for i, e := range event.Expenses {
-			expense := model.Expense{
-				ID:          e.ID,
-				Category:    e.Category,
-				TotalAmount: e.TotalAmount,
-			}
+			newEvent.Expenses[i] = copyExpense(e)
+		}
+	}
 
-			// Kopiowanie płatności
-			if len(e.Payments) > 0 {
-				expense.Payments = make([]model.Payment, len(e.Payments))
-				for j, p := range e.Payments {
-					expense.Payments[j] = model.Payment{
-						ParticipantID: p.ParticipantID,
-						Amount:        p.Amount,
+	// Kopiowanie wydatków cyklicznych
+	if len(event.Recurring) > 0 {
+		newEvent.Recurring = make([]model.RecurringExpense, len(event.Recurring))
+		for i, rec := range event.Recurring {
+			recurring := rec
+			recurring.Template = copyExpense(rec.Template)
+			if len(rec.Exceptions) > 0 {
+				recurring.Exceptions = make([]model.RecurrenceException, len(rec.Exceptions))
+				for j, ex := range rec.Exceptions {
+					recurring.Exceptions[j] = ex
+					if ex.Override != nil {
+						override := copyExpense(*ex.Override)
+						recurring.Exceptions[j].Override = &override
 					}
 				}
 			}
+			newEvent.Recurring[i] = recurring
+		}
+	}
 
-			// Kopiowanie sharedWith
-			if len(e.SharedWith) > 0 {
-				expense.SharedWith = make([]int, len(e.SharedWith))
-				copy(expense.SharedWith, e.SharedWith)
-			}
+	// Kopiowanie zamkniętych okresów i bilansów otwarcia
+	if len(event.Periods) > 0 {
+		newEvent.Periods = make([]model.Period, len(event.Periods))
+		for i, p := range event.Periods {
+			period := p
+			period.OpeningBalances = copyOpeningBalances(p.OpeningBalances)
+			period.Summary = copySummary(p.Summary)
+			newEvent.Periods[i] = period
+		}
+	}
+	newEvent.OpeningBalances = copyOpeningBalances(event.OpeningBalances)
+
+	// Kopiowanie spłat
+	if len(event.Repayments) > 0 {
+		newEvent.Repayments = make([]model.Repayment, len(event.Repayments))
+		copy(newEvent.Repayments, event.Repayments)
+	}
 
-			newEvent.Expenses[i] = expense
+	// Kopiowanie gospodarstw domowych
+	if len(event.Households) > 0 {
+		newEvent.Households = make([]model.Household, len(event.Households))
+		for i, h := range event.Households {
+			household := h
+			household.Members = append([]int(nil), h.Members...)
+			newEvent.Households[i] = household
+		}
+	}
+
+	// Kopiowanie katalogu kategorii
+	if len(event.Categories) > 0 {
+		newEvent.Categories = make([]model.Category, len(event.Categories))
+		for i, c := range event.Categories {
+			category := c
+			if len(c.Aliases) > 0 {
+				category.Aliases = make([]string, len(c.Aliases))
+				copy(category.Aliases, c.Aliases)
+			}
+			newEvent.Categories[i] = category
 		}
 	}
 
 	return newEvent
 }
+
+// Funkcja pomocnicza do głębokiego kopiowania obiektów Expense
+func copyExpense(e model.Expense) model.Expense {
+	expense := model.Expense{
+		ID:            e.ID,
+		Kind:          e.Kind,
+		Category:      e.Category,
+		TotalAmount:   e.TotalAmount,
+		Date:          e.Date,
+		Description:   e.Description,
+		Merchant:      e.Merchant,
+		Notes:         e.Notes,
+		Tax:           e.Tax,
+		ServiceCharge: e.ServiceCharge,
+		Tip:           e.Tip,
+		Discount:      e.Discount,
+		RecurringID:   e.RecurringID,
+		PeriodID:      e.PeriodID,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+	}
+
+	// Kopiowanie płatności
+	if len(e.Payments) > 0 {
+		expense.Payments = make([]model.Payment, len(e.Payments))
+		for j, p := range e.Payments {
+			expense.Payments[j] = model.Payment{
+				ParticipantID: p.ParticipantID,
+				Amount:        p.Amount,
+			}
+		}
+	}
+
+	// Kopiowanie sharedWith
+	if len(e.SharedWith) > 0 {
+		expense.SharedWith = make([]int, len(e.SharedWith))
+		copy(expense.SharedWith, e.SharedWith)
+	}
+
+	// Kopiowanie pozycji rachunku
+	if len(e.Items) > 0 {
+		expense.Items = make([]model.ExpenseItem, len(e.Items))
+		for j, item := range e.Items {
+			expense.Items[j] = item
+			if len(item.Participants) > 0 {
+				expense.Items[j].Participants = make([]int, len(item.Participants))
+				copy(expense.Items[j].Participants, item.Participants)
+			}
+		}
+	}
+
+	// Kopiowanie załączników
+	if len(e.Attachments) > 0 {
+		expense.Attachments = make([]model.Attachment, len(e.Attachments))
+		copy(expense.Attachments, e.Attachments)
+	}
+
+	return expense
+}
+
+// Funkcja pomocnicza porównująca wydatki z pominięciem znaczników czasu
+func sameExpense(a, b model.Expense) bool {
+	a, b = copyExpense(a), copyExpense(b)
+	a.CreatedAt, a.UpdatedAt = time.Time{}, time.Time{}
+	b.CreatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+// Funkcja pomocnicza do kopiowania bilansów otwarcia
+func copyOpeningBalances(balances []model.OpeningBalance) []model.OpeningBalance {
+	if len(balances) == 0 {
+		return nil
+	}
+	result := make([]model.OpeningBalance, len(balances))
+	copy(result, balances)
+	return result
+}
+
+// Funkcja pomocnicza do głębokiego kopiowania migawki podsumowania
+func copySummary(summary model.Summary) model.Summary {
+	result := summary
+	result.PaidByPerson = append([]model.ParticipantBalance(nil), summary.PaidByPerson...)
+	result.Settlements = append([]model.Settlement(nil), summary.Settlements...)
+	result.Categories = nil
+	for _, c := range summary.Categories {
+		category := c
+		category.ByParticipant = append([]model.CategoryShare(nil), c.ByParticipant...)
+		result.Categories = append(result.Categories, category)
+	}
+	result.HouseholdSettlements = append([]model.Settlement(nil), summary.HouseholdSettlements...)
+	result.HouseholdBalances = nil
+	for _, h := range summary.HouseholdBalances {
+		household := h
+		household.Members = append([]int(nil), h.Members...)
+		result.HouseholdBalances = append(result.HouseholdBalances, household)
+	}
+	return result
+}
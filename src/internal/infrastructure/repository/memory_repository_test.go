@@ -1,9 +1,13 @@
 package repository_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/inflop/splitty.api/internal/domain/model"
+	domain "github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/domain/service"
 	"github.com/inflop/splitty.api/internal/infrastructure/repository"
 )
 
@@ -114,3 +118,184 @@ func TestDelete(t *testing.T) {
 		t.Fatal("Expected error when finding deleted event")
 	}
 }
+
+func TestSaveManagesTimestamps(t *testing.T) {
+	// Utworzenie repozytorium
+	repo := repository.NewInMemoryEventRepository()
+
+	event := &model.Event{
+		Name: "Test Event",
+		Expenses: []model.Expense{
+			{ID: 1, Category: "Food", TotalAmount: 100, Date: model.NewDate(2024, 7, 1)},
+			{ID: 2, Category: "Fuel", TotalAmount: 50},
+		},
+	}
+	if err := repo.Save(event); err != nil {
+		t.Fatalf("Failed to save event: %v", err)
+	}
+
+	saved, _ := repo.FindByID(event.ID)
+	if saved.CreatedAt.IsZero() || saved.UpdatedAt.IsZero() {
+		t.Fatal("Expected event timestamps to be set")
+	}
+	if saved.Expenses[0].CreatedAt.IsZero() {
+		t.Fatal("Expected expense timestamps to be set")
+	}
+	if !saved.Expenses[0].Date.Equal(model.NewDate(2024, 7, 1).Time) {
+		t.Errorf("Expected expense date to be copied, got %v", saved.Expenses[0].Date)
+	}
+
+	// Aktualizacja - klient nie może nadpisać czasu utworzenia
+	update := *saved
+	update.CreatedAt = time.Time{}
+	update.Expenses = []model.Expense{saved.Expenses[0], saved.Expenses[1]}
+	update.Expenses[0].CreatedAt = time.Time{}
+	update.Expenses[1].TotalAmount = 60
+
+	time.Sleep(time.Millisecond)
+	if err := repo.Save(&update); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+
+	updated, _ := repo.FindByID(event.ID)
+	if !updated.CreatedAt.Equal(saved.CreatedAt) {
+		t.Errorf("Expected event creation time to be preserved")
+	}
+	if !updated.Expenses[0].CreatedAt.Equal(saved.Expenses[0].CreatedAt) {
+		t.Errorf("Expected expense creation time to be preserved")
+	}
+	if !updated.Expenses[0].UpdatedAt.Equal(saved.Expenses[0].UpdatedAt) {
+		t.Errorf("Expected unchanged expense to keep its modification time")
+	}
+	if !updated.Expenses[1].UpdatedAt.After(saved.Expenses[1].UpdatedAt) {
+		t.Errorf("Expected changed expense to get a new modification time")
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	repo := repository.NewInMemoryEventRepository()
+
+	event := &model.Event{Name: "Test Event"}
+	if err := repo.Save(event); err != nil {
+		t.Fatalf("Failed to save event: %v", err)
+	}
+
+	if err := repo.SoftDelete(event.ID, time.Now()); err != nil {
+		t.Fatalf("Failed to soft delete event: %v", err)
+	}
+
+	// Wydarzenie w koszu jest niewidoczne dla zwykłych odczytów
+	if _, err := repo.FindByID(event.ID); err == nil {
+		t.Error("Expected deleted event to be hidden from FindByID")
+	}
+	if events, _ := repo.FindAll(); len(events) != 0 {
+		t.Errorf("Expected no active events, got %d", len(events))
+	}
+	if deleted, _ := repo.FindDeleted(); len(deleted) != 1 || deleted[0].DeletedAt.IsZero() {
+		t.Errorf("Expected one event in trash, got %+v", deleted)
+	}
+
+	// Zapis nie zmienia stanu kosza
+	event.DeletedAt = time.Time{}
+	if err := repo.Save(event); err != nil {
+		t.Fatalf("Failed to save event: %v", err)
+	}
+	if _, err := repo.FindByID(event.ID); err == nil {
+		t.Error("Expected Save not to restore a deleted event")
+	}
+
+	if err := repo.Restore(event.ID); err != nil {
+		t.Fatalf("Failed to restore event: %v", err)
+	}
+	restored, err := repo.FindByID(event.ID)
+	if err != nil || !restored.DeletedAt.IsZero() {
+		t.Errorf("Expected restored event, got %+v (%v)", restored, err)
+	}
+
+	if err := repo.Restore(event.ID); err == nil {
+		t.Error("Expected error restoring event that is not in trash")
+	}
+}
+
+func TestSaveChecksVersion(t *testing.T) {
+	repo := repository.NewInMemoryEventRepository()
+
+	event := &model.Event{Name: "Test Event"}
+	if err := repo.Save(event); err != nil {
+		t.Fatalf("Failed to save event: %v", err)
+	}
+	if event.Version != 1 {
+		t.Fatalf("Expected new event to get version 1, got %d", event.Version)
+	}
+
+	first, _ := repo.FindByID(event.ID)
+	second, _ := repo.FindByID(event.ID)
+
+	first.Name = "First"
+	if err := repo.Save(first); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected version 2 after update, got %d", first.Version)
+	}
+
+	// Zapis oparty na nieaktualnej wersji jest odrzucany
+	second.Name = "Second"
+	if err := repo.Save(second); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+	if stored, _ := repo.FindByID(event.ID); stored.Name != "First" || stored.Version != 2 {
+		t.Errorf("Expected stored event to be unchanged, got %q version %d", stored.Name, stored.Version)
+	}
+}
+
+func TestSavePreservesClosedPeriods(t *testing.T) {
+	repo := repository.NewInMemoryEventRepository()
+	expenseService := service.NewExpenseService()
+
+	event := &model.Event{
+		Name:         "Test Event",
+		Participants: []model.Participant{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}},
+		Expenses: []model.Expense{
+			{ID: 1, TotalAmount: 100, Date: model.NewDate(2024, 1, 10), Payments: []model.Payment{{ParticipantID: 1, Amount: 100}}, SharedWith: []int{1, 2}},
+		},
+	}
+	if err := repo.Save(event); err != nil {
+		t.Fatalf("Failed to save event: %v", err)
+	}
+	if _, err := expenseService.ClosePeriod(event, model.NewDate(2024, 1, 31), time.Now()); err != nil {
+		t.Fatalf("Failed to close period: %v", err)
+	}
+	if err := repo.Save(event); err != nil {
+		t.Fatalf("Failed to save event: %v", err)
+	}
+
+	stored, err := repo.FindByID(event.ID)
+	if err != nil {
+		t.Fatalf("Failed to find event: %v", err)
+	}
+	if stored.Expenses[0].PeriodID != 1 {
+		t.Fatalf("Expected expense to stay in period 1, got %d", stored.Expenses[0].PeriodID)
+	}
+
+	// Wydatki zamkniętego okresu nie mogą być liczone ponownie obok bilansów otwarcia
+	summary := expenseService.CalculateSummary(stored)
+	if alice, bob := summary.PaidByPerson[0], summary.PaidByPerson[1]; alice.Balance != 50 || bob.Balance != -50 {
+		t.Errorf("Expected balances +50/-50 after reload, got %v/%v", alice.Balance, bob.Balance)
+	}
+
+	// Zapis z niezmienionymi wydatkami zamkniętego okresu jest dozwolony, zmiana nie
+	update := *stored
+	update.Expenses = append([]model.Expense{stored.Expenses[0]},
+		model.Expense{ID: 2, TotalAmount: 20, Date: model.NewDate(2024, 2, 5), Payments: []model.Payment{{ParticipantID: 2, Amount: 20}}, SharedWith: []int{1, 2}})
+	if err := expenseService.PreservePeriods(stored, &update); err != nil {
+		t.Errorf("Expected update keeping closed expenses to be accepted, got %v", err)
+	}
+
+	changed := *stored
+	changed.Expenses = []model.Expense{stored.Expenses[0]}
+	changed.Expenses[0].TotalAmount = 120
+	if err := expenseService.PreservePeriods(stored, &changed); !errors.Is(err, service.ErrPeriodFrozen) {
+		t.Errorf("Expected ErrPeriodFrozen when changing a closed expense, got %v", err)
+	}
+}
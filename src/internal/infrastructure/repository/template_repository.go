@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// Sprawdzenie czy implementacja spełnia interfejs
+var _ repository.TemplateRepository = (*InMemoryTemplateRepository)(nil)
+
+// InMemoryTemplateRepository implementacja repozytorium szablonów wydarzeń w pamięci
+type InMemoryTemplateRepository struct {
+	templates map[int]*model.EventTemplate
+	nextID    int
+	mutex     sync.RWMutex
+}
+
+// NewInMemoryTemplateRepository tworzy nowe repozytorium szablonów w pamięci
+func NewInMemoryTemplateRepository() *InMemoryTemplateRepository {
+	return &InMemoryTemplateRepository{
+		templates: make(map[int]*model.EventTemplate),
+		nextID:    1,
+	}
+}
+
+// Save zapisuje szablon
+func (r *InMemoryTemplateRepository) Save(template *model.EventTemplate) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if template.ID == 0 {
+		template.ID = r.nextID
+		r.nextID++
+	}
+
+	// Znaczniki czasu są zarządzane przez serwer
+	now := time.Now().UTC()
+	template.CreatedAt = now
+	if previous, exists := r.templates[template.ID]; exists {
+		template.CreatedAt = previous.CreatedAt
+	}
+	template.UpdatedAt = now
+
+	r.templates[template.ID] = copyTemplate(template)
+	return nil
+}
+
+// FindByID znajduje szablon po ID
+func (r *InMemoryTemplateRepository) FindByID(id int) (*model.EventTemplate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	template, exists := r.templates[id]
+	if !exists {
+		return nil, errors.New("template not found")
+	}
+
+	return copyTemplate(template), nil
+}
+
+// Delete usuwa szablon
+func (r *InMemoryTemplateRepository) Delete(id int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.templates[id]; !exists {
+		return errors.New("template not found")
+	}
+
+	delete(r.templates, id)
+	return nil
+}
+
+// FindAll zwraca wszystkie szablony
+func (r *InMemoryTemplateRepository) FindAll() ([]*model.EventTemplate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	templates := make([]*model.EventTemplate, 0, len(r.templates))
+	for _, template := range r.templates {
+		templates = append(templates, copyTemplate(template))
+	}
+
+	return templates, nil
+}
+
+// Funkcja pomocnicza do głębokiego kopiowania obiektów EventTemplate
+func copyTemplate(template *model.EventTemplate) *model.EventTemplate {
+	if template == nil {
+		return nil
+	}
+
+	// Kopia przez wydarzenie korzysta z tych samych funkcji kopiujących co repozytorium wydarzeń
+	event := copyEvent(&model.Event{
+		Participants: template.Participants,
+		Categories:   template.Categories,
+		Households:   template.Households,
+	})
+
+	newTemplate := *template
+	newTemplate.Participants = event.Participants
+	newTemplate.Categories = event.Categories
+	newTemplate.Households = event.Households
+	return &newTemplate
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// Sprawdzenie czy implementacja spełnia interfejs
+var _ repository.WebhookRepository = (*InMemoryWebhookRepository)(nil)
+
+// deliveryLogSize to liczba wpisów dziennika doręczeń przechowywanych dla subskrypcji
+const deliveryLogSize = 100
+
+// InMemoryWebhookRepository implementacja repozytorium webhooków w pamięci
+type InMemoryWebhookRepository struct {
+	subscriptions  map[int]*model.WebhookSubscription
+	deliveries     map[int][]*model.WebhookDelivery
+	nextID         int
+	nextDeliveryID int
+	mutex          sync.RWMutex
+}
+
+// NewInMemoryWebhookRepository tworzy nowe repozytorium webhooków w pamięci
+func NewInMemoryWebhookRepository() *InMemoryWebhookRepository {
+	return &InMemoryWebhookRepository{
+		subscriptions:  make(map[int]*model.WebhookSubscription),
+		deliveries:     make(map[int][]*model.WebhookDelivery),
+		nextID:         1,
+		nextDeliveryID: 1,
+	}
+}
+
+// Save zapisuje subskrypcję
+func (r *InMemoryWebhookRepository) Save(subscription *model.WebhookSubscription) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if subscription.ID == 0 {
+		subscription.ID = r.nextID
+		r.nextID++
+	}
+
+	// Czas utworzenia jest zarządzany przez serwer
+	subscription.CreatedAt = time.Now().UTC()
+	if previous, exists := r.subscriptions[subscription.ID]; exists {
+		subscription.CreatedAt = previous.CreatedAt
+	}
+
+	r.subscriptions[subscription.ID] = copySubscription(subscription)
+	return nil
+}
+
+// FindByID znajduje subskrypcję po ID
+func (r *InMemoryWebhookRepository) FindByID(id int) (*model.WebhookSubscription, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscription, exists := r.subscriptions[id]
+	if !exists {
+		return nil, errors.New("webhook not found")
+	}
+
+	return copySubscription(subscription), nil
+}
+
+// Delete usuwa subskrypcję wraz z dziennikiem doręczeń
+func (r *InMemoryWebhookRepository) Delete(id int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.subscriptions[id]; !exists {
+		return errors.New("webhook not found")
+	}
+
+	delete(r.subscriptions, id)
+	delete(r.deliveries, id)
+	return nil
+}
+
+// FindAll zwraca wszystkie subskrypcje w kolejności utworzenia
+func (r *InMemoryWebhookRepository) FindAll() ([]*model.WebhookSubscription, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscriptions := make([]*model.WebhookSubscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, copySubscription(subscription))
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].ID < subscriptions[j].ID })
+
+	return subscriptions, nil
+}
+
+// AddDelivery dopisuje próbę doręczenia do dziennika subskrypcji, usuwając najstarsze wpisy
+func (r *InMemoryWebhookRepository) AddDelivery(delivery *model.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.subscriptions[delivery.SubscriptionID]; !exists {
+		return errors.New("webhook not found")
+	}
+
+	delivery.ID = r.nextDeliveryID
+	r.nextDeliveryID++
+
+	stored := *delivery
+	log := append(r.deliveries[delivery.SubscriptionID], &stored)
+	if len(log) > deliveryLogSize {
+		log = log[len(log)-deliveryLogSize:]
+	}
+	r.deliveries[delivery.SubscriptionID] = log
+	return nil
+}
+
+// FindDeliveries zwraca dziennik doręczeń subskrypcji od najnowszego wpisu
+func (r *InMemoryWebhookRepository) FindDeliveries(subscriptionID int) ([]*model.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if _, exists := r.subscriptions[subscriptionID]; !exists {
+		return nil, errors.New("webhook not found")
+	}
+
+	log := r.deliveries[subscriptionID]
+	deliveries := make([]*model.WebhookDelivery, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		delivery := *log[i]
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
+
+// Funkcja pomocnicza do kopiowania subskrypcji
+func copySubscription(subscription *model.WebhookSubscription) *model.WebhookSubscription {
+	newSubscription := *subscription
+	newSubscription.Types = append([]string(nil), subscription.Types...)
+	return &newSubscription
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// Sprawdzenie czy implementacja spełnia interfejs
+var _ repository.BlobStorage = (*LocalBlobStorage)(nil)
+
+// LocalBlobStorage implementacja magazynu plików w lokalnym systemie plików
+type LocalBlobStorage struct {
+	baseDir string
+}
+
+// NewLocalBlobStorage tworzy magazyn plików w podanym katalogu
+func NewLocalBlobStorage(baseDir string) (*LocalBlobStorage, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalBlobStorage{baseDir: baseDir}, nil
+}
+
+// Put zapisuje plik pod podanym kluczem
+func (s *LocalBlobStorage) Put(_ context.Context, key string, data io.Reader, _ int64, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Zapis do pliku tymczasowego i podmiana, aby nie zostawić niepełnego pliku
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get otwiera plik o podanym kluczu
+func (s *LocalBlobStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, repository.ErrBlobNotFound
+	}
+	return file, err
+}
+
+// Delete usuwa plik o podanym kluczu; brak pliku nie jest błędem
+func (s *LocalBlobStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path zamienia klucz na ścieżkę w katalogu magazynu, odrzucając próby wyjścia poza niego
+func (s *LocalBlobStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash("/" + key))
+	if cleaned == string(filepath.Separator) || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.baseDir, cleaned), nil
+}
@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/repository"
+)
+
+// Sprawdzenie czy implementacja spełnia interfejs
+var _ repository.BlobStorage = (*S3BlobStorage)(nil)
+
+// S3Config zawiera konfigurację magazynu zgodnego z S3 (AWS, MinIO, Ceph itp.)
+type S3Config struct {
+	Endpoint  string // np. https://s3.eu-central-1.amazonaws.com lub http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3BlobStorage implementacja magazynu plików w usłudze zgodnej z S3.
+// Używa adresowania path-style i podpisów AWS Signature Version 4.
+type S3BlobStorage struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3BlobStorage tworzy magazyn plików S3
+func NewS3BlobStorage(config S3Config, client *http.Client) (*S3BlobStorage, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("s3 endpoint and bucket are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+
+	return &S3BlobStorage{
+		config: config,
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+// Put zapisuje plik pod podanym kluczem
+func (s *S3BlobStorage) Put(ctx context.Context, key string, data io.Reader, _ int64, contentType string) error {
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(resp)
+	}
+	return nil
+}
+
+// Get pobiera plik o podanym kluczu
+func (s *S3BlobStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, repository.ErrBlobNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s.responseError(resp)
+	}
+}
+
+// Delete usuwa plik o podanym kluczu; brak pliku nie jest błędem
+func (s *S3BlobStorage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.responseError(resp)
+	}
+	return nil
+}
+
+// newRequest tworzy podpisane żądanie do obiektu o podanym kluczu
+func (s *S3BlobStorage) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	path := "/" + uriEncode(s.config.Bucket, false) + "/" + uriEncode(key, true)
+	req, err := http.NewRequestWithContext(ctx, method, s.config.Endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+
+	s.sign(req, path, body)
+	return req, nil
+}
+
+// sign podpisuje żądanie algorytmem AWS Signature Version 4
+func (s *S3BlobStorage) sign(req *http.Request, path string, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	shortDate := now.Format("20060102")
+
+	payloadHash := sha256.Sum256(body)
+	payloadHex := hex.EncodeToString(payloadHash[:])
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHex)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHex + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHex,
+	}, "\n")
+
+	scope := shortDate + "/" + s.config.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretKey), shortDate)
+	signingKey = hmacSHA256(signingKey, s.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// responseError buduje błąd na podstawie odpowiedzi serwera S3
+func (s *S3BlobStorage) responseError(resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// hmacSHA256 oblicza HMAC-SHA256 wiadomości
+func hmacSHA256(key []byte, message string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// uriEncode koduje tekst zgodnie z regułami AWS SigV4 (RFC 3986, bez kodowania "/" w kluczach)
+func uriEncode(value string, keepSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
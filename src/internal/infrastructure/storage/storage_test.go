@@ -0,0 +1,112 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/infrastructure/storage"
+)
+
+// fakeS3 to minimalny serwer zgodny z S3 (path-style) do testów, na wzór lokalnego MinIO
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestBlobStorages(t *testing.T) {
+	local, err := storage.NewLocalBlobStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create local storage: %v", err)
+	}
+
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s3, err := storage.NewS3BlobStorage(storage.S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "receipts",
+		AccessKey: "key",
+		SecretKey: "secret",
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("Failed to create S3 storage: %v", err)
+	}
+
+	storages := map[string]repository.BlobStorage{"local": local, "s3": s3}
+	for name, blobs := range storages {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "events/1/expenses/2/abc"
+
+			if err := blobs.Put(ctx, key, strings.NewReader("receipt"), 7, "image/png"); err != nil {
+				t.Fatalf("Failed to put blob: %v", err)
+			}
+
+			reader, err := blobs.Get(ctx, key)
+			if err != nil {
+				t.Fatalf("Failed to get blob: %v", err)
+			}
+			content, _ := io.ReadAll(reader)
+			reader.Close()
+			if string(content) != "receipt" {
+				t.Errorf("Expected blob content 'receipt', got %q", content)
+			}
+
+			if err := blobs.Delete(ctx, key); err != nil {
+				t.Fatalf("Failed to delete blob: %v", err)
+			}
+
+			if _, err := blobs.Get(ctx, key); !errors.Is(err, repository.ErrBlobNotFound) {
+				t.Errorf("Expected ErrBlobNotFound after delete, got %v", err)
+			}
+
+			// Ponowne usunięcie nie jest błędem
+			if err := blobs.Delete(ctx, key); err != nil {
+				t.Errorf("Expected deleting missing blob to succeed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLocalBlobStorageRejectsTraversal(t *testing.T) {
+	local, _ := storage.NewLocalBlobStorage(t.TempDir())
+
+	if err := local.Put(context.Background(), "../outside", strings.NewReader("x"), 1, ""); err == nil {
+		t.Error("Expected error for key escaping the storage directory")
+	}
+}
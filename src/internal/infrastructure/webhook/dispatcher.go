@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/domain/repository"
+	"github.com/inflop/splitty.api/internal/infrastructure/pubsub"
+)
+
+// Domyślne ustawienia ponawiania doręczeń
+const (
+	DefaultMaxAttempts = 5
+	DefaultBaseDelay   = 5 * time.Second
+	DefaultMaxDelay    = 5 * time.Minute
+	DefaultTimeout     = 10 * time.Second
+)
+
+// Options określa sposób ponawiania doręczeń. Kolejne próby następują po BaseDelay,
+// 2×BaseDelay, 4×BaseDelay itd., nie dłużej niż po MaxDelay. Zerowe pola przyjmują
+// wartości domyślne.
+type Options struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Timeout to limit czasu pojedynczej próby doręczenia
+	Timeout time.Duration
+}
+
+// Dispatcher doręcza zmiany wydarzeń do pasujących subskrypcji i zapisuje każdą
+// próbę w dzienniku doręczeń
+type Dispatcher struct {
+	repository repository.WebhookRepository
+	client     *http.Client
+	options    Options
+	logger     *log.Logger
+	inFlight   sync.WaitGroup
+}
+
+// NewDispatcher tworzy dystrybutor powiadomień
+func NewDispatcher(repository repository.WebhookRepository, options Options, logger *log.Logger) *Dispatcher {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+	if options.BaseDelay <= 0 {
+		options.BaseDelay = DefaultBaseDelay
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = DefaultMaxDelay
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Dispatcher{
+		repository: repository,
+		client:     &http.Client{Timeout: options.Timeout},
+		options:    options,
+		logger:     logger,
+	}
+}
+
+// Run subskrybuje zmiany wszystkich wydarzeń i doręcza je do czasu anulowania kontekstu.
+// Po odłączeniu przez broker subskrypcja jest odnawiana.
+func (d *Dispatcher) Run(ctx context.Context, broker pubsub.Broker) {
+	defer d.inFlight.Wait()
+
+	for ctx.Err() == nil {
+		changes, unsubscribe := broker.Subscribe(pubsub.AllEvents)
+		d.consume(ctx, changes)
+		unsubscribe()
+
+		if ctx.Err() == nil {
+			d.logger.Printf("Webhooks: subscription dropped by the broker, resubscribing")
+		}
+	}
+}
+
+// consume doręcza zmiany z kanału do jego zamknięcia lub anulowania kontekstu
+func (d *Dispatcher) consume(ctx context.Context, changes <-chan model.EventChange) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			d.Dispatch(ctx, change)
+		}
+	}
+}
+
+// Dispatch rozpoczyna doręczanie zmiany do wszystkich pasujących subskrypcji
+// i nie czeka na jego zakończenie
+func (d *Dispatcher) Dispatch(ctx context.Context, change model.EventChange) {
+	subscriptions, err := d.repository.FindAll()
+	if err != nil {
+		d.logger.Printf("Webhooks: failed to get subscriptions: %v", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !Matches(subscription, change) {
+			continue
+		}
+		d.inFlight.Add(1)
+		go func() {
+			defer d.inFlight.Done()
+			d.deliver(ctx, subscription, change)
+		}()
+	}
+}
+
+// Wait czeka na zakończenie rozpoczętych doręczeń (łącznie z ponowieniami)
+func (d *Dispatcher) Wait() {
+	d.inFlight.Wait()
+}
+
+// Matches sprawdza czy subskrypcja obejmuje zmianę. Rodzaj "expense.*" obejmuje
+// wszystkie rodzaje zaczynające się od "expense.".
+func Matches(subscription *model.WebhookSubscription, change model.EventChange) bool {
+	if subscription.EventID != 0 && subscription.EventID != change.EventID {
+		return false
+	}
+	if len(subscription.Types) == 0 {
+		return true
+	}
+
+	for _, pattern := range subscription.Types {
+		if pattern == change.Type || pattern == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(change.Type, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver wysyła zmianę do subskrypcji, ponawiając nieudane próby z wykładniczo
+// rosnącym opóźnieniem. Odpowiedzi 4xx (poza 408 i 429) kończą doręczanie.
+func (d *Dispatcher) deliver(ctx context.Context, subscription *model.WebhookSubscription, change model.EventChange) {
+	body, err := json.Marshal(change)
+	if err != nil {
+		d.logger.Printf("Webhooks: failed to encode change %d: %v", change.ID, err)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		status, err := d.post(ctx, subscription, change, body)
+
+		delivery := model.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			ChangeID:       change.ID,
+			Type:           change.Type,
+			EventID:        change.EventID,
+			Attempt:        attempt,
+			Success:        err == nil,
+			StatusCode:     status,
+			DurationMs:     time.Since(start).Milliseconds(),
+			At:             start.UTC(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+
+		retry := err != nil && retryable(status) && attempt < d.options.MaxAttempts && ctx.Err() == nil
+		delay := d.backoff(attempt)
+		if retry {
+			delivery.NextAttemptAt = delivery.At.Add(delay)
+		}
+
+		// Błąd zapisu oznacza, że subskrypcja została w międzyczasie usunięta
+		if err := d.repository.AddDelivery(&delivery); err != nil || !retry {
+			return
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// post wykonuje pojedynczą próbę doręczenia i zwraca kod odpowiedzi (0 przy błędzie sieci)
+func (d *Dispatcher) post(ctx context.Context, subscription *model.WebhookSubscription, change model.EventChange, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Splitty-Webhooks/1.0")
+	request.Header.Set(HeaderEvent, change.Type)
+	request.Header.Set(HeaderDelivery, fmt.Sprintf("%d-%d", subscription.ID, change.ID))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// backoff zwraca opóźnienie po nieudanej próbie o podanym numerze
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.options.BaseDelay
+	for i := 1; i < attempt && delay < d.options.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.options.MaxDelay)
+}
+
+// retryable sprawdza czy po odpowiedzi o podanym kodzie warto ponowić doręczenie
+func retryable(status int) bool {
+	return status == 0 || status >= 500 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+}
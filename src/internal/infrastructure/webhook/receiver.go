@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+)
+
+// Received to powiadomienie odebrane przez Receiver
+type Received struct {
+	Delivery string
+	Change   model.EventChange
+	Body     []byte
+	At       time.Time
+}
+
+// Receiver to lokalny odbiorca powiadomień do testów i debugowania integracji.
+// Weryfikuje podpis, zapamiętuje odebrane zmiany i potrafi symulować błędy odbiorcy.
+type Receiver struct {
+	// Logger, jeśli ustawiony, wypisuje każde odebrane powiadomienie
+	Logger *log.Logger
+
+	secret   string
+	mutex    sync.Mutex
+	received []Received
+	failures []int
+}
+
+// NewReceiver tworzy odbiorcę weryfikującego podpisy sekretem subskrypcji
+func NewReceiver(secret string) *Receiver {
+	return &Receiver{secret: secret}
+}
+
+// FailNext sprawia, że kolejne powiadomienia otrzymają odpowiedzi o podanych kodach
+func (r *Receiver) FailNext(statuses ...int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.failures = append(r.failures, statuses...)
+}
+
+// ServeHTTP odbiera powiadomienie. Niepoprawny podpis kończy się odpowiedzią 401,
+// a poprawne powiadomienie (o ile nie symulowano błędu) odpowiedzią 204.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, 10<<20))
+	if err != nil {
+		http.Error(w, "Failed to read body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := Verify(r.secret, req.Header, body, time.Now(), DefaultTolerance); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	r.mutex.Lock()
+	if len(r.failures) > 0 {
+		status := r.failures[0]
+		r.failures = r.failures[1:]
+		r.mutex.Unlock()
+		http.Error(w, "Simulated failure", status)
+		return
+	}
+	r.mutex.Unlock()
+
+	var change model.EventChange
+	if err := json.Unmarshal(body, &change); err != nil {
+		http.Error(w, "Invalid change: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	received := Received{Delivery: req.Header.Get(HeaderDelivery), Change: change, Body: body, At: time.Now()}
+	r.mutex.Lock()
+	r.received = append(r.received, received)
+	r.mutex.Unlock()
+
+	if r.Logger != nil {
+		r.Logger.Printf("Delivery %s: %s for event %d\n%s", received.Delivery, change.Type, change.EventID, body)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Received zwraca odebrane powiadomienia w kolejności odbioru
+func (r *Receiver) Received() []Received {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]Received(nil), r.received...)
+}
+
+// WaitFor czeka, aż odebranych zostanie co najmniej n powiadomień, nie dłużej niż timeout
+func (r *Receiver) WaitFor(n int, timeout time.Duration) []Received {
+	deadline := time.Now().Add(timeout)
+	for {
+		received := r.Received()
+		if len(received) >= n || time.Now().After(deadline) {
+			return received
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,58 @@
+// Package webhook doręcza powiadomienia o zmianach wydarzeń do zewnętrznych odbiorców HTTP.
+//
+// Treścią powiadomienia jest zmiana wydarzenia (model.EventChange) w formacie JSON.
+// Nagłówek X-Splitty-Signature zawiera podpis "sha256=<hex>" wyznaczony algorytmem
+// HMAC-SHA256 z sekretu subskrypcji dla tekstu "<timestamp>.<treść>", gdzie timestamp
+// to wartość nagłówka X-Splitty-Timestamp (czas Unix w sekundach).
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Nagłówki powiadomień
+const (
+	HeaderEvent     = "X-Splitty-Event"
+	HeaderDelivery  = "X-Splitty-Delivery"
+	HeaderTimestamp = "X-Splitty-Timestamp"
+	HeaderSignature = "X-Splitty-Signature"
+)
+
+// DefaultTolerance to domyślna maksymalna różnica między czasem podpisu a czasem weryfikacji
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrStaleTimestamp   = errors.New("webhook: timestamp outside tolerance")
+)
+
+// Sign wyznacza podpis treści powiadomienia wysłanego w podanym czasie
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify sprawdza podpis powiadomienia oraz to, czy czas podpisu mieści się w tolerancji,
+// co chroni odbiorcę przed ponownym odtworzeniem przechwyconego powiadomienia
+func Verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+	return nil
+}
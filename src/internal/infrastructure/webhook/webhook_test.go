@@ -0,0 +1,98 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/inflop/splitty.api/internal/domain/model"
+	"github.com/inflop/splitty.api/internal/infrastructure/repository"
+	"github.com/inflop/splitty.api/internal/infrastructure/webhook"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"event.updated"}`)
+	now := time.Unix(1700000000, 0)
+
+	header := http.Header{}
+	header.Set(webhook.HeaderTimestamp, "1700000000")
+	header.Set(webhook.HeaderSignature, webhook.Sign("secret", now.Unix(), body))
+
+	if err := webhook.Verify("secret", header, body, now.Add(time.Minute), webhook.DefaultTolerance); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
+	}
+	if err := webhook.Verify("other", header, body, now, webhook.DefaultTolerance); !errors.Is(err, webhook.ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+	if err := webhook.Verify("secret", header, []byte(`{}`), now, webhook.DefaultTolerance); !errors.Is(err, webhook.ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for modified body, got %v", err)
+	}
+	if err := webhook.Verify("secret", header, body, now.Add(time.Hour), webhook.DefaultTolerance); !errors.Is(err, webhook.ErrStaleTimestamp) {
+		t.Errorf("Expected ErrStaleTimestamp, got %v", err)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	change := model.EventChange{Type: model.ChangeExpenseCreated, EventID: 2}
+
+	tests := []struct {
+		subscription model.WebhookSubscription
+		want         bool
+	}{
+		{model.WebhookSubscription{}, true},
+		{model.WebhookSubscription{EventID: 2, Types: []string{model.ChangeExpenseCreated}}, true},
+		{model.WebhookSubscription{EventID: 3}, false},
+		{model.WebhookSubscription{Types: []string{"expense.*"}}, true},
+		{model.WebhookSubscription{Types: []string{model.ChangeEventSettled}}, false},
+	}
+	for _, tt := range tests {
+		if got := webhook.Matches(&tt.subscription, change); got != tt.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tt.subscription, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcherRetriesAndLogsDeliveries(t *testing.T) {
+	receiver := webhook.NewReceiver("secret")
+	receiver.FailNext(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	repo := repository.NewInMemoryWebhookRepository()
+	retried := &model.WebhookSubscription{URL: server.URL, Secret: "secret", Types: []string{model.ChangeEventSettled}}
+	rejected := &model.WebhookSubscription{URL: server.URL, Secret: "wrong"}
+	other := &model.WebhookSubscription{URL: server.URL, Secret: "secret", EventID: 9}
+	for _, subscription := range []*model.WebhookSubscription{retried, rejected, other} {
+		repo.Save(subscription)
+	}
+
+	dispatcher := webhook.NewDispatcher(repo, webhook.Options{BaseDelay: time.Millisecond, MaxAttempts: 3}, nil)
+	dispatcher.Dispatch(context.Background(), model.EventChange{ID: 7, Type: model.ChangeEventSettled, EventID: 1})
+	dispatcher.Wait()
+
+	received := receiver.Received()
+	if len(received) != 1 || received[0].Change.ID != 7 || received[0].Delivery != "1-7" {
+		t.Fatalf("Expected one verified delivery of change 7, got %+v", received)
+	}
+
+	deliveries, _ := repo.FindDeliveries(retried.ID)
+	if len(deliveries) != 3 || !deliveries[0].Success || deliveries[0].Attempt != 3 {
+		t.Fatalf("Expected two failed attempts and a successful third one, got %+v", deliveries)
+	}
+	if deliveries[2].StatusCode != http.StatusServiceUnavailable || deliveries[2].NextAttemptAt.IsZero() {
+		t.Errorf("Expected first attempt to be logged with status 503 and a retry time, got %+v", deliveries[2])
+	}
+
+	// Odpowiedź 401 nie jest ponawiana
+	deliveries, _ = repo.FindDeliveries(rejected.ID)
+	if len(deliveries) != 1 || deliveries[0].StatusCode != http.StatusUnauthorized || !deliveries[0].NextAttemptAt.IsZero() {
+		t.Errorf("Expected a single rejected attempt, got %+v", deliveries)
+	}
+
+	if deliveries, _ := repo.FindDeliveries(other.ID); len(deliveries) != 0 {
+		t.Errorf("Expected no deliveries for subscription of another event, got %d", len(deliveries))
+	}
+}
@@ -0,0 +1,305 @@
+// Package websocket implementuje serwerową stronę protokołu WebSocket (RFC 6455)
+// bez rozszerzeń: wiadomości tekstowe i binarne, fragmentację oraz ramki kontrolne.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Rodzaje wiadomości (kody operacji ramek)
+const (
+	continuationFrame = 0
+	TextMessage       = 1
+	BinaryMessage     = 2
+	CloseMessage      = 8
+	PingMessage       = 9
+	PongMessage       = 10
+)
+
+// Kody zamknięcia połączenia
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+// acceptGUID to stała z RFC 6455 używana do wyznaczenia Sec-WebSocket-Accept
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// DefaultMaxMessageSize to domyślny maksymalny rozmiar odbieranej wiadomości
+const DefaultMaxMessageSize = 1 << 20
+
+// writeTimeout to limit czasu zapisu pojedynczej ramki
+const writeTimeout = 10 * time.Second
+
+var (
+	ErrBadHandshake = errors.New("websocket: bad handshake")
+	ErrClosed       = errors.New("websocket: connection closed")
+)
+
+// CloseError to błąd zwracany po otrzymaniu ramki zamknięcia od klienta
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error zwraca opis zamknięcia połączenia
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", e.Code, e.Reason)
+}
+
+// Conn to połączenie WebSocket po stronie serwera. ReadMessage może być wywoływane
+// z jednej gorutyny, a WriteMessage z wielu jednocześnie.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	// MaxMessageSize to maksymalny rozmiar odbieranej wiadomości (po złożeniu fragmentów)
+	MaxMessageSize int64
+	// IdleTimeout to maksymalny czas oczekiwania na kolejną ramkę (0 oznacza brak limitu)
+	IdleTimeout time.Duration
+
+	writeMutex sync.Mutex
+	closeSent  bool
+}
+
+// Upgrade przełącza połączenie HTTP na protokół WebSocket. W razie błędu uzgadniania
+// zapisuje odpowiedź HTTP z kodem błędu.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "WebSocket upgrade requires GET", http.StatusMethodNotAllowed)
+		return nil, ErrBadHandshake
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "Expected WebSocket upgrade", http.StatusBadRequest)
+		return nil, ErrBadHandshake
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, ErrBadHandshake
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "Invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, ErrBadHandshake
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket is not supported", http.StatusInternalServerError)
+		return nil, err
+	}
+	// Limity czasu serwera HTTP nie dotyczą połączenia WebSocket
+	conn.SetDeadline(time.Time{})
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Conn{conn: conn, reader: rw.Reader, MaxMessageSize: DefaultMaxMessageSize}, nil
+}
+
+// AcceptKey wyznacza wartość nagłówka Sec-WebSocket-Accept dla klucza klienta
+func AcceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// ReadMessage zwraca kolejną wiadomość z danymi, składając fragmenty. Ramki ping
+// otrzymują odpowiedź automatycznie, a ramka zamknięcia kończy się błędem *CloseError.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	messageType := 0
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case PingMessage:
+			if err := c.WriteMessage(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNormal}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			c.WriteClose(closeErr.Code, "")
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			messageType = opcode
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(message)+len(payload)) > c.MaxMessageSize {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		message = append(message, payload...)
+
+		if fin {
+			if messageType == TextMessage && !utf8.Valid(message) {
+				return 0, nil, c.fail(CloseInvalidPayload, "invalid UTF-8")
+			}
+			return messageType, message, nil
+		}
+	}
+}
+
+// readFrame odczytuje pojedynczą ramkę i zdejmuje z niej maskę klienta
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	if c.IdleTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.IdleTimeout))
+	}
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := int(header[0] & 0x0F)
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "unsupported extension")
+	}
+	// Ramki od klienta muszą być maskowane
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "unmasked client frame")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if opcode >= CloseMessage && (!fin || length > 125) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	if length > uint64(c.MaxMessageSize) {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage wysyła wiadomość w pojedynczej, niemaskowanej ramce
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if c.closeSent {
+		return ErrClosed
+	}
+	if messageType == CloseMessage {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(data)+10)
+	frame = append(frame, 0x80|byte(messageType))
+	switch {
+	case len(data) < 126:
+		frame = append(frame, byte(len(data)))
+	case len(data) <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(data)))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(data)))
+	}
+	frame = append(frame, data...)
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// WriteClose wysyła ramkę zamknięcia z kodem i powodem
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	return c.WriteMessage(CloseMessage, append(payload, reason...))
+}
+
+// Close zamyka połączenie sieciowe
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// fail zamyka połączenie z powodu naruszenia protokołu i zwraca opisujący je błąd
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteClose(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// headerContains sprawdza, czy nagłówek zawiera token (bez rozróżniania wielkości liter)
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
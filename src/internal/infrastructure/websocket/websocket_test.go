@@ -0,0 +1,200 @@
+package websocket_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/inflop/splitty.api/internal/infrastructure/websocket"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Przykład z RFC 6455
+	if got := websocket.AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Unexpected accept key %q", got)
+	}
+}
+
+func TestUpgradeRejectsInvalidHandshake(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		websocket.Upgrade(w, r)
+	}))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for plain request, got %d", response.StatusCode)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "8")
+	request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusUpgradeRequired || response.Header.Get("Sec-WebSocket-Version") != "13" {
+		t.Errorf("Expected 426 with supported version, got %d", response.StatusCode)
+	}
+}
+
+func TestConnExchangesMessages(t *testing.T) {
+	closed := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			conn.WriteMessage(messageType, append([]byte("echo: "), data...))
+		}
+	}))
+	defer server.Close()
+
+	client, reader := dial(t, server.URL)
+	defer client.Close()
+
+	// Wiadomość podzielona na fragmenty z ramką ping pomiędzy nimi
+	writeFrame(t, client, false, websocket.TextMessage, []byte("hello "))
+	writeFrame(t, client, true, websocket.PingMessage, []byte("p"))
+	writeFrame(t, client, true, 0, []byte("world"))
+
+	if opcode, payload := readFrame(t, reader); opcode != websocket.PongMessage || string(payload) != "p" {
+		t.Errorf("Expected pong with ping payload, got %d %q", opcode, payload)
+	}
+	if opcode, payload := readFrame(t, reader); opcode != websocket.TextMessage || string(payload) != "echo: hello world" {
+		t.Errorf("Unexpected echo %d %q", opcode, payload)
+	}
+
+	// Wiadomość wymagająca 16-bitowej długości
+	long := strings.Repeat("x", 300)
+	writeFrame(t, client, true, websocket.BinaryMessage, []byte(long))
+	if opcode, payload := readFrame(t, reader); opcode != websocket.BinaryMessage || string(payload) != "echo: "+long {
+		t.Errorf("Unexpected echo of long message %d (%d bytes)", opcode, len(payload))
+	}
+
+	writeFrame(t, client, true, websocket.CloseMessage, binary.BigEndian.AppendUint16(nil, websocket.CloseNormal))
+	if opcode, payload := readFrame(t, reader); opcode != websocket.CloseMessage || binary.BigEndian.Uint16(payload) != websocket.CloseNormal {
+		t.Errorf("Expected close frame echo, got %d %v", opcode, payload)
+	}
+
+	var closeErr *websocket.CloseError
+	if err := <-closed; !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseNormal {
+		t.Errorf("Expected CloseError with normal code, got %v", err)
+	}
+}
+
+func TestConnRejectsUnmaskedFrame(t *testing.T) {
+	closed := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _, err = conn.ReadMessage()
+		closed <- err
+	}))
+	defer server.Close()
+
+	client, reader := dial(t, server.URL)
+	defer client.Close()
+
+	client.Write([]byte{0x81, 0x02, 'h', 'i'})
+	if opcode, payload := readFrame(t, reader); opcode != websocket.CloseMessage || binary.BigEndian.Uint16(payload) != websocket.CloseProtocolError {
+		t.Errorf("Expected protocol error close frame, got %d %v", opcode, payload)
+	}
+
+	var closeErr *websocket.CloseError
+	if err := <-closed; !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseProtocolError {
+		t.Errorf("Expected protocol error, got %v", err)
+	}
+}
+
+// dial nawiązuje połączenie WebSocket z serwerem testowym
+func dial(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: "+key+"\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Failed to read handshake: %v", err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != websocket.AcceptKey(key) {
+		t.Fatalf("Unexpected handshake response %d %v", response.StatusCode, response.Header)
+	}
+	return conn, reader
+}
+
+// writeFrame zapisuje maskowaną ramkę klienta
+func writeFrame(t *testing.T, conn net.Conn, fin bool, opcode int, payload []byte) {
+	t.Helper()
+
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	if len(payload) < 126 {
+		frame = append(frame, 0x80|byte(len(payload)))
+	} else {
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+}
+
+// readFrame odczytuje niemaskowaną ramkę serwera
+func readFrame(t *testing.T, reader *bufio.Reader) (int, []byte) {
+	t.Helper()
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		t.Fatalf("Failed to read frame: %v", err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		extended := make([]byte, 2)
+		io.ReadFull(reader, extended)
+		length = int(binary.BigEndian.Uint16(extended))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatalf("Failed to read payload: %v", err)
+	}
+	return int(header[0] & 0x0F), payload
+}